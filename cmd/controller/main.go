@@ -1,18 +1,26 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
+	"github.com/cho/vpa-graceful-drain-controller/pkg/admin"
 	"github.com/cho/vpa-graceful-drain-controller/pkg/controller"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/elbv2"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
 )
 
 var (
@@ -26,16 +34,61 @@ func init() {
 
 func main() {
 	var enableLeaderElection bool
+	var claimDrains bool
+	var metricsAddr string
+	var adminAddr string
 	var probeAddr string
 	var configMapName string
 	var configMapNamespace string
+	var finalizerName string
+	var cleanupFinalizersOnShutdown bool
+	var backfillFinalizersOnStartup bool
+	var configErrorRequeue time.Duration
+	var configBounds controller.ConfigBounds
+	var configDefaults controller.DefaultConfigOptions
+	var enableDrainPolicies bool
+	var validateConfigPath string
+	var awsTargetHealth bool
+	var awsRegion string
 
+	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. Use \"0\" to disable the metrics server.")
+	flag.StringVar(&adminAddr, "admin-bind-address", "0", "The address the admin endpoint (GET /drains) binds to. Use \"0\" to disable it.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&claimDrains, "claim-drains", false,
+		"Claim draining pods per replica so several replicas running without --leader-elect don't "+
+			"drain the same pod at once. Has no effect with --leader-elect, where only the leader reconciles.")
 	flag.StringVar(&configMapName, "config-map-name", "vpa-graceful-drain-config", "Name of the ConfigMap for configuration.")
 	flag.StringVar(&configMapNamespace, "config-map-namespace", "kube-system", "Namespace of the ConfigMap for configuration.")
+	flag.StringVar(&finalizerName, "finalizer-name", controller.VPAGracefulDrainFinalizer,
+		"Finalizer added to managed pods. Use a distinct value per controller instance.")
+	flag.BoolVar(&cleanupFinalizersOnShutdown, "cleanup-finalizers-on-shutdown", false,
+		"Remove the finalizer from pods that are not being deleted when the controller shuts down. "+
+			"Enable before uninstalling so pods are not left with a finalizer nobody removes.")
+	flag.BoolVar(&backfillFinalizersOnStartup, "backfill-finalizers-on-startup", false,
+		"Add the finalizer to existing managed pods that lack it once the cache has synced. "+
+			"Enable when turning the controller on in a cluster that already runs workloads.")
+	flag.DurationVar(&configErrorRequeue, "config-error-requeue", controller.DefaultConfigErrorRequeue,
+		"How long to wait before retrying a pod when the configuration ConfigMap cannot be read.")
+	flag.Int64Var(&configBounds.MaxGracePeriodSeconds, "max-grace-seconds", controller.DefaultMaxGracePeriodSeconds,
+		"Largest gracePeriodSeconds the configuration may set.")
+	flag.Int64Var(&configBounds.MaxDrainTimeoutSeconds, "max-drain-timeout-seconds", controller.DefaultMaxDrainTimeoutSeconds,
+		"Largest drainTimeoutSeconds the configuration may set. Raise it for workloads that need longer drains.")
+	flag.Int64Var(&configDefaults.GracePeriodSeconds, "default-grace-seconds", controller.DefaultGracePeriodSeconds,
+		"gracePeriodSeconds used when the configuration doesn't set it.")
+	flag.Int64Var(&configDefaults.DrainTimeoutSeconds, "default-drain-timeout-seconds", controller.DefaultDrainTimeoutSeconds,
+		"drainTimeoutSeconds used when the configuration doesn't set it.")
+	flag.BoolVar(&enableDrainPolicies, "enable-drain-policies", false,
+		"Read cluster-scoped DrainPolicy resources, whose settings take precedence over the ConfigMap.")
+	flag.BoolVar(&awsTargetHealth, "aws-target-health", false,
+		"Query AWS ELBv2 DescribeTargetHealth for awsTargetGroupCheck, with credentials from IAM roles for "+
+			"service accounts or the AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY environment variables.")
+	flag.StringVar(&awsRegion, "aws-region", os.Getenv("AWS_REGION"),
+		"AWS region of the target groups checked with --aws-target-health. Defaults to AWS_REGION.")
+	flag.StringVar(&validateConfigPath, "validate-config", "",
+		"Validate the ConfigMap manifest at this path, print the effective configuration and exit.")
 
 	opts := zap.Options{
 		Development: true,
@@ -45,10 +98,27 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if err := controller.ValidateFinalizerName(finalizerName); err != nil {
+		setupLog.Error(err, "invalid --finalizer-name")
+		os.Exit(1)
+	}
+	if err := configBounds.Validate(); err != nil {
+		setupLog.Error(err, "invalid --max-grace-seconds or --max-drain-timeout-seconds")
+		os.Exit(1)
+	}
+	if err := configDefaults.Validate(configBounds); err != nil {
+		setupLog.Error(err, "invalid --default-grace-seconds or --default-drain-timeout-seconds")
+		os.Exit(1)
+	}
+
+	if validateConfigPath != "" {
+		os.Exit(validateConfig(validateConfigPath, configBounds, configDefaults))
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
-			BindAddress: "0", // Disable metrics server
+			BindAddress: metricsAddr,
 		},
 		HealthProbeBindAddress:        probeAddr,
 		LeaderElection:                enableLeaderElection,
@@ -60,16 +130,86 @@ func main() {
 		os.Exit(1)
 	}
 
+	drainTracker := controller.NewDrainTracker()
+
+	// Without leader election every replica reconciles, so draining pods are claimed per replica
+	var replicaID string
+	if claimDrains && enableLeaderElection {
+		setupLog.Info("--claim-drains has no effect with --leader-elect, ignoring it")
+	} else if claimDrains {
+		replicaID = os.Getenv("POD_NAME")
+		if replicaID == "" {
+			replicaID, _ = os.Hostname()
+		}
+	}
+
+	var drainPolicies *controller.DrainPolicyResolver
+	if enableDrainPolicies {
+		dynamicClient, err := dynamic.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "unable to create dynamic client for drain policies")
+			os.Exit(1)
+		}
+		drainPolicies = &controller.DrainPolicyResolver{
+			Client:   dynamicClient,
+			Recorder: mgr.GetEventRecorderFor("vpa-graceful-drain-controller"),
+		}
+	}
+
+	var targetHealth finalizer.TargetHealthDescriber
+	if awsTargetHealth {
+		if awsRegion == "" {
+			setupLog.Error(fmt.Errorf("no AWS region"), "--aws-target-health needs --aws-region or AWS_REGION")
+			os.Exit(1)
+		}
+		targetHealth = elbv2.NewClient(awsRegion, elbv2.DefaultCredentials(awsRegion), nil)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+
+	// Without access to services and endpoints every endpoint check fails and each drain
+	// waits for its timeout; fall back to grace-period-only drains instead
+	var gracePeriodOnly bool
+	if clientset, err := kubernetes.NewForConfig(mgr.GetConfig()); err != nil {
+		setupLog.Error(err, "unable to create client for the RBAC preflight check, skipping it")
+	} else if missing, err := controller.PreflightEndpointAccess(ctx, clientset.AuthorizationV1().SelfSubjectAccessReviews()); err != nil {
+		setupLog.Error(err, "RBAC preflight check failed, skipping it")
+	} else if len(missing) > 0 {
+		setupLog.Info("WARNING: missing permissions for endpoint checks, falling back to grace-period-only drain",
+			"missing", missing)
+		gracePeriodOnly = true
+	}
+
 	if err = (&controller.PodReconciler{
-		Client:             mgr.GetClient(),
-		Scheme:             mgr.GetScheme(),
-		ConfigMapName:      configMapName,
-		ConfigMapNamespace: configMapNamespace,
+		Client:                      mgr.GetClient(),
+		Scheme:                      mgr.GetScheme(),
+		Recorder:                    mgr.GetEventRecorderFor("vpa-graceful-drain-controller"),
+		Tracker:                     drainTracker,
+		ConfigMapName:               configMapName,
+		ConfigMapNamespace:          configMapNamespace,
+		PodNamespace:                os.Getenv("POD_NAMESPACE"),
+		FinalizerName:               finalizerName,
+		CleanupFinalizersOnShutdown: cleanupFinalizersOnShutdown,
+		BackfillFinalizersOnStartup: backfillFinalizersOnStartup,
+		ConfigErrorRequeue:          configErrorRequeue,
+		ConfigBounds:                &configBounds,
+		ConfigDefaults:              &configDefaults,
+		DrainPolicies:               drainPolicies,
+		TargetHealth:                targetHealth,
+		ReplicaID:                   replicaID,
+		GracePeriodOnly:             gracePeriodOnly,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Pod")
 		os.Exit(1)
 	}
 
+	if adminAddr != "0" {
+		if err := mgr.Add(admin.NewServer(adminAddr, drainTracker)); err != nil {
+			setupLog.Error(err, "unable to set up admin server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -80,8 +220,26 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// validateConfig prints the effective configuration of the ConfigMap manifest at path, or
+// the validation error, and returns the process exit code
+func validateConfig(path string, bounds controller.ConfigBounds, defaults controller.DefaultConfigOptions) int {
+	config, err := controller.ValidateConfigFile(path, bounds, controller.WithDefaults(defaults))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	out, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println(string(out))
+	return 0
+}
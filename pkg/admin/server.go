@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/controller"
+)
+
+// DrainInfo is the JSON representation of a pod currently being drained
+type DrainInfo struct {
+	Namespace         string    `json:"namespace"`
+	Name              string    `json:"name"`
+	DeletionTimestamp time.Time `json:"deletionTimestamp"`
+	ElapsedSeconds    int64     `json:"elapsedSeconds"`
+	Phase             string    `json:"phase"`
+}
+
+// Server exposes read-only admin endpoints backed by in-memory drain tracking.
+// It never calls the API server while serving requests.
+type Server struct {
+	Addr    string
+	Tracker *controller.DrainTracker
+}
+
+func NewServer(addr string, tracker *controller.DrainTracker) *Server {
+	return &Server{
+		Addr:    addr,
+		Tracker: tracker,
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/drains", s.handleDrains)
+	return mux
+}
+
+func (s *Server) handleDrains(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+	entries := s.Tracker.List()
+	drains := make([]DrainInfo, 0, len(entries))
+	for _, entry := range entries {
+		drains = append(drains, DrainInfo{
+			Namespace:         entry.Namespace,
+			Name:              entry.Name,
+			DeletionTimestamp: entry.DeletionTimestamp,
+			ElapsedSeconds:    int64(now.Sub(entry.DeletionTimestamp).Seconds()),
+			Phase:             string(entry.Phase),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(drains); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Start implements manager.Runnable and serves until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("admin")
+
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "Failed to shut down admin server")
+		}
+	}()
+
+	logger.Info("Starting admin server", "address", s.Addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection allows every replica to serve its own view of the drains
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/controller"
+)
+
+func TestAdmin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Admin Suite")
+}
+
+var _ = Describe("Server", func() {
+	var (
+		tracker *controller.DrainTracker
+		server  *Server
+	)
+
+	BeforeEach(func() {
+		tracker = controller.NewDrainTracker()
+		server = NewServer(":0", tracker)
+	})
+
+	It("should return an empty list when nothing is draining", func() {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/drains", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`[]`))
+	})
+
+	It("should list tracked drains with elapsed time and phase", func() {
+		deletionTime := metav1.NewTime(time.Now().Add(-42 * time.Second))
+		tracker.Track(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "web-1",
+				Namespace:         "default",
+				DeletionTimestamp: &deletionTime,
+			},
+		}, "waiting-connections")
+
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/drains", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var drains []map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &drains)).To(Succeed())
+		Expect(drains).To(HaveLen(1))
+		Expect(drains[0]).To(HaveKeyWithValue("namespace", "default"))
+		Expect(drains[0]).To(HaveKeyWithValue("name", "web-1"))
+		Expect(drains[0]).To(HaveKeyWithValue("phase", "waiting-connections"))
+		Expect(drains[0]).To(HaveKey("deletionTimestamp"))
+		Expect(drains[0]["elapsedSeconds"]).To(BeNumerically(">=", 42))
+	})
+
+	It("should reject non-GET requests", func() {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/drains", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
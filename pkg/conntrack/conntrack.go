@@ -0,0 +1,98 @@
+package conntrack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// NodeNamePlaceholder is replaced with the pod's node name in the agent endpoint
+	NodeNamePlaceholder = "{nodeName}"
+
+	agentRequestTimeout = 5 * time.Second
+)
+
+// ConnTracker reports the number of established TCP connections of a pod
+type ConnTracker interface {
+	EstablishedConnections(ctx context.Context, pod *corev1.Pod) (int, error)
+}
+
+// AgentResponse is the JSON body returned by the node-local agent
+type AgentResponse struct {
+	Established int `json:"established"`
+}
+
+// HTTPAgent queries a node-local agent (typically a DaemonSet reading /proc/net/tcp or
+// netlink inside the pod's network namespace) for the pod's connection count.
+// The agent is called as GET <endpoint>?namespace=<ns>&pod=<name>&podIP=<ip>.
+type HTTPAgent struct {
+	endpointTemplate string
+	client           *http.Client
+}
+
+// NewHTTPAgent builds an agent client; endpointTemplate may contain NodeNamePlaceholder,
+// e.g. "http://{nodeName}:9095/connections". A nil client gets a short default timeout.
+func NewHTTPAgent(endpointTemplate string, client *http.Client) *HTTPAgent {
+	if client == nil {
+		client = &http.Client{Timeout: agentRequestTimeout}
+	}
+	return &HTTPAgent{
+		endpointTemplate: endpointTemplate,
+		client:           client,
+	}
+}
+
+// Endpoint returns the agent URL for the given node
+func (a *HTTPAgent) Endpoint(nodeName string) string {
+	return strings.ReplaceAll(a.endpointTemplate, NodeNamePlaceholder, nodeName)
+}
+
+func (a *HTTPAgent) EstablishedConnections(ctx context.Context, pod *corev1.Pod) (int, error) {
+	if pod.Spec.NodeName == "" {
+		return 0, fmt.Errorf("pod %s/%s is not scheduled to a node", pod.Namespace, pod.Name)
+	}
+
+	endpoint, err := url.Parse(a.Endpoint(pod.Spec.NodeName))
+	if err != nil {
+		return 0, fmt.Errorf("invalid agent endpoint: %v", err)
+	}
+	query := endpoint.Query()
+	query.Set("namespace", pod.Namespace)
+	query.Set("pod", pod.Name)
+	query.Set("podIP", pod.Status.PodIP)
+	endpoint.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, agentRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("conntrack agent returned status %d", resp.StatusCode)
+	}
+
+	var agentResponse AgentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&agentResponse); err != nil {
+		return 0, fmt.Errorf("invalid conntrack agent response: %v", err)
+	}
+	if agentResponse.Established < 0 {
+		return 0, fmt.Errorf("conntrack agent returned negative connection count: %d", agentResponse.Established)
+	}
+	return agentResponse.Established, nil
+}
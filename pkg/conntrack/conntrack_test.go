@@ -0,0 +1,109 @@
+package conntrack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConntrack(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conntrack Suite")
+}
+
+var _ = Describe("HTTPAgent", func() {
+	var (
+		ctx          context.Context
+		pod          *corev1.Pod
+		agent        *httptest.Server
+		lastQuery    url.Values
+		responseCode int
+		responseBody string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		responseCode = http.StatusOK
+		responseBody = `{"established": 3}`
+
+		agent = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			lastQuery = req.URL.Query()
+			w.WriteHeader(responseCode)
+			w.Write([]byte(responseBody))
+		}))
+		DeferCleanup(agent.Close)
+
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "default",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+			},
+			Status: corev1.PodStatus{
+				PodIP: "10.0.0.1",
+			},
+		}
+	})
+
+	It("should substitute the node name into the endpoint", func() {
+		httpAgent := NewHTTPAgent("http://{nodeName}:9095/connections", nil)
+		Expect(httpAgent.Endpoint("node-1")).To(Equal("http://node-1:9095/connections"))
+	})
+
+	It("should return the established connection count reported by the agent", func() {
+		httpAgent := NewHTTPAgent(agent.URL+"/connections", agent.Client())
+
+		count, err := httpAgent.EstablishedConnections(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(3))
+		Expect(lastQuery.Get("namespace")).To(Equal("default"))
+		Expect(lastQuery.Get("pod")).To(Equal("test-pod"))
+		Expect(lastQuery.Get("podIP")).To(Equal("10.0.0.1"))
+	})
+
+	It("should template the node name into the agent URL", func() {
+		template := strings.Replace(agent.URL, "127.0.0.1", NodeNamePlaceholder, 1)
+		pod.Spec.NodeName = "127.0.0.1"
+		httpAgent := NewHTTPAgent(template, agent.Client())
+
+		count, err := httpAgent.EstablishedConnections(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(3))
+	})
+
+	It("should return an error for non-200 responses", func() {
+		responseCode = http.StatusServiceUnavailable
+		httpAgent := NewHTTPAgent(agent.URL, agent.Client())
+
+		_, err := httpAgent.EstablishedConnections(ctx, pod)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("503"))
+	})
+
+	It("should return an error for malformed responses", func() {
+		responseBody = `not json`
+		httpAgent := NewHTTPAgent(agent.URL, agent.Client())
+
+		_, err := httpAgent.EstablishedConnections(ctx, pod)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid conntrack agent response"))
+	})
+
+	It("should return an error when the pod has no node", func() {
+		pod.Spec.NodeName = ""
+		httpAgent := NewHTTPAgent(agent.URL, agent.Client())
+
+		_, err := httpAgent.EstablishedConnections(ctx, pod)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// AuditRecordsKey is the audit ConfigMap key holding one JSON record per line, oldest first
+const AuditRecordsKey = "records"
+
+// AuditRecord is the persisted trace of one drain completion
+type AuditRecord struct {
+	Pod             string    `json:"pod"`
+	Namespace       string    `json:"namespace"`
+	UID             string    `json:"uid"`
+	Reason          string    `json:"reason"`
+	DurationSeconds int64     `json:"durationSeconds"`
+	CompletedAt     time.Time `json:"completedAt"`
+}
+
+// auditDrainCompletion appends the completion to the audit ConfigMap in the controller's
+// ConfigMap namespace when auditConfigMapName is set
+func (r *PodReconciler) auditDrainCompletion(ctx context.Context, pod *corev1.Pod, config *Config, reason string) error {
+	if config.AuditConfigMapName == "" {
+		return nil
+	}
+
+	now := r.clock().Now().UTC()
+	record := AuditRecord{
+		Pod:         pod.Name,
+		Namespace:   pod.Namespace,
+		UID:         string(pod.UID),
+		Reason:      reason,
+		CompletedAt: now,
+	}
+	if pod.DeletionTimestamp != nil {
+		record.DurationSeconds = int64(now.Sub(pod.DeletionTimestamp.Time).Seconds())
+	}
+
+	key := types.NamespacedName{Namespace: r.ConfigMapNamespace, Name: config.AuditConfigMapName}
+	return r.appendAuditRecord(ctx, key, record, config.AuditMaxEntries)
+}
+
+// appendAuditRecord adds the record to the audit ConfigMap, creating it if needed and
+// keeping only the newest maxEntries records. Replicas completing drains at the same
+// time race on the ConfigMap, so conflicting writes are retried on a fresh read.
+func (r *PodReconciler) appendAuditRecord(ctx context.Context, key types.NamespacedName, record AuditRecord, maxEntries int) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	isRetriable := func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, isRetriable, func() error {
+		var configMap corev1.ConfigMap
+		err := r.Get(ctx, key, &configMap)
+		if errors.IsNotFound(err) {
+			configMap = corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+				Data:       map[string]string{AuditRecordsKey: string(line)},
+			}
+			return r.Create(ctx, &configMap)
+		}
+		if err != nil {
+			return err
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[AuditRecordsKey] = appendTrimmed(configMap.Data[AuditRecordsKey], string(line), maxEntries)
+		return r.Update(ctx, &configMap)
+	})
+}
+
+// appendTrimmed appends line to the newline-separated records and drops the oldest ones
+// beyond maxEntries
+func appendTrimmed(records, line string, maxEntries int) string {
+	var lines []string
+	if records != "" {
+		lines = strings.Split(records, "\n")
+	}
+	lines = append(lines, line)
+	if maxEntries > 0 && len(lines) > maxEntries {
+		lines = lines[len(lines)-maxEntries:]
+	}
+	return strings.Join(lines, "\n")
+}
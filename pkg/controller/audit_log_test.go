@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+var _ = Describe("Drain audit ConfigMap", func() {
+	var (
+		ctx        context.Context
+		scheme     *runtime.Scheme
+		reconciler *PodReconciler
+		config     *Config
+		completed  time.Time
+		auditKey   types.NamespacedName
+	)
+
+	newDrainedPod := func(name string) *corev1.Pod {
+		deletionTime := metav1.NewTime(completed.Add(-90 * time.Second))
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				UID:               types.UID(name + "-uid"),
+				DeletionTimestamp: &deletionTime,
+			},
+		}
+	}
+
+	readRecords := func() []AuditRecord {
+		var configMap corev1.ConfigMap
+		Expect(reconciler.Get(ctx, auditKey, &configMap)).To(Succeed())
+		var records []AuditRecord
+		for _, line := range strings.Split(configMap.Data[AuditRecordsKey], "\n") {
+			var record AuditRecord
+			Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+			records = append(records, record)
+		}
+		return records
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		completed = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		reconciler = &PodReconciler{
+			Client:             fake.NewClientBuilder().WithScheme(scheme).Build(),
+			ConfigMapNamespace: "kube-system",
+			Clock:              fixedClock{now: completed},
+		}
+		config = NewDefaultConfig()
+		config.AuditConfigMapName = "drain-audit"
+		auditKey = types.NamespacedName{Namespace: "kube-system", Name: "drain-audit"}
+	})
+
+	It("should create the ConfigMap with the first record", func() {
+		Expect(reconciler.auditDrainCompletion(ctx, newDrainedPod("web-1"), config, finalizer.CompletionReasonNoConnections)).To(Succeed())
+
+		Expect(readRecords()).To(Equal([]AuditRecord{{
+			Pod:             "web-1",
+			Namespace:       "default",
+			UID:             "web-1-uid",
+			Reason:          finalizer.CompletionReasonNoConnections,
+			DurationSeconds: 90,
+			CompletedAt:     completed,
+		}}))
+	})
+
+	It("should keep only the newest records", func() {
+		config.AuditMaxEntries = 3
+		for i := 1; i <= 5; i++ {
+			Expect(reconciler.auditDrainCompletion(ctx, newDrainedPod(fmt.Sprintf("web-%d", i)), config, finalizer.CompletionReasonTimeout)).To(Succeed())
+		}
+
+		records := readRecords()
+		Expect(records).To(HaveLen(3))
+		Expect(records[0].Pod).To(Equal("web-3"))
+		Expect(records[2].Pod).To(Equal("web-5"))
+	})
+
+	It("should retry on a conflicting update", func() {
+		Expect(reconciler.auditDrainCompletion(ctx, newDrainedPod("web-1"), config, finalizer.CompletionReasonTimeout)).To(Succeed())
+
+		updates := 0
+		reconciler.Client = interceptor.NewClient(reconciler.Client.(client.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				updates++
+				if updates == 1 {
+					return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(), nil)
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+
+		Expect(reconciler.auditDrainCompletion(ctx, newDrainedPod("web-2"), config, finalizer.CompletionReasonTimeout)).To(Succeed())
+		Expect(updates).To(Equal(2))
+		Expect(readRecords()).To(HaveLen(2))
+	})
+
+	It("should do nothing without an audit ConfigMap name", func() {
+		config.AuditConfigMapName = ""
+		Expect(reconciler.auditDrainCompletion(ctx, newDrainedPod("web-1"), config, finalizer.CompletionReasonTimeout)).To(Succeed())
+
+		var configMap corev1.ConfigMap
+		Expect(apierrors.IsNotFound(reconciler.Get(ctx, auditKey, &configMap))).To(BeTrue())
+	})
+})
+
+var _ = Describe("appendTrimmed", func() {
+	It("should append to empty records", func() {
+		Expect(appendTrimmed("", "a", 3)).To(Equal("a"))
+	})
+
+	It("should drop the oldest lines beyond the limit", func() {
+		Expect(appendTrimmed("a\nb\nc", "d", 3)).To(Equal("b\nc\nd"))
+	})
+})
@@ -3,16 +3,198 @@ package controller
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"path"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/cel-go/cel"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/conntrack"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+const (
+	// FinalizerUpdateStrategyUpdate writes finalizer changes by updating the whole pod
+	FinalizerUpdateStrategyUpdate = "update"
+	// FinalizerUpdateStrategyPatch writes finalizer changes as a merge patch, which doesn't
+	// conflict with unrelated changes to the pod
+	FinalizerUpdateStrategyPatch = "patch"
 )
 
 type Config struct {
-	GracePeriodSeconds  int64              `json:"gracePeriodSeconds"`
-	DrainTimeoutSeconds int64              `json:"drainTimeoutSeconds"`
-	NamespaceSelector   *NamespaceSelector `json:"namespaceSelector,omitempty"`
+	// Enabled is the emergency off-switch: when false every finalizer is released at once
+	Enabled                       bool               `json:"enabled"`
+	GracePeriodSeconds            int64              `json:"gracePeriodSeconds"`
+	DrainTimeoutSeconds           int64              `json:"drainTimeoutSeconds"`
+	HardDeadlineBufferSeconds     int64              `json:"hardDeadlineBufferSeconds"`
+	APICallTimeoutSeconds         int64              `json:"apiCallTimeoutSeconds"`
+	ConnectionPollIntervalSeconds int64              `json:"connectionPollIntervalSeconds"`
+	MaxPollIntervalSeconds        int64              `json:"maxPollIntervalSeconds"`
+	EndpointSettleSeconds         int64              `json:"endpointSettleSeconds"`
+	PostDeregistrationSeconds     int64              `json:"postDeregistrationSeconds"`
+	KnativeSettleSeconds          int64              `json:"knativeSettleSeconds"`
+	MinimumServingSeconds         int64              `json:"minimumServingSeconds"`
+	AgeScaledGraceMinSeconds      int64              `json:"ageScaledGraceMinSeconds"`
+	AgeScaledGraceFullAgeSeconds  int64              `json:"ageScaledGraceFullAgeSeconds"`
+	WaitingLogIntervalSeconds     int64              `json:"waitingLogIntervalSeconds"`
+	TrafficWeightThreshold        float64            `json:"trafficWeightThreshold,omitempty"`
+	SlowDrainThreshold            float64            `json:"slowDrainThreshold"`
+	OnTimeoutWithConnections      string             `json:"onTimeoutWithConnections"`
+	OnConnectionCheckError        string             `json:"onConnectionCheckError"`
+	TimeoutExtensionSeconds       int64              `json:"timeoutExtensionSeconds"`
+	MaxTimeoutExtensions          int                `json:"maxTimeoutExtensions"`
+	NamespaceSelector             *NamespaceSelector `json:"namespaceSelector,omitempty"`
+	ManagedExpression             string             `json:"managedExpression,omitempty"`
+	ManageIfAnnotations           []string           `json:"manageIfAnnotations,omitempty"`
+	ActiveTrafficAnnotations      []string           `json:"activeTrafficAnnotations,omitempty"`
+	TrafficContainers             []string           `json:"trafficContainers,omitempty"`
+	ExcludePodNames               []string           `json:"excludePodNames,omitempty"`
+	CrossNamespaceEndpoints       []string           `json:"crossNamespaceEndpointNamespaces,omitempty"`
+	ServingPhases                 []string           `json:"servingPhases"`
+	ManageDaemonSetPods           bool               `json:"manageDaemonSetPods"`
+	ManageJobPods                 bool               `json:"manageJobPods"`
+	ExcludeSystemNamespaces       bool               `json:"excludeSystemNamespaces"`
+	SystemNamespaces              []string           `json:"-"`
+	DisableResourceHeuristic      bool               `json:"disableResourceHeuristic"`
+	HeuristicCPUModulos           []int64            `json:"heuristicCpuModulos,omitempty"`
+	HeuristicMemoryAlignmentBytes int64              `json:"heuristicMemoryAlignmentBytes"`
+	OnlyManageEvictions           bool               `json:"onlyManageEvictions"`
+	TCPPortsOnly                  bool               `json:"tcpPortsOnly"`
+	ConsiderHostPort              bool               `json:"considerHostPort"`
+	CrossNamespaceEndpointCheck   bool               `json:"crossNamespaceEndpointCheck"`
+	AWSTargetGroupCheck           bool               `json:"awsTargetGroupCheck"`
+	PreventLastReplicaDrain       bool               `json:"preventLastReplicaDrain"`
+	PreserveZoneAvailability      bool               `json:"preserveZoneAvailability"`
+	AgeScaledGrace                bool               `json:"ageScaledGrace"`
+	UseEndpointSlices             bool               `json:"useEndpointSlices"`
+	EndpointCheckConcurrency      int                `json:"endpointCheckConcurrency"`
+	WaitForVolumeDetach           bool               `json:"waitForVolumeDetach"`
+	KnativeAware                  bool               `json:"knativeAware"`
+	TreatMissingReadyAsReady      bool               `json:"treatMissingReadyAsReady"`
+	RespectDeletionGracePeriod    bool               `json:"respectDeletionGracePeriod"`
+	FastDrainOnNodeCordon         bool               `json:"fastDrainOnNodeCordon"`
+	NodeCordonGraceSeconds        int64              `json:"nodeCordonGraceSeconds"`
+	FastDrainOnDescheduler        bool               `json:"fastDrainOnDescheduler"`
+	DeschedulerGraceSeconds       int64              `json:"deschedulerGraceSeconds"`
+	BlockNamespaceTermination     bool               `json:"blockNamespaceTermination"`
+	MetricsNamespaceLabel         bool               `json:"metricsNamespaceLabel"`
+	ConnectionCheckMode           string             `json:"connectionCheckMode"`
+	FinalizerUpdateStrategy       string             `json:"finalizerUpdateStrategy"`
+	ConnTrackerEndpoint           string             `json:"connTrackerEndpoint,omitempty"`
+	GRPCHealthPort                int32              `json:"grpcHealthPort,omitempty"`
+	GRPCHealthService             string             `json:"grpcHealthService,omitempty"`
+	DrainCompleteWebhookURL       string             `json:"drainCompleteWebhookURL,omitempty"`
+	AuditConfigMapName            string             `json:"auditConfigMapName,omitempty"`
+	AuditMaxEntries               int                `json:"auditMaxEntries"`
+	DrainGateAPIVersion           string             `json:"drainGateAPIVersion,omitempty"`
+	DrainGateKind                 string             `json:"drainGateKind,omitempty"`
+	DrainGateFieldPath            string             `json:"drainGateFieldPath"`
+
+	// OwnerKindOverrides replaces the grace period and timeout for pods whose
+	// top-level owner has the given kind (e.g. StatefulSet)
+	OwnerKindOverrides map[string]OwnerKindOverride `json:"ownerKindOverrides,omitempty"`
+
+	// managedProgram is the compiled form of ManagedExpression
+	managedProgram cel.Program
+}
+
+// podPhases are the phase names accepted in servingPhases
+var podPhases = []string{
+	string(corev1.PodPending),
+	string(corev1.PodRunning),
+	string(corev1.PodSucceeded),
+	string(corev1.PodFailed),
+	string(corev1.PodUnknown),
+}
+
+// ConfigValidationError reports the ConfigMap key that failed validation so callers
+// can act on the field instead of matching error strings
+type ConfigValidationError struct {
+	Field  string
+	Value  string
+	Reason string
+	// Err is the underlying parse error, if the value could not be parsed at all
+	Err error
+}
+
+// Error keeps the established message formats: "invalid <field>: <cause>" for values
+// that fail to parse and "<field> <reason>" for values that violate a constraint
+func (e *ConfigValidationError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid %s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s %s", e.Field, e.Reason)
+}
+
+func (e *ConfigValidationError) Unwrap() error {
+	return e.Err
+}
+
+func newParseError(field, value string, err error) *ConfigValidationError {
+	return &ConfigValidationError{Field: field, Value: value, Reason: err.Error(), Err: err}
+}
+
+func newConstraintError(field, value, reason string) *ConfigValidationError {
+	return &ConfigValidationError{Field: field, Value: value, Reason: reason}
+}
+
+// validateQualifiedNames rejects entries that aren't Kubernetes qualified names (an optional
+// DNS subdomain prefix and a name), which the API server requires of annotation keys
+func validateQualifiedNames(field, value string, names []string) error {
+	for _, name := range names {
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return newConstraintError(field, value, fmt.Sprintf("contains invalid name %q: %s", name, strings.Join(errs, "; ")))
+		}
+	}
+	return nil
+}
+
+// validatePodNamePatterns checks that every pattern is a valid path.Match glob
+func validatePodNamePatterns(field, value string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return newConstraintError(field, value, fmt.Sprintf("contains invalid pattern %q: %s", pattern, err))
+		}
+	}
+	return nil
+}
+
+// IsSystemNamespace reports whether excludeSystemNamespaces skips the namespace
+func (c *Config) IsSystemNamespace(namespace string) bool {
+	return slices.Contains(c.SystemNamespaces, namespace)
+}
+
+// ExcludesPodName reports whether the pod name matches one of excludePodNames
+func (c *Config) ExcludesPodName(name string) bool {
+	for _, pattern := range c.ExcludePodNames {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFinalizerName checks a configured finalizer name up front, since the API server
+// only rejects an invalid one when the finalizer is first written to a pod
+func ValidateFinalizerName(name string) error {
+	if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+		return fmt.Errorf("invalid finalizer name %q: %s", name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// OwnerKindOverride holds per-owner-kind drain windows; unset fields inherit the global values
+type OwnerKindOverride struct {
+	GracePeriodSeconds  *int64 `json:"gracePeriodSeconds,omitempty"`
+	DrainTimeoutSeconds *int64 `json:"drainTimeoutSeconds,omitempty"`
 }
 
 type NamespaceSelector struct {
@@ -25,15 +207,8 @@ func (ns *NamespaceSelector) Matches(namespace string) bool {
 		return true
 	}
 
-	if len(ns.Exclude) > 0 {
-		for _, excluded := range ns.Exclude {
-			if excluded == namespace {
-				return false
-			}
-		}
-	}
-
-	if len(ns.Include) > 0 {
+	// An explicit include list (even an empty one) takes priority over exclude
+	if ns.Include != nil {
 		for _, included := range ns.Include {
 			if included == namespace {
 				return true
@@ -42,23 +217,178 @@ func (ns *NamespaceSelector) Matches(namespace string) bool {
 		return false
 	}
 
+	for _, excluded := range ns.Exclude {
+		if excluded == namespace {
+			return false
+		}
+	}
+
 	return true
 }
 
-func NewDefaultConfig() *Config {
+const (
+	// DefaultGracePeriodSeconds is the grace period when neither the flags nor the configuration set one
+	DefaultGracePeriodSeconds = 30
+	// DefaultDrainTimeoutSeconds is the drain timeout when neither the flags nor the configuration set one
+	DefaultDrainTimeoutSeconds = 300
+)
+
+// DefaultConfigOptions are the baseline settings a Config starts from before the ConfigMaps
+// are applied, so the controller's flags or an embedder can change them without a ConfigMap
+type DefaultConfigOptions struct {
+	GracePeriodSeconds  int64
+	DrainTimeoutSeconds int64
+	// ControllerNamespace is the controller's own namespace, skipped along with the system
+	// namespaces by excludeSystemNamespaces
+	ControllerNamespace string
+}
+
+// NewDefaultConfigOptions returns the built-in baseline settings
+func NewDefaultConfigOptions() DefaultConfigOptions {
+	return DefaultConfigOptions{
+		GracePeriodSeconds:  DefaultGracePeriodSeconds,
+		DrainTimeoutSeconds: DefaultDrainTimeoutSeconds,
+	}
+}
+
+// Validate checks the settings are usable and within the bounds the configuration is held to
+func (o DefaultConfigOptions) Validate(bounds ConfigBounds) error {
+	if o.GracePeriodSeconds < 0 || o.GracePeriodSeconds > bounds.MaxGracePeriodSeconds {
+		return fmt.Errorf("default grace period must be between 0 and %d, got: %d", bounds.MaxGracePeriodSeconds, o.GracePeriodSeconds)
+	}
+	if o.DrainTimeoutSeconds <= 0 || o.DrainTimeoutSeconds > bounds.MaxDrainTimeoutSeconds {
+		return fmt.Errorf("default drain timeout must be between 1 and %d, got: %d", bounds.MaxDrainTimeoutSeconds, o.DrainTimeoutSeconds)
+	}
+	if o.DrainTimeoutSeconds < o.GracePeriodSeconds {
+		return fmt.Errorf("default drain timeout (%d) must be greater than the default grace period (%d)", o.DrainTimeoutSeconds, o.GracePeriodSeconds)
+	}
+	return nil
+}
+
+// DefaultConfigOption adjusts the DefaultConfigOptions NewDefaultConfig starts from
+type DefaultConfigOption func(*DefaultConfigOptions)
+
+// WithDefaults replaces the baseline settings as a whole
+func WithDefaults(defaults DefaultConfigOptions) DefaultConfigOption {
+	return func(o *DefaultConfigOptions) {
+		*o = defaults
+	}
+}
+
+// WithDefaultGracePeriod sets the baseline grace period
+func WithDefaultGracePeriod(seconds int64) DefaultConfigOption {
+	return func(o *DefaultConfigOptions) {
+		o.GracePeriodSeconds = seconds
+	}
+}
+
+// WithDefaultDrainTimeout sets the baseline drain timeout
+func WithDefaultDrainTimeout(seconds int64) DefaultConfigOption {
+	return func(o *DefaultConfigOptions) {
+		o.DrainTimeoutSeconds = seconds
+	}
+}
+
+// WithControllerNamespace records the controller's own namespace
+func WithControllerNamespace(namespace string) DefaultConfigOption {
+	return func(o *DefaultConfigOptions) {
+		o.ControllerNamespace = namespace
+	}
+}
+
+func applyDefaultConfigOptions(opts []DefaultConfigOption) DefaultConfigOptions {
+	defaults := NewDefaultConfigOptions()
+	for _, opt := range opts {
+		opt(&defaults)
+	}
+	return defaults
+}
+
+// NewDefaultConfig returns the configuration used when no ConfigMap sets anything. Without
+// options it yields the built-in defaults.
+func NewDefaultConfig(opts ...DefaultConfigOption) *Config {
+	defaults := applyDefaultConfigOptions(opts)
+
 	return &Config{
-		GracePeriodSeconds:  30,
-		DrainTimeoutSeconds: 300,
-		NamespaceSelector:   nil,
+		Enabled:                       true,
+		GracePeriodSeconds:            defaults.GracePeriodSeconds,
+		DrainTimeoutSeconds:           defaults.DrainTimeoutSeconds,
+		HardDeadlineBufferSeconds:     60,
+		APICallTimeoutSeconds:         5,
+		ConnectionPollIntervalSeconds: 10,
+		MaxPollIntervalSeconds:        60,
+		EndpointSettleSeconds:         5,
+		WaitingLogIntervalSeconds:     60,
+		KnativeSettleSeconds:          30,
+		AgeScaledGraceMinSeconds:      5,
+		AgeScaledGraceFullAgeSeconds:  3600,
+		NodeCordonGraceSeconds:        5,
+		DeschedulerGraceSeconds:       5,
+		SlowDrainThreshold:            0.8,
+		EndpointCheckConcurrency:      1,
+		NamespaceSelector:             nil,
+		TCPPortsOnly:                  true,
+		ConnectionCheckMode:           finalizer.ConnectionCheckModeEndpoints,
+		FinalizerUpdateStrategy:       FinalizerUpdateStrategyUpdate,
+		DrainGateFieldPath:            finalizer.DefaultDrainGateFieldPath,
+		OnTimeoutWithConnections:      finalizer.OnTimeoutForceComplete,
+		OnConnectionCheckError:        finalizer.OnConnectionCheckErrorRetry,
+		TimeoutExtensionSeconds:       60,
+		MaxTimeoutExtensions:          1,
+		AuditMaxEntries:               100,
+		ServingPhases:                 []string{string(corev1.PodRunning)},
+		HeuristicCPUModulos:           []int64{100, 50},
+		HeuristicMemoryAlignmentBytes: 1024 * 1024,
+	}
+}
+
+// ConfigBounds are the upper limits enforced on the grace period and drain timeout,
+// which the controller's flags can raise for workloads that need longer drains
+type ConfigBounds struct {
+	MaxGracePeriodSeconds  int64
+	MaxDrainTimeoutSeconds int64
+}
+
+const (
+	// DefaultMaxGracePeriodSeconds is the default upper limit of gracePeriodSeconds (1 hour)
+	DefaultMaxGracePeriodSeconds = 3600
+	// DefaultMaxDrainTimeoutSeconds is the default upper limit of drainTimeoutSeconds (2 hours)
+	DefaultMaxDrainTimeoutSeconds = 7200
+)
+
+// DefaultConfigBounds returns the bounds ParseConfig applies
+func DefaultConfigBounds() ConfigBounds {
+	return ConfigBounds{
+		MaxGracePeriodSeconds:  DefaultMaxGracePeriodSeconds,
+		MaxDrainTimeoutSeconds: DefaultMaxDrainTimeoutSeconds,
 	}
 }
 
+// Validate rejects bounds that would make every grace period or drain timeout invalid
+func (b ConfigBounds) Validate() error {
+	if b.MaxGracePeriodSeconds < 0 {
+		return fmt.Errorf("max grace period must be non-negative, got: %d", b.MaxGracePeriodSeconds)
+	}
+	if b.MaxDrainTimeoutSeconds <= 0 {
+		return fmt.Errorf("max drain timeout must be positive, got: %d", b.MaxDrainTimeoutSeconds)
+	}
+	return nil
+}
+
+// ParseConfig parses the ConfigMap with the default bounds
 func ParseConfig(configMap *corev1.ConfigMap) (*Config, error) {
+	return ParseConfigWithBounds(configMap, DefaultConfigBounds())
+}
+
+// ParseConfigWithBounds parses the ConfigMap, limiting the grace period and drain timeout
+// (including owner kind overrides) to the given bounds. Settings the ConfigMap leaves out
+// come from NewDefaultConfig with the given options.
+func ParseConfigWithBounds(configMap *corev1.ConfigMap, bounds ConfigBounds, opts ...DefaultConfigOption) (*Config, error) {
 	if configMap == nil {
 		return nil, fmt.Errorf("configMap cannot be nil")
 	}
 
-	config := NewDefaultConfig()
+	config := NewDefaultConfig(opts...)
 
 	if configMap.Data == nil {
 		return config, nil
@@ -67,45 +397,808 @@ func ParseConfig(configMap *corev1.ConfigMap) (*Config, error) {
 	if gracePeriodStr, exists := configMap.Data["gracePeriodSeconds"]; exists {
 		if gracePeriod, err := strconv.ParseInt(gracePeriodStr, 10, 64); err == nil {
 			if gracePeriod < 0 {
-				return nil, fmt.Errorf("gracePeriodSeconds must be non-negative, got: %d", gracePeriod)
+				return nil, newConstraintError("gracePeriodSeconds", gracePeriodStr, fmt.Sprintf("must be non-negative, got: %d", gracePeriod))
 			}
-			if gracePeriod > 3600 {
-				return nil, fmt.Errorf("gracePeriodSeconds must be less than 3600 (1 hour), got: %d", gracePeriod)
+			if gracePeriod > bounds.MaxGracePeriodSeconds {
+				return nil, newConstraintError("gracePeriodSeconds", gracePeriodStr, fmt.Sprintf("must be less than %d, got: %d", bounds.MaxGracePeriodSeconds, gracePeriod))
 			}
 			config.GracePeriodSeconds = gracePeriod
 		} else {
-			return nil, fmt.Errorf("invalid gracePeriodSeconds: %v", err)
+			return nil, newParseError("gracePeriodSeconds", gracePeriodStr, err)
 		}
 	}
 
 	if drainTimeoutStr, exists := configMap.Data["drainTimeoutSeconds"]; exists {
 		if drainTimeout, err := strconv.ParseInt(drainTimeoutStr, 10, 64); err == nil {
 			if drainTimeout <= 0 {
-				return nil, fmt.Errorf("drainTimeoutSeconds must be positive, got: %d", drainTimeout)
+				return nil, newConstraintError("drainTimeoutSeconds", drainTimeoutStr, fmt.Sprintf("must be positive, got: %d", drainTimeout))
 			}
-			if drainTimeout > 7200 {
-				return nil, fmt.Errorf("drainTimeoutSeconds must be less than 7200 (2 hours), got: %d", drainTimeout)
-			}
-			if drainTimeout < config.GracePeriodSeconds {
-				return nil, fmt.Errorf("drainTimeoutSeconds (%d) must be greater than gracePeriodSeconds (%d)", drainTimeout, config.GracePeriodSeconds)
+			if drainTimeout > bounds.MaxDrainTimeoutSeconds {
+				return nil, newConstraintError("drainTimeoutSeconds", drainTimeoutStr, fmt.Sprintf("must be less than %d, got: %d", bounds.MaxDrainTimeoutSeconds, drainTimeout))
 			}
 			config.DrainTimeoutSeconds = drainTimeout
 		} else {
-			return nil, fmt.Errorf("invalid drainTimeoutSeconds: %v", err)
+			return nil, newParseError("drainTimeoutSeconds", drainTimeoutStr, err)
+		}
+	}
+
+	if hardDeadlineBufferStr, exists := configMap.Data["hardDeadlineBufferSeconds"]; exists {
+		if hardDeadlineBuffer, err := strconv.ParseInt(hardDeadlineBufferStr, 10, 64); err == nil {
+			if hardDeadlineBuffer < 0 {
+				return nil, newConstraintError("hardDeadlineBufferSeconds", hardDeadlineBufferStr, fmt.Sprintf("must be non-negative, got: %d", hardDeadlineBuffer))
+			}
+			if hardDeadlineBuffer > 3600 {
+				return nil, newConstraintError("hardDeadlineBufferSeconds", hardDeadlineBufferStr, fmt.Sprintf("must be less than 3600 (1 hour), got: %d", hardDeadlineBuffer))
+			}
+			config.HardDeadlineBufferSeconds = hardDeadlineBuffer
+		} else {
+			return nil, newParseError("hardDeadlineBufferSeconds", hardDeadlineBufferStr, err)
+		}
+	}
+
+	if apiCallTimeoutStr, exists := configMap.Data["apiCallTimeoutSeconds"]; exists {
+		if apiCallTimeout, err := strconv.ParseInt(apiCallTimeoutStr, 10, 64); err == nil {
+			if apiCallTimeout <= 0 {
+				return nil, newConstraintError("apiCallTimeoutSeconds", apiCallTimeoutStr, fmt.Sprintf("must be positive, got: %d", apiCallTimeout))
+			}
+			if apiCallTimeout > 60 {
+				return nil, newConstraintError("apiCallTimeoutSeconds", apiCallTimeoutStr, fmt.Sprintf("must be less than 60 (1 minute), got: %d", apiCallTimeout))
+			}
+			config.APICallTimeoutSeconds = apiCallTimeout
+		} else {
+			return nil, newParseError("apiCallTimeoutSeconds", apiCallTimeoutStr, err)
+		}
+	}
+
+	if pollIntervalStr, exists := configMap.Data["connectionPollIntervalSeconds"]; exists {
+		if pollInterval, err := strconv.ParseInt(pollIntervalStr, 10, 64); err == nil {
+			if pollInterval <= 0 {
+				return nil, newConstraintError("connectionPollIntervalSeconds", pollIntervalStr, fmt.Sprintf("must be positive, got: %d", pollInterval))
+			}
+			if pollInterval > 60 {
+				return nil, newConstraintError("connectionPollIntervalSeconds", pollIntervalStr, fmt.Sprintf("must be less than 60 (1 minute), got: %d", pollInterval))
+			}
+			config.ConnectionPollIntervalSeconds = pollInterval
+		} else {
+			return nil, newParseError("connectionPollIntervalSeconds", pollIntervalStr, err)
+		}
+	}
+
+	if maxPollIntervalStr, exists := configMap.Data["maxPollIntervalSeconds"]; exists {
+		if maxPollInterval, err := strconv.ParseInt(maxPollIntervalStr, 10, 64); err == nil {
+			if maxPollInterval < config.ConnectionPollIntervalSeconds {
+				return nil, newConstraintError("maxPollIntervalSeconds", maxPollIntervalStr, fmt.Sprintf("(%d) must not be less than connectionPollIntervalSeconds (%d)", maxPollInterval, config.ConnectionPollIntervalSeconds))
+			}
+			if maxPollInterval > 600 {
+				return nil, newConstraintError("maxPollIntervalSeconds", maxPollIntervalStr, fmt.Sprintf("must be less than 600 (10 minutes), got: %d", maxPollInterval))
+			}
+			config.MaxPollIntervalSeconds = maxPollInterval
+		} else {
+			return nil, newParseError("maxPollIntervalSeconds", maxPollIntervalStr, err)
+		}
+	}
+
+	if settleStr, exists := configMap.Data["endpointSettleSeconds"]; exists {
+		if settle, err := strconv.ParseInt(settleStr, 10, 64); err == nil {
+			if settle < 0 {
+				return nil, newConstraintError("endpointSettleSeconds", settleStr, fmt.Sprintf("must not be negative, got: %d", settle))
+			}
+			if settle > 60 {
+				return nil, newConstraintError("endpointSettleSeconds", settleStr, fmt.Sprintf("must be less than 60 (1 minute), got: %d", settle))
+			}
+			config.EndpointSettleSeconds = settle
+		} else {
+			return nil, newParseError("endpointSettleSeconds", settleStr, err)
+		}
+	}
+
+	if err := parseBoolField(configMap.Data, "ageScaledGrace", &config.AgeScaledGrace); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "useEndpointSlices", &config.UseEndpointSlices); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "waitForVolumeDetach", &config.WaitForVolumeDetach); err != nil {
+		return nil, err
+	}
+
+	if minGraceStr, exists := configMap.Data["ageScaledGraceMinSeconds"]; exists {
+		if minGrace, err := strconv.ParseInt(minGraceStr, 10, 64); err == nil {
+			if minGrace < 0 {
+				return nil, newConstraintError("ageScaledGraceMinSeconds", minGraceStr, fmt.Sprintf("must not be negative, got: %d", minGrace))
+			}
+			config.AgeScaledGraceMinSeconds = minGrace
+		} else {
+			return nil, newParseError("ageScaledGraceMinSeconds", minGraceStr, err)
+		}
+	}
+
+	if fullAgeStr, exists := configMap.Data["ageScaledGraceFullAgeSeconds"]; exists {
+		if fullAge, err := strconv.ParseInt(fullAgeStr, 10, 64); err == nil {
+			if fullAge <= 0 {
+				return nil, newConstraintError("ageScaledGraceFullAgeSeconds", fullAgeStr, fmt.Sprintf("must be positive, got: %d", fullAge))
+			}
+			if fullAge > 604800 {
+				return nil, newConstraintError("ageScaledGraceFullAgeSeconds", fullAgeStr, fmt.Sprintf("must be at most 604800 (7 days), got: %d", fullAge))
+			}
+			config.AgeScaledGraceFullAgeSeconds = fullAge
+		} else {
+			return nil, newParseError("ageScaledGraceFullAgeSeconds", fullAgeStr, err)
+		}
+	}
+
+	if minimumServingStr, exists := configMap.Data["minimumServingSeconds"]; exists {
+		if minimumServing, err := strconv.ParseInt(minimumServingStr, 10, 64); err == nil {
+			if minimumServing < 0 {
+				return nil, newConstraintError("minimumServingSeconds", minimumServingStr, fmt.Sprintf("must not be negative, got: %d", minimumServing))
+			}
+			if minimumServing > 3600 {
+				return nil, newConstraintError("minimumServingSeconds", minimumServingStr, fmt.Sprintf("must be less than 3600 (1 hour), got: %d", minimumServing))
+			}
+			config.MinimumServingSeconds = minimumServing
+		} else {
+			return nil, newParseError("minimumServingSeconds", minimumServingStr, err)
+		}
+	}
+
+	if postDeregistrationStr, exists := configMap.Data["postDeregistrationSeconds"]; exists {
+		if postDeregistration, err := strconv.ParseInt(postDeregistrationStr, 10, 64); err == nil {
+			if postDeregistration < 0 {
+				return nil, newConstraintError("postDeregistrationSeconds", postDeregistrationStr, fmt.Sprintf("must not be negative, got: %d", postDeregistration))
+			}
+			if postDeregistration > 3600 {
+				return nil, newConstraintError("postDeregistrationSeconds", postDeregistrationStr, fmt.Sprintf("must be less than 3600 (1 hour), got: %d", postDeregistration))
+			}
+			config.PostDeregistrationSeconds = postDeregistration
+		} else {
+			return nil, newParseError("postDeregistrationSeconds", postDeregistrationStr, err)
+		}
+	}
+
+	if knativeSettleStr, exists := configMap.Data["knativeSettleSeconds"]; exists {
+		if knativeSettle, err := strconv.ParseInt(knativeSettleStr, 10, 64); err == nil {
+			if knativeSettle < 0 {
+				return nil, newConstraintError("knativeSettleSeconds", knativeSettleStr, fmt.Sprintf("must not be negative, got: %d", knativeSettle))
+			}
+			if knativeSettle > 600 {
+				return nil, newConstraintError("knativeSettleSeconds", knativeSettleStr, fmt.Sprintf("must be less than 600 (10 minutes), got: %d", knativeSettle))
+			}
+			config.KnativeSettleSeconds = knativeSettle
+		} else {
+			return nil, newParseError("knativeSettleSeconds", knativeSettleStr, err)
+		}
+	}
+
+	if cordonGraceStr, exists := configMap.Data["nodeCordonGraceSeconds"]; exists {
+		if cordonGrace, err := strconv.ParseInt(cordonGraceStr, 10, 64); err == nil {
+			if cordonGrace < 0 {
+				return nil, newConstraintError("nodeCordonGraceSeconds", cordonGraceStr, fmt.Sprintf("must not be negative, got: %d", cordonGrace))
+			}
+			if cordonGrace > 300 {
+				return nil, newConstraintError("nodeCordonGraceSeconds", cordonGraceStr, fmt.Sprintf("must be less than 300 (5 minutes), got: %d", cordonGrace))
+			}
+			config.NodeCordonGraceSeconds = cordonGrace
+		} else {
+			return nil, newParseError("nodeCordonGraceSeconds", cordonGraceStr, err)
+		}
+	}
+
+	if deschedulerGraceStr, exists := configMap.Data["deschedulerGraceSeconds"]; exists {
+		if deschedulerGrace, err := strconv.ParseInt(deschedulerGraceStr, 10, 64); err == nil {
+			if deschedulerGrace < 0 {
+				return nil, newConstraintError("deschedulerGraceSeconds", deschedulerGraceStr, fmt.Sprintf("must not be negative, got: %d", deschedulerGrace))
+			}
+			if deschedulerGrace > 300 {
+				return nil, newConstraintError("deschedulerGraceSeconds", deschedulerGraceStr, fmt.Sprintf("must be less than 300 (5 minutes), got: %d", deschedulerGrace))
+			}
+			config.DeschedulerGraceSeconds = deschedulerGrace
+		} else {
+			return nil, newParseError("deschedulerGraceSeconds", deschedulerGraceStr, err)
+		}
+	}
+
+	if intervalStr, exists := configMap.Data["waitingLogIntervalSeconds"]; exists {
+		if interval, err := strconv.ParseInt(intervalStr, 10, 64); err == nil {
+			if interval < 0 {
+				return nil, newConstraintError("waitingLogIntervalSeconds", intervalStr, fmt.Sprintf("must not be negative, got: %d", interval))
+			}
+			if interval > 3600 {
+				return nil, newConstraintError("waitingLogIntervalSeconds", intervalStr, fmt.Sprintf("must be less than 3600 (1 hour), got: %d", interval))
+			}
+			config.WaitingLogIntervalSeconds = interval
+		} else {
+			return nil, newParseError("waitingLogIntervalSeconds", intervalStr, err)
+		}
+	}
+
+	if thresholdStr, exists := configMap.Data["trafficWeightThreshold"]; exists {
+		if threshold, err := strconv.ParseFloat(thresholdStr, 64); err == nil {
+			if threshold < 0 || threshold > 1 {
+				return nil, newConstraintError("trafficWeightThreshold", thresholdStr, fmt.Sprintf("must be between 0 and 1, got: %s", thresholdStr))
+			}
+			config.TrafficWeightThreshold = threshold
+		} else {
+			return nil, newParseError("trafficWeightThreshold", thresholdStr, err)
+		}
+	}
+
+	if slowStr, exists := configMap.Data["slowDrainThreshold"]; exists {
+		if slow, err := strconv.ParseFloat(slowStr, 64); err == nil {
+			if slow < 0 || slow > 1 {
+				return nil, newConstraintError("slowDrainThreshold", slowStr, fmt.Sprintf("must be between 0 and 1, got: %s", slowStr))
+			}
+			config.SlowDrainThreshold = slow
+		} else {
+			return nil, newParseError("slowDrainThreshold", slowStr, err)
+		}
+	}
+
+	if kind, exists := configMap.Data["drainGateKind"]; exists {
+		apiVersion := configMap.Data["drainGateAPIVersion"]
+		if _, err := schema.ParseGroupVersion(apiVersion); err != nil || apiVersion == "" {
+			return nil, newConstraintError("drainGateAPIVersion", apiVersion, "must be a valid apiVersion such as example.com/v1 when drainGateKind is set")
+		}
+		config.DrainGateAPIVersion = apiVersion
+		config.DrainGateKind = kind
+	}
+
+	if fieldPath, exists := configMap.Data["drainGateFieldPath"]; exists {
+		if err := jsonpath.New("drainGateFieldPath").Parse(fieldPath); err != nil {
+			return nil, newParseError("drainGateFieldPath", fieldPath, err)
+		}
+		config.DrainGateFieldPath = fieldPath
+	}
+
+	if strategy, exists := configMap.Data["finalizerUpdateStrategy"]; exists {
+		switch strategy {
+		case FinalizerUpdateStrategyUpdate, FinalizerUpdateStrategyPatch:
+			config.FinalizerUpdateStrategy = strategy
+		default:
+			return nil, newConstraintError("finalizerUpdateStrategy", strategy, fmt.Sprintf("must be %q or %q, got: %q",
+				FinalizerUpdateStrategyUpdate, FinalizerUpdateStrategyPatch, strategy))
+		}
+	}
+
+	if onTimeout, exists := configMap.Data["onTimeoutWithConnections"]; exists {
+		switch onTimeout {
+		case finalizer.OnTimeoutForceComplete, finalizer.OnTimeoutExtend:
+			config.OnTimeoutWithConnections = onTimeout
+		default:
+			return nil, newConstraintError("onTimeoutWithConnections", onTimeout, fmt.Sprintf("must be %q or %q, got: %q",
+				finalizer.OnTimeoutForceComplete, finalizer.OnTimeoutExtend, onTimeout))
+		}
+	}
+
+	if onError, exists := configMap.Data["onConnectionCheckError"]; exists {
+		switch onError {
+		case finalizer.OnConnectionCheckErrorRetry, finalizer.OnConnectionCheckErrorAssumeDrained, finalizer.OnConnectionCheckErrorAssumeServing:
+			config.OnConnectionCheckError = onError
+		default:
+			return nil, newConstraintError("onConnectionCheckError", onError, fmt.Sprintf("must be %q, %q or %q, got: %q",
+				finalizer.OnConnectionCheckErrorRetry, finalizer.OnConnectionCheckErrorAssumeDrained,
+				finalizer.OnConnectionCheckErrorAssumeServing, onError))
+		}
+	}
+
+	if extensionStr, exists := configMap.Data["timeoutExtensionSeconds"]; exists {
+		if extension, err := strconv.ParseInt(extensionStr, 10, 64); err == nil {
+			if extension <= 0 {
+				return nil, newConstraintError("timeoutExtensionSeconds", extensionStr, fmt.Sprintf("must be positive, got: %d", extension))
+			}
+			if extension > 3600 {
+				return nil, newConstraintError("timeoutExtensionSeconds", extensionStr, fmt.Sprintf("must be less than 3600 (1 hour), got: %d", extension))
+			}
+			config.TimeoutExtensionSeconds = extension
+		} else {
+			return nil, newParseError("timeoutExtensionSeconds", extensionStr, err)
+		}
+	}
+
+	if maxExtensionsStr, exists := configMap.Data["maxTimeoutExtensions"]; exists {
+		if maxExtensions, err := strconv.Atoi(maxExtensionsStr); err == nil {
+			if maxExtensions < 0 {
+				return nil, newConstraintError("maxTimeoutExtensions", maxExtensionsStr, fmt.Sprintf("must not be negative, got: %d", maxExtensions))
+			}
+			if maxExtensions > 10 {
+				return nil, newConstraintError("maxTimeoutExtensions", maxExtensionsStr, fmt.Sprintf("must be at most 10, got: %d", maxExtensions))
+			}
+			config.MaxTimeoutExtensions = maxExtensions
+		} else {
+			return nil, newParseError("maxTimeoutExtensions", maxExtensionsStr, err)
+		}
+	}
+
+	if concurrencyStr, exists := configMap.Data["endpointCheckConcurrency"]; exists {
+		if concurrency, err := strconv.Atoi(concurrencyStr); err == nil {
+			if concurrency < 1 {
+				return nil, newConstraintError("endpointCheckConcurrency", concurrencyStr, fmt.Sprintf("must be at least 1, got: %d", concurrency))
+			}
+			if concurrency > 32 {
+				return nil, newConstraintError("endpointCheckConcurrency", concurrencyStr, fmt.Sprintf("must be at most 32, got: %d", concurrency))
+			}
+			config.EndpointCheckConcurrency = concurrency
+		} else {
+			return nil, newParseError("endpointCheckConcurrency", concurrencyStr, err)
 		}
 	}
 
 	if namespaceSelectorStr, exists := configMap.Data["namespaceSelector"]; exists {
 		var namespaceSelector NamespaceSelector
 		if err := json.Unmarshal([]byte(namespaceSelectorStr), &namespaceSelector); err != nil {
-			return nil, fmt.Errorf("invalid namespaceSelector JSON: %v", err)
+			return nil, newParseError("namespaceSelector", namespaceSelectorStr, err)
 		}
 		config.NamespaceSelector = &namespaceSelector
 	}
 
+	if manageIfAnnotationsStr, exists := configMap.Data["manageIfAnnotations"]; exists {
+		var manageIfAnnotations []string
+		if err := json.Unmarshal([]byte(manageIfAnnotationsStr), &manageIfAnnotations); err != nil {
+			return nil, newParseError("manageIfAnnotations", manageIfAnnotationsStr, err)
+		}
+		if err := validateQualifiedNames("manageIfAnnotations", manageIfAnnotationsStr, manageIfAnnotations); err != nil {
+			return nil, err
+		}
+		config.ManageIfAnnotations = manageIfAnnotations
+	}
+
+	if activeTrafficStr, exists := configMap.Data["activeTrafficAnnotations"]; exists {
+		var activeTrafficAnnotations []string
+		if err := json.Unmarshal([]byte(activeTrafficStr), &activeTrafficAnnotations); err != nil {
+			return nil, newParseError("activeTrafficAnnotations", activeTrafficStr, err)
+		}
+		if err := validateQualifiedNames("activeTrafficAnnotations", activeTrafficStr, activeTrafficAnnotations); err != nil {
+			return nil, err
+		}
+		config.ActiveTrafficAnnotations = activeTrafficAnnotations
+	}
+
+	if trafficContainersStr, exists := configMap.Data["trafficContainers"]; exists {
+		var trafficContainers []string
+		if err := json.Unmarshal([]byte(trafficContainersStr), &trafficContainers); err != nil {
+			return nil, newParseError("trafficContainers", trafficContainersStr, err)
+		}
+		config.TrafficContainers = trafficContainers
+	}
+
+	if excludePodNamesStr, exists := configMap.Data["excludePodNames"]; exists {
+		var excludePodNames []string
+		if err := json.Unmarshal([]byte(excludePodNamesStr), &excludePodNames); err != nil {
+			return nil, newParseError("excludePodNames", excludePodNamesStr, err)
+		}
+		if err := validatePodNamePatterns("excludePodNames", excludePodNamesStr, excludePodNames); err != nil {
+			return nil, err
+		}
+		config.ExcludePodNames = excludePodNames
+	}
+
+	if namespacesStr, exists := configMap.Data["crossNamespaceEndpointNamespaces"]; exists {
+		var namespaces []string
+		if err := json.Unmarshal([]byte(namespacesStr), &namespaces); err != nil {
+			return nil, newParseError("crossNamespaceEndpointNamespaces", namespacesStr, err)
+		}
+		for _, namespace := range namespaces {
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				return nil, newConstraintError("crossNamespaceEndpointNamespaces", namespacesStr,
+					fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, "; ")))
+			}
+		}
+		config.CrossNamespaceEndpoints = namespaces
+	}
+
+	if servingPhasesStr, exists := configMap.Data["servingPhases"]; exists {
+		var servingPhases []string
+		if err := json.Unmarshal([]byte(servingPhasesStr), &servingPhases); err != nil {
+			return nil, newParseError("servingPhases", servingPhasesStr, err)
+		}
+		if len(servingPhases) == 0 {
+			return nil, newConstraintError("servingPhases", servingPhasesStr, "must list at least one phase")
+		}
+		for _, phase := range servingPhases {
+			if !slices.Contains(podPhases, phase) {
+				return nil, newConstraintError("servingPhases", servingPhasesStr,
+					fmt.Sprintf("unknown pod phase %q, must be one of %s", phase, strings.Join(podPhases, ", ")))
+			}
+		}
+		config.ServingPhases = servingPhases
+	}
+
+	if err := parseBoolField(configMap.Data, "enabled", &config.Enabled); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "fastDrainOnNodeCordon", &config.FastDrainOnNodeCordon); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "fastDrainOnDescheduler", &config.FastDrainOnDescheduler); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "disableResourceHeuristic", &config.DisableResourceHeuristic); err != nil {
+		return nil, err
+	}
+
+	if modulosStr, exists := configMap.Data["heuristicCpuModulos"]; exists {
+		var modulos []int64
+		if err := json.Unmarshal([]byte(modulosStr), &modulos); err != nil {
+			return nil, newParseError("heuristicCpuModulos", modulosStr, err)
+		}
+		for _, modulo := range modulos {
+			if modulo <= 0 {
+				return nil, newConstraintError("heuristicCpuModulos", modulosStr, fmt.Sprintf("modulos must be positive, got: %d", modulo))
+			}
+		}
+		config.HeuristicCPUModulos = modulos
+	}
+
+	if alignmentStr, exists := configMap.Data["heuristicMemoryAlignmentBytes"]; exists {
+		if alignment, err := strconv.ParseInt(alignmentStr, 10, 64); err == nil {
+			if alignment <= 0 {
+				return nil, newConstraintError("heuristicMemoryAlignmentBytes", alignmentStr, fmt.Sprintf("must be positive, got: %d", alignment))
+			}
+			config.HeuristicMemoryAlignmentBytes = alignment
+		} else {
+			return nil, newParseError("heuristicMemoryAlignmentBytes", alignmentStr, err)
+		}
+	}
+
+	if err := parseBoolField(configMap.Data, "blockNamespaceTermination", &config.BlockNamespaceTermination); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "excludeSystemNamespaces", &config.ExcludeSystemNamespaces); err != nil {
+		return nil, err
+	}
+	if config.ExcludeSystemNamespaces {
+		config.SystemNamespaces = systemNamespaces(applyDefaultConfigOptions(opts).ControllerNamespace)
+	}
+
+	if overridesStr, exists := configMap.Data["ownerKindOverrides"]; exists {
+		overrides, err := parseOwnerKindOverrides(overridesStr, config, bounds)
+		if err != nil {
+			return nil, err
+		}
+		config.OwnerKindOverrides = overrides
+	}
+
+	if err := parseBoolField(configMap.Data, "manageDaemonSetPods", &config.ManageDaemonSetPods); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "manageJobPods", &config.ManageJobPods); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "onlyManageEvictions", &config.OnlyManageEvictions); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "metricsNamespaceLabel", &config.MetricsNamespaceLabel); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "considerHostPort", &config.ConsiderHostPort); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "crossNamespaceEndpointCheck", &config.CrossNamespaceEndpointCheck); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "awsTargetGroupCheck", &config.AWSTargetGroupCheck); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "preventLastReplicaDrain", &config.PreventLastReplicaDrain); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "preserveZoneAvailability", &config.PreserveZoneAvailability); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "knativeAware", &config.KnativeAware); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "tcpPortsOnly", &config.TCPPortsOnly); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "treatMissingReadyAsReady", &config.TreatMissingReadyAsReady); err != nil {
+		return nil, err
+	}
+
+	if err := parseBoolField(configMap.Data, "respectDeletionGracePeriod", &config.RespectDeletionGracePeriod); err != nil {
+		return nil, err
+	}
+
+	if mode, exists := configMap.Data["connectionCheckMode"]; exists {
+		if mode != finalizer.ConnectionCheckModeEndpoints && mode != finalizer.ConnectionCheckModeConntrack &&
+			mode != finalizer.ConnectionCheckModeGRPCHealth {
+			return nil, newConstraintError("connectionCheckMode", mode, fmt.Sprintf("must be %q, %q or %q, got: %s",
+				finalizer.ConnectionCheckModeEndpoints, finalizer.ConnectionCheckModeConntrack, finalizer.ConnectionCheckModeGRPCHealth, mode))
+		}
+		config.ConnectionCheckMode = mode
+	}
+
+	if endpoint, exists := configMap.Data["connTrackerEndpoint"]; exists && endpoint != "" {
+		// Validate with a sample node name substituted so the placeholder doesn't break parsing
+		sample := strings.ReplaceAll(endpoint, conntrack.NodeNamePlaceholder, "node")
+		if _, err := url.ParseRequestURI(sample); err != nil {
+			return nil, newParseError("connTrackerEndpoint", endpoint, err)
+		}
+		config.ConnTrackerEndpoint = endpoint
+	}
+
+	if config.ConnectionCheckMode == finalizer.ConnectionCheckModeConntrack && config.ConnTrackerEndpoint == "" {
+		return nil, newConstraintError("connTrackerEndpoint", "", fmt.Sprintf("is required when connectionCheckMode is %q", finalizer.ConnectionCheckModeConntrack))
+	}
+
+	if portStr, exists := configMap.Data["grpcHealthPort"]; exists {
+		if port, err := strconv.ParseInt(portStr, 10, 32); err == nil {
+			if port < 1 || port > 65535 {
+				return nil, newConstraintError("grpcHealthPort", portStr, fmt.Sprintf("must be between 1 and 65535, got: %d", port))
+			}
+			config.GRPCHealthPort = int32(port)
+		} else {
+			return nil, newParseError("grpcHealthPort", portStr, err)
+		}
+	}
+
+	if service, exists := configMap.Data["grpcHealthService"]; exists {
+		config.GRPCHealthService = service
+	}
+
+	if config.ConnectionCheckMode == finalizer.ConnectionCheckModeGRPCHealth && config.GRPCHealthPort == 0 {
+		return nil, newConstraintError("grpcHealthPort", "", fmt.Sprintf("is required when connectionCheckMode is %q", finalizer.ConnectionCheckModeGRPCHealth))
+	}
+
+	if webhookURL, exists := configMap.Data["drainCompleteWebhookURL"]; exists && webhookURL != "" {
+		parsedURL, err := url.ParseRequestURI(webhookURL)
+		if err != nil {
+			return nil, newParseError("drainCompleteWebhookURL", webhookURL, err)
+		}
+		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+			return nil, newConstraintError("drainCompleteWebhookURL", webhookURL, fmt.Sprintf("must use http or https, got: %s", parsedURL.Scheme))
+		}
+		config.DrainCompleteWebhookURL = webhookURL
+	}
+
+	if auditName, exists := configMap.Data["auditConfigMapName"]; exists && auditName != "" {
+		if errs := validation.IsDNS1123Subdomain(auditName); len(errs) > 0 {
+			return nil, newConstraintError("auditConfigMapName", auditName, fmt.Sprintf("must be a valid ConfigMap name: %s", strings.Join(errs, "; ")))
+		}
+		config.AuditConfigMapName = auditName
+	}
+
+	if maxEntriesStr, exists := configMap.Data["auditMaxEntries"]; exists {
+		if maxEntries, err := strconv.Atoi(maxEntriesStr); err == nil {
+			if maxEntries < 1 || maxEntries > 1000 {
+				return nil, newConstraintError("auditMaxEntries", maxEntriesStr, fmt.Sprintf("must be between 1 and 1000, got: %d", maxEntries))
+			}
+			config.AuditMaxEntries = maxEntries
+		} else {
+			return nil, newParseError("auditMaxEntries", maxEntriesStr, err)
+		}
+	}
+
+	if managedExpression, exists := configMap.Data["managedExpression"]; exists && managedExpression != "" {
+		program, err := compileManagedExpression(managedExpression)
+		if err != nil {
+			return nil, newParseError("managedExpression", managedExpression, err)
+		}
+		config.ManagedExpression = managedExpression
+		config.managedProgram = program
+	}
+
+	// Checked once both values are final, whether they came from the ConfigMap or the defaults
+	if config.DrainTimeoutSeconds < config.GracePeriodSeconds {
+		field, value := "drainTimeoutSeconds", configMap.Data["drainTimeoutSeconds"]
+		if _, exists := configMap.Data["drainTimeoutSeconds"]; !exists {
+			field, value = "gracePeriodSeconds", configMap.Data["gracePeriodSeconds"]
+		}
+		return nil, newConstraintError(field, value, fmt.Sprintf("drainTimeoutSeconds (%d) must be greater than gracePeriodSeconds (%d)",
+			config.DrainTimeoutSeconds, config.GracePeriodSeconds))
+	}
+
 	return config, nil
 }
 
+// systemNamespaces lists the namespaces skipped by excludeSystemNamespaces, including the
+// controller's own namespace when it is known
+func systemNamespaces(controllerNamespace string) []string {
+	namespaces := []string{"kube-system", "kube-public", "kube-node-lease"}
+	if controllerNamespace != "" {
+		namespaces = append(namespaces, controllerNamespace)
+	}
+	return namespaces
+}
+
+// parseOwnerKindOverrides parses the ownerKindOverrides JSON, filling unset values from
+// the already-parsed global config and applying the same bounds as the global keys
+func parseOwnerKindOverrides(value string, config *Config, bounds ConfigBounds) (map[string]OwnerKindOverride, error) {
+	var overrides map[string]OwnerKindOverride
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		return nil, newParseError("ownerKindOverrides", value, err)
+	}
+
+	for kind, override := range overrides {
+		gracePeriod := config.GracePeriodSeconds
+		if override.GracePeriodSeconds != nil {
+			gracePeriod = *override.GracePeriodSeconds
+		}
+		drainTimeout := config.DrainTimeoutSeconds
+		if override.DrainTimeoutSeconds != nil {
+			drainTimeout = *override.DrainTimeoutSeconds
+		}
+
+		if gracePeriod < 0 || gracePeriod > bounds.MaxGracePeriodSeconds {
+			return nil, newConstraintError("ownerKindOverrides", value,
+				fmt.Sprintf("%s gracePeriodSeconds must be between 0 and %d, got: %d", kind, bounds.MaxGracePeriodSeconds, gracePeriod))
+		}
+		if drainTimeout <= 0 || drainTimeout > bounds.MaxDrainTimeoutSeconds {
+			return nil, newConstraintError("ownerKindOverrides", value,
+				fmt.Sprintf("%s drainTimeoutSeconds must be between 1 and %d, got: %d", kind, bounds.MaxDrainTimeoutSeconds, drainTimeout))
+		}
+		if drainTimeout < gracePeriod {
+			return nil, newConstraintError("ownerKindOverrides", value,
+				fmt.Sprintf("%s drainTimeoutSeconds (%d) must be greater than gracePeriodSeconds (%d)", kind, drainTimeout, gracePeriod))
+		}
+
+		overrides[kind] = OwnerKindOverride{
+			GracePeriodSeconds:  &gracePeriod,
+			DrainTimeoutSeconds: &drainTimeout,
+		}
+	}
+
+	return overrides, nil
+}
+
+// parseBoolField sets target from data[key] when the key is present
+func parseBoolField(data map[string]string, key string, target *bool) error {
+	valueStr, exists := data[key]
+	if !exists {
+		return nil
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return newParseError(key, valueStr, err)
+	}
+	*target = value
+	return nil
+}
+
+// compileManagedExpression compiles a CEL expression that receives the pod as the `pod` variable
+func compileManagedExpression(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Variable("pod", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if outputType := ast.OutputType(); outputType != cel.BoolType && outputType != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to bool, got: %v", outputType)
+	}
+
+	return env.Program(ast)
+}
+
+// HasManagedExpression reports whether a compiled managedExpression is configured
+func (c *Config) HasManagedExpression() bool {
+	return c.managedProgram != nil
+}
+
+// EvaluateManagedExpression runs the configured managedExpression against the pod
+func (c *Config) EvaluateManagedExpression(pod *corev1.Pod) (bool, error) {
+	if c.managedProgram == nil {
+		return false, fmt.Errorf("no managedExpression configured")
+	}
+
+	podObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return false, err
+	}
+
+	result, _, err := c.managedProgram.Eval(map[string]interface{}{"pod": podObject})
+	if err != nil {
+		return false, err
+	}
+
+	managed, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("managedExpression returned non-bool value: %v", result.Value())
+	}
+	return managed, nil
+}
+
+// namespaceOverridableKeys are the per-workload timing and selection keys a namespace
+// ConfigMap may override. Everything else is controller-wide and only read globally.
+var namespaceOverridableKeys = map[string]bool{
+	"gracePeriodSeconds":           true,
+	"drainTimeoutSeconds":          true,
+	"minimumServingSeconds":        true,
+	"endpointSettleSeconds":        true,
+	"postDeregistrationSeconds":    true,
+	"knativeSettleSeconds":         true,
+	"nodeCordonGraceSeconds":       true,
+	"fastDrainOnNodeCordon":        true,
+	"deschedulerGraceSeconds":      true,
+	"fastDrainOnDescheduler":       true,
+	"ageScaledGrace":               true,
+	"ageScaledGraceMinSeconds":     true,
+	"ageScaledGraceFullAgeSeconds": true,
+	"timeoutExtensionSeconds":      true,
+	"maxTimeoutExtensions":         true,
+	"respectDeletionGracePeriod":   true,
+	"slowDrainThreshold":           true,
+	"ownerKindOverrides":           true,
+	"managedExpression":            true,
+	"manageIfAnnotations":          true,
+	"onlyManageEvictions":          true,
+	"manageDaemonSetPods":          true,
+	"manageJobPods":                true,
+	"disableResourceHeuristic":     true,
+	"excludePodNames":              true,
+}
+
+// filterNamespaceOverrides returns a copy of the namespace ConfigMap holding only the
+// overridable keys, along with the sorted keys that were dropped
+func filterNamespaceOverrides(configMap *corev1.ConfigMap) (*corev1.ConfigMap, []string) {
+	if configMap == nil {
+		return nil, nil
+	}
+
+	filtered := &corev1.ConfigMap{
+		ObjectMeta: *configMap.ObjectMeta.DeepCopy(),
+		Data:       map[string]string{},
+	}
+	var ignored []string
+	for key, value := range configMap.Data {
+		if !namespaceOverridableKeys[key] {
+			ignored = append(ignored, key)
+			continue
+		}
+		filtered.Data[key] = value
+	}
+	slices.Sort(ignored)
+
+	return filtered, ignored
+}
+
+// mergeConfigMaps overlays the override ConfigMap's keys on top of the base ConfigMap.
+// Either argument may be nil; nil is returned only when both are.
+func mergeConfigMaps(base, override *corev1.ConfigMap) *corev1.ConfigMap {
+	if base == nil && override == nil {
+		return nil
+	}
+
+	merged := &corev1.ConfigMap{Data: map[string]string{}}
+	if base != nil {
+		merged.ObjectMeta = *base.ObjectMeta.DeepCopy()
+		for key, value := range base.Data {
+			merged.Data[key] = value
+		}
+	}
+	if override != nil {
+		if base == nil {
+			merged.ObjectMeta = *override.ObjectMeta.DeepCopy()
+		}
+		for key, value := range override.Data {
+			merged.Data[key] = value
+		}
+	}
+
+	return merged
+}
+
 func (c *Config) GetGracePeriod() time.Duration {
 	return time.Duration(c.GracePeriodSeconds) * time.Second
 }
@@ -113,3 +1206,209 @@ func (c *Config) GetGracePeriod() time.Duration {
 func (c *Config) GetDrainTimeout() time.Duration {
 	return time.Duration(c.DrainTimeoutSeconds) * time.Second
 }
+
+func (c *Config) GetHardDeadlineBuffer() time.Duration {
+	return time.Duration(c.HardDeadlineBufferSeconds) * time.Second
+}
+
+func (c *Config) GetAPICallTimeout() time.Duration {
+	return time.Duration(c.APICallTimeoutSeconds) * time.Second
+}
+
+// GetConnectionPollInterval is the requeue interval once the grace period is over
+// and the drain is waiting for connections to close
+func (c *Config) GetConnectionPollInterval() time.Duration {
+	return time.Duration(c.ConnectionPollIntervalSeconds) * time.Second
+}
+
+// GetMaxPollInterval caps the requeue interval of a pod whose connections keep holding
+// its drain, which backs off exponentially from the connection poll interval
+func (c *Config) GetMaxPollInterval() time.Duration {
+	return time.Duration(c.MaxPollIntervalSeconds) * time.Second
+}
+
+// GetWaitingLogInterval is how often the "still waiting" line is logged per draining pod
+// while its drain phase is unchanged; 0 logs it on every reconcile
+func (c *Config) GetWaitingLogInterval() time.Duration {
+	return time.Duration(c.WaitingLogIntervalSeconds) * time.Second
+}
+
+// GetEndpointSettle is how long after turning Ready a pod matching a service is assumed
+// to be about to appear in its endpoints
+func (c *Config) GetEndpointSettle() time.Duration {
+	return time.Duration(c.EndpointSettleSeconds) * time.Second
+}
+
+func (c *Config) GetOnlyManageEvictions() bool {
+	return c.OnlyManageEvictions
+}
+
+func (c *Config) GetTCPPortsOnly() bool {
+	return c.TCPPortsOnly
+}
+
+func (c *Config) GetConnectionCheckMode() string {
+	return c.ConnectionCheckMode
+}
+
+func (c *Config) GetTreatMissingReadyAsReady() bool {
+	return c.TreatMissingReadyAsReady
+}
+
+func (c *Config) GetOwnerKindOverrides() map[string]finalizer.DrainWindow {
+	if len(c.OwnerKindOverrides) == 0 {
+		return nil
+	}
+
+	windows := make(map[string]finalizer.DrainWindow, len(c.OwnerKindOverrides))
+	for kind, override := range c.OwnerKindOverrides {
+		window := finalizer.DrainWindow{
+			GracePeriod:  c.GetGracePeriod(),
+			DrainTimeout: c.GetDrainTimeout(),
+		}
+		if override.GracePeriodSeconds != nil {
+			window.GracePeriod = time.Duration(*override.GracePeriodSeconds) * time.Second
+		}
+		if override.DrainTimeoutSeconds != nil {
+			window.DrainTimeout = time.Duration(*override.DrainTimeoutSeconds) * time.Second
+		}
+		windows[kind] = window
+	}
+	return windows
+}
+
+func (c *Config) GetRespectDeletionGracePeriod() bool {
+	return c.RespectDeletionGracePeriod
+}
+
+func (c *Config) GetTrafficWeightThreshold() float64 {
+	return c.TrafficWeightThreshold
+}
+
+func (c *Config) GetOnTimeoutWithConnections() string {
+	return c.OnTimeoutWithConnections
+}
+
+func (c *Config) GetOnConnectionCheckError() string {
+	return c.OnConnectionCheckError
+}
+
+func (c *Config) GetTimeoutExtension() time.Duration {
+	return time.Duration(c.TimeoutExtensionSeconds) * time.Second
+}
+
+func (c *Config) GetMaxTimeoutExtensions() int {
+	return c.MaxTimeoutExtensions
+}
+
+func (c *Config) GetFastDrainOnNodeCordon() bool {
+	return c.FastDrainOnNodeCordon
+}
+
+// GetNodeCordonGrace is the grace period applied to pods on a cordoned node when
+// fastDrainOnNodeCordon is enabled
+func (c *Config) GetNodeCordonGrace() time.Duration {
+	return time.Duration(c.NodeCordonGraceSeconds) * time.Second
+}
+
+func (c *Config) GetFastDrainOnDescheduler() bool {
+	return c.FastDrainOnDescheduler
+}
+
+// GetDeschedulerGrace is the grace period applied to pods evicted by the Descheduler when
+// fastDrainOnDescheduler is enabled
+func (c *Config) GetDeschedulerGrace() time.Duration {
+	return time.Duration(c.DeschedulerGraceSeconds) * time.Second
+}
+
+// GetConsiderHostPort reports whether pods publishing a hostPort drain on the grace period
+// alone, since endpoints don't reflect traffic sent to the node IP
+func (c *Config) GetConsiderHostPort() bool {
+	return c.ConsiderHostPort
+}
+
+// GetCrossNamespaceEndpointCheck reports whether endpoints outside the pod's namespace are
+// searched for the pod as well
+func (c *Config) GetCrossNamespaceEndpointCheck() bool {
+	return c.CrossNamespaceEndpointCheck
+}
+
+// GetCrossNamespaceEndpoints lists the namespaces searched by the cross-namespace endpoint
+// check; empty means all namespaces
+func (c *Config) GetCrossNamespaceEndpoints() []string {
+	return c.CrossNamespaceEndpoints
+}
+
+// GetServingPhases lists the pod phases in which a pod may still have active connections
+func (c *Config) GetServingPhases() []string {
+	return c.ServingPhases
+}
+
+func (c *Config) GetUseEndpointSlices() bool {
+	return c.UseEndpointSlices
+}
+
+// GetWaitForVolumeDetach reports whether drains wait for the pod's persistent volumes to
+// leave its node's volumesInUse
+func (c *Config) GetWaitForVolumeDetach() bool {
+	return c.WaitForVolumeDetach
+}
+
+// GetEndpointCheckConcurrency is how many services' endpoints are checked at once for a
+// pod; 1 checks them one after another
+func (c *Config) GetEndpointCheckConcurrency() int {
+	return c.EndpointCheckConcurrency
+}
+
+func (c *Config) GetAgeScaledGrace() bool {
+	return c.AgeScaledGrace
+}
+
+// GetAgeScaledGraceMin is the grace period of a pod deleted right after it started
+func (c *Config) GetAgeScaledGraceMin() time.Duration {
+	return time.Duration(c.AgeScaledGraceMinSeconds) * time.Second
+}
+
+// GetAgeScaledGraceFullAge is the pod age from which the full grace period applies
+func (c *Config) GetAgeScaledGraceFullAge() time.Duration {
+	return time.Duration(c.AgeScaledGraceFullAgeSeconds) * time.Second
+}
+
+// GetMinimumServing is how long a pod must have been Ready before its drain may complete
+func (c *Config) GetMinimumServing() time.Duration {
+	return time.Duration(c.MinimumServingSeconds) * time.Second
+}
+
+// GetKnativeAware reports whether Knative pods drain only once they are out of every
+// Endpoints object and the knative settle window has passed
+func (c *Config) GetKnativeAware() bool {
+	return c.KnativeAware
+}
+
+// GetKnativeSettle is how long a Knative pod must stay out of all endpoints before its
+// drain may complete
+func (c *Config) GetKnativeSettle() time.Duration {
+	return time.Duration(c.KnativeSettleSeconds) * time.Second
+}
+
+// GetPostDeregistration is how long after leaving all service endpoints a pod's drain
+// completes; 0 disables the post-deregistration timer
+func (c *Config) GetPostDeregistration() time.Duration {
+	return time.Duration(c.PostDeregistrationSeconds) * time.Second
+}
+
+// GetActiveTrafficAnnotations lists the pod annotations that, when set to a true value,
+// mark the pod as serving traffic regardless of its endpoints
+func (c *Config) GetActiveTrafficAnnotations() []string {
+	return c.ActiveTrafficAnnotations
+}
+
+// GetTrafficContainers lists the containers whose ports count as traffic ports; empty means all
+func (c *Config) GetTrafficContainers() []string {
+	return c.TrafficContainers
+}
+
+// DrainGateGVK is the kind of the custom resource that gates drains, if drainGateKind is set
+func (c *Config) DrainGateGVK() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(c.DrainGateAPIVersion, c.DrainGateKind)
+}
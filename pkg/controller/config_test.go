@@ -1,20 +1,17 @@
 package controller
 
 import (
-	"testing"
+	"errors"
+	"strconv"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-func TestConfig(t *testing.T) {
-	RegisterFailHandler(Fail)
-	RunSpecs(t, "Config Suite")
-}
-
 var _ = Describe("Config", func() {
 	Describe("NewDefaultConfig", func() {
 		It("should create config with default values", func() {
@@ -22,80 +19,1236 @@ var _ = Describe("Config", func() {
 			
 			Expect(config.GetGracePeriod()).To(Equal(30 * time.Second))
 			Expect(config.GetDrainTimeout()).To(Equal(300 * time.Second))
+			Expect(config.GetHardDeadlineBuffer()).To(Equal(60 * time.Second))
+			Expect(config.GetTCPPortsOnly()).To(BeTrue())
+			Expect(config.GetAPICallTimeout()).To(Equal(5 * time.Second))
+			Expect(config.GetConnectionPollInterval()).To(Equal(10 * time.Second))
+			Expect(config.GetOnTimeoutWithConnections()).To(Equal("force-complete"))
+			Expect(config.GetOnConnectionCheckError()).To(Equal("retry"))
 			Expect(config.NamespaceSelector).To(BeNil())
 		})
+
+		It("should apply default config options", func() {
+			config := NewDefaultConfig(WithDefaultGracePeriod(45), WithDefaultDrainTimeout(600))
+
+			Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+			Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+			Expect(config.GetHardDeadlineBuffer()).To(Equal(60 * time.Second))
+		})
+
+		It("should replace the defaults as a whole", func() {
+			config := NewDefaultConfig(WithDefaults(DefaultConfigOptions{GracePeriodSeconds: 0, DrainTimeoutSeconds: 120}))
+
+			Expect(config.GetGracePeriod()).To(BeZero())
+			Expect(config.GetDrainTimeout()).To(Equal(120 * time.Second))
+		})
 	})
 
-	Describe("ParseConfig", func() {
-		Context("when ConfigMap is empty", func() {
-			It("should return default config", func() {
+	Describe("ParseConfig", func() {
+		Context("when ConfigMap is empty", func() {
+			It("should return default config", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(30 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(300 * time.Second))
+				Expect(config.NamespaceSelector).To(BeNil())
+			})
+		})
+
+		Context("when ConfigMap has valid values", func() {
+			It("should parse gracePeriodSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds": "60",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(60 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(300 * time.Second))
+			})
+
+			It("should parse drainTimeoutSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"drainTimeoutSeconds": "600",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(30 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+			})
+
+			It("should parse both values correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds":  "45",
+						"drainTimeoutSeconds": "900",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(900 * time.Second))
+			})
+
+			It("should parse hardDeadlineBufferSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"hardDeadlineBufferSeconds": "120",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetHardDeadlineBuffer()).To(Equal(120 * time.Second))
+			})
+
+			It("should parse manageDaemonSetPods correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"manageDaemonSetPods": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.ManageDaemonSetPods).To(BeTrue())
+			})
+
+			It("should parse onlyManageEvictions correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"onlyManageEvictions": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetOnlyManageEvictions()).To(BeTrue())
+			})
+
+			It("should parse tcpPortsOnly correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"tcpPortsOnly": "false",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetTCPPortsOnly()).To(BeFalse())
+			})
+
+			It("should parse conntrack connection check mode correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"connectionCheckMode": "conntrack",
+						"connTrackerEndpoint": "http://{nodeName}:9095/connections",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetConnectionCheckMode()).To(Equal("conntrack"))
+				Expect(config.ConnTrackerEndpoint).To(Equal("http://{nodeName}:9095/connections"))
+			})
+
+			It("should return error for conntrack mode without an endpoint", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"connectionCheckMode": "conntrack",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("connTrackerEndpoint is required"))
+			})
+
+			It("should parse grpc-health connection check mode correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"connectionCheckMode": "grpc-health",
+						"grpcHealthPort":      "9090",
+						"grpcHealthService":   "orders.v1.Orders",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetConnectionCheckMode()).To(Equal("grpc-health"))
+				Expect(config.GRPCHealthPort).To(Equal(int32(9090)))
+				Expect(config.GRPCHealthService).To(Equal("orders.v1.Orders"))
+			})
+
+			It("should return error for grpc-health mode without a port", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"connectionCheckMode": "grpc-health",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("grpcHealthPort is required"))
+			})
+
+			It("should return error for an out of range grpcHealthPort", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"grpcHealthPort": "70000",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("grpcHealthPort must be between 1 and 65535"))
+			})
+
+			It("should return error for unknown connectionCheckMode", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"connectionCheckMode": "netstat",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("connectionCheckMode must be"))
+			})
+
+			It("should parse treatMissingReadyAsReady correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"treatMissingReadyAsReady": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetTreatMissingReadyAsReady()).To(BeTrue())
+			})
+
+			It("should parse ownerKindOverrides and inherit unset values", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"drainTimeoutSeconds": "600",
+						"ownerKindOverrides":  `{"StatefulSet": {"gracePeriodSeconds": 120, "drainTimeoutSeconds": 1200}, "Job": {"gracePeriodSeconds": 0}}`,
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				windows := config.GetOwnerKindOverrides()
+				Expect(windows).To(HaveLen(2))
+				Expect(windows["StatefulSet"].GracePeriod).To(Equal(120 * time.Second))
+				Expect(windows["StatefulSet"].DrainTimeout).To(Equal(1200 * time.Second))
+				Expect(windows["Job"].GracePeriod).To(Equal(time.Duration(0)))
+				Expect(windows["Job"].DrainTimeout).To(Equal(600 * time.Second))
+			})
+
+			It("should return error when an override timeout is shorter than its grace period", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"ownerKindOverrides": `{"StatefulSet": {"gracePeriodSeconds": 600, "drainTimeoutSeconds": 120}}`,
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("StatefulSet drainTimeoutSeconds (120) must be greater than gracePeriodSeconds (600)"))
+			})
+
+			It("should return error for invalid ownerKindOverrides JSON", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"ownerKindOverrides": `{"StatefulSet": 120}`,
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid ownerKindOverrides"))
+			})
+
+			It("should parse apiCallTimeoutSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"apiCallTimeoutSeconds": "10",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetAPICallTimeout()).To(Equal(10 * time.Second))
+			})
+
+			It("should return error for non-positive apiCallTimeoutSeconds", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"apiCallTimeoutSeconds": "0",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("apiCallTimeoutSeconds must be positive"))
+			})
+
+			It("should parse connectionPollIntervalSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"connectionPollIntervalSeconds": "3",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetConnectionPollInterval()).To(Equal(3 * time.Second))
+			})
+
+			It("should return error for out-of-range connectionPollIntervalSeconds", func() {
+				for _, value := range []string{"0", "61"} {
+					configMap := &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "test-config",
+							Namespace: "test-namespace",
+						},
+						Data: map[string]string{
+							"connectionPollIntervalSeconds": value,
+						},
+					}
+
+					_, err := ParseConfig(configMap)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("connectionPollIntervalSeconds must be"))
+				}
+			})
+
+			It("should parse endpointSettleSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"endpointSettleSeconds": "0",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetEndpointSettle()).To(BeZero())
+			})
+
+			It("should parse waitingLogIntervalSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"waitingLogIntervalSeconds": "120",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetWaitingLogInterval()).To(Equal(2 * time.Minute))
+
+				configMap.Data["waitingLogIntervalSeconds"] = "-1"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse trafficWeightThreshold correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"trafficWeightThreshold": "0.05",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetTrafficWeightThreshold()).To(Equal(0.05))
+			})
+
+			It("should return error for trafficWeightThreshold above 1", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"trafficWeightThreshold": "1.5",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("trafficWeightThreshold must be between 0 and 1"))
+			})
+
+			It("should parse the timeout extension settings correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"onTimeoutWithConnections": "extend",
+						"timeoutExtensionSeconds":  "120",
+						"maxTimeoutExtensions":     "3",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetOnTimeoutWithConnections()).To(Equal("extend"))
+				Expect(config.GetTimeoutExtension()).To(Equal(120 * time.Second))
+				Expect(config.GetMaxTimeoutExtensions()).To(Equal(3))
+			})
+
+			It("should return error for an unknown onTimeoutWithConnections", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"onTimeoutWithConnections": "wait-forever",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("onTimeoutWithConnections must be"))
+			})
+
+			It("should parse onConnectionCheckError correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"onConnectionCheckError": "assume-drained",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetOnConnectionCheckError()).To(Equal("assume-drained"))
+			})
+
+			It("should return error for an unknown onConnectionCheckError", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"onConnectionCheckError": "ignore",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("onConnectionCheckError must be"))
+			})
+
+			It("should parse respectDeletionGracePeriod correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"respectDeletionGracePeriod": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetRespectDeletionGracePeriod()).To(BeTrue())
+			})
+
+			It("should parse manageIfAnnotations correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"manageIfAnnotations": `["sidecar.istio.io/status", "linkerd.io/proxy-version"]`,
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.ManageIfAnnotations).To(Equal([]string{"sidecar.istio.io/status", "linkerd.io/proxy-version"}))
+			})
+
+			It("should return error for a malformed manageIfAnnotations", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"manageIfAnnotations": "sidecar.istio.io/status",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid manageIfAnnotations"))
+			})
+
+			It("should parse disableResourceHeuristic correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"disableResourceHeuristic": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.DisableResourceHeuristic).To(BeTrue())
+			})
+
+			It("should parse resource heuristic thresholds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"heuristicCpuModulos":           "[25]",
+						"heuristicMemoryAlignmentBytes": "1000000",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.HeuristicCPUModulos).To(Equal([]int64{25}))
+				Expect(config.HeuristicMemoryAlignmentBytes).To(Equal(int64(1000000)))
+
+				configMap.Data["heuristicCpuModulos"] = "[0]"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse manageJobPods correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"manageJobPods": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.ManageJobPods).To(BeTrue())
+			})
+
+			It("should parse node cordon settings correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"fastDrainOnNodeCordon":  "true",
+						"nodeCordonGraceSeconds": "10",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetFastDrainOnNodeCordon()).To(BeTrue())
+				Expect(config.GetNodeCordonGrace()).To(Equal(10 * time.Second))
+
+				configMap.Data["nodeCordonGraceSeconds"] = "-5"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse the age-scaled grace settings correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"ageScaledGrace":               "true",
+						"ageScaledGraceMinSeconds":     "10",
+						"ageScaledGraceFullAgeSeconds": "7200",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetAgeScaledGrace()).To(BeTrue())
+				Expect(config.GetAgeScaledGraceMin()).To(Equal(10 * time.Second))
+				Expect(config.GetAgeScaledGraceFullAge()).To(Equal(2 * time.Hour))
+			})
+
+			It("should parse slowDrainThreshold correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"slowDrainThreshold": "0.5",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.SlowDrainThreshold).To(Equal(0.5))
+			})
+
+			It("should return error for a slowDrainThreshold above 1", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"slowDrainThreshold": "1.5",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse the descheduler settings correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"fastDrainOnDescheduler":  "true",
+						"deschedulerGraceSeconds": "10",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetFastDrainOnDescheduler()).To(BeTrue())
+				Expect(config.GetDeschedulerGrace()).To(Equal(10 * time.Second))
+			})
+
+			It("should return error for a deschedulerGraceSeconds above 300", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"deschedulerGraceSeconds": "301",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse excludePodNames correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"excludePodNames": `["prometheus-*", "vault-[0-2]"]`,
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.ExcludePodNames).To(Equal([]string{"prometheus-*", "vault-[0-2]"}))
+				Expect(config.ExcludesPodName("prometheus-0")).To(BeTrue())
+				Expect(config.ExcludesPodName("vault-1")).To(BeTrue())
+				Expect(config.ExcludesPodName("vault-3")).To(BeFalse())
+			})
+
+			It("should return error for an invalid excludePodNames pattern", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"excludePodNames": `["prometheus-[0"]`,
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("excludePodNames"))
+			})
+
+			It("should parse endpointCheckConcurrency correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"endpointCheckConcurrency": "8",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetEndpointCheckConcurrency()).To(Equal(8))
+			})
+
+			It("should return error for an endpointCheckConcurrency below 1", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"endpointCheckConcurrency": "0",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse waitForVolumeDetach correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"waitForVolumeDetach": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetWaitForVolumeDetach()).To(BeTrue())
+			})
+
+			It("should parse useEndpointSlices correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"useEndpointSlices": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetUseEndpointSlices()).To(BeTrue())
+			})
+
+			It("should return error for a non-positive ageScaledGraceFullAgeSeconds", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"ageScaledGraceFullAgeSeconds": "0",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("ageScaledGraceFullAgeSeconds must be positive"))
+			})
+
+			It("should parse minimumServingSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"minimumServingSeconds": "120",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetMinimumServing()).To(Equal(2 * time.Minute))
+
+				configMap.Data["minimumServingSeconds"] = "-1"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse postDeregistrationSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"postDeregistrationSeconds": "15",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetPostDeregistration()).To(Equal(15 * time.Second))
+
+				configMap.Data["postDeregistrationSeconds"] = "3601"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse finalizerUpdateStrategy correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"finalizerUpdateStrategy": "patch",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.FinalizerUpdateStrategy).To(Equal(FinalizerUpdateStrategyPatch))
+				Expect(NewDefaultConfig().FinalizerUpdateStrategy).To(Equal(FinalizerUpdateStrategyUpdate))
+
+				configMap.Data["finalizerUpdateStrategy"] = "apply"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse the drain gate resource correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"drainGateAPIVersion": "example.com/v1alpha1",
+						"drainGateKind":       "TrafficPolicy",
+						"drainGateFieldPath":  "{.status.drained}",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.DrainGateGVK()).To(Equal(schema.GroupVersionKind{
+					Group: "example.com", Version: "v1alpha1", Kind: "TrafficPolicy",
+				}))
+
+				delete(configMap.Data, "drainGateAPIVersion")
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse activeTrafficAnnotations correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"activeTrafficAnnotations": `["traffic.mycompany.io/active"]`,
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetActiveTrafficAnnotations()).To(Equal([]string{"traffic.mycompany.io/active"}))
+
+				configMap.Data["activeTrafficAnnotations"] = "traffic.mycompany.io/active"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse considerHostPort correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"considerHostPort": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetConsiderHostPort()).To(BeTrue())
+			})
+
+			It("should parse the audit ConfigMap settings correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"auditConfigMapName": "drain-audit",
+						"auditMaxEntries":    "500",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.AuditConfigMapName).To(Equal("drain-audit"))
+				Expect(config.AuditMaxEntries).To(Equal(500))
+
+				configMap.Data["auditMaxEntries"] = "0"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+
+				configMap.Data["auditMaxEntries"] = "10"
+				configMap.Data["auditConfigMapName"] = "Drain_Audit"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse the cross-namespace endpoint check correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"crossNamespaceEndpointCheck":      "true",
+						"crossNamespaceEndpointNamespaces": `["istio-system", "mesh-exports"]`,
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetCrossNamespaceEndpointCheck()).To(BeTrue())
+				Expect(config.GetCrossNamespaceEndpoints()).To(Equal([]string{"istio-system", "mesh-exports"}))
+
+				configMap.Data["crossNamespaceEndpointNamespaces"] = `["Mesh_Exports"]`
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse servingPhases correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"servingPhases": `["Running", "Pending"]`,
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetServingPhases()).To(Equal([]string{"Running", "Pending"}))
+
+				configMap.Data["servingPhases"] = `["running"]`
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+
+				configMap.Data["servingPhases"] = `[]`
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse awsTargetGroupCheck correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"awsTargetGroupCheck": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.AWSTargetGroupCheck).To(BeTrue())
+			})
+
+			It("should parse maxPollIntervalSeconds correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"maxPollIntervalSeconds": "120",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetMaxPollInterval()).To(Equal(2 * time.Minute))
+
+				configMap.Data["connectionPollIntervalSeconds"] = "30"
+				configMap.Data["maxPollIntervalSeconds"] = "20"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+
+				configMap.Data["maxPollIntervalSeconds"] = "601"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse the knative settings correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"knativeAware":         "true",
+						"knativeSettleSeconds": "45",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetKnativeAware()).To(BeTrue())
+				Expect(config.GetKnativeSettle()).To(Equal(45 * time.Second))
+
+				configMap.Data["knativeSettleSeconds"] = "-1"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+
+				configMap.Data["knativeSettleSeconds"] = "601"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse preventLastReplicaDrain correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"preventLastReplicaDrain": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.PreventLastReplicaDrain).To(BeTrue())
+
+				configMap.Data["preventLastReplicaDrain"] = "maybe"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse trafficContainers correctly", func() {
 				configMap := &corev1.ConfigMap{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-config",
 						Namespace: "test-namespace",
 					},
-					Data: map[string]string{},
+					Data: map[string]string{
+						"trafficContainers": `["app"]`,
+					},
 				}
 
 				config, err := ParseConfig(configMap)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(config.GetGracePeriod()).To(Equal(30 * time.Second))
-				Expect(config.GetDrainTimeout()).To(Equal(300 * time.Second))
-				Expect(config.NamespaceSelector).To(BeNil())
+				Expect(config.GetTrafficContainers()).To(Equal([]string{"app"}))
+
+				configMap.Data["trafficContainers"] = "app"
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
 			})
-		})
 
-		Context("when ConfigMap has valid values", func() {
-			It("should parse gracePeriodSeconds correctly", func() {
+			It("should reject annotation keys that aren't qualified names", func() {
 				configMap := &corev1.ConfigMap{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-config",
 						Namespace: "test-namespace",
 					},
 					Data: map[string]string{
-						"gracePeriodSeconds": "60",
+						"manageIfAnnotations": `["sidecar.istio.io/status", "not a key"]`,
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				var validationErr *ConfigValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("manageIfAnnotations"))
+				Expect(err.Error()).To(ContainSubstring(`"not a key"`))
+
+				delete(configMap.Data, "manageIfAnnotations")
+				configMap.Data["activeTrafficAnnotations"] = `["-bad-/active"]`
+				_, err = ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should parse enabled correctly", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"enabled": "false",
 					},
 				}
 
 				config, err := ParseConfig(configMap)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(config.GetGracePeriod()).To(Equal(60 * time.Second))
-				Expect(config.GetDrainTimeout()).To(Equal(300 * time.Second))
+				Expect(config.Enabled).To(BeFalse())
+				Expect(NewDefaultConfig().Enabled).To(BeTrue())
 			})
 
-			It("should parse drainTimeoutSeconds correctly", func() {
+			It("should parse blockNamespaceTermination correctly", func() {
 				configMap := &corev1.ConfigMap{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-config",
 						Namespace: "test-namespace",
 					},
 					Data: map[string]string{
-						"drainTimeoutSeconds": "600",
+						"blockNamespaceTermination": "true",
 					},
 				}
 
 				config, err := ParseConfig(configMap)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(config.GetGracePeriod()).To(Equal(30 * time.Second))
-				Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+				Expect(config.BlockNamespaceTermination).To(BeTrue())
 			})
 
-			It("should parse both values correctly", func() {
+			It("should parse drainCompleteWebhookURL correctly", func() {
 				configMap := &corev1.ConfigMap{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-config",
 						Namespace: "test-namespace",
 					},
 					Data: map[string]string{
-						"gracePeriodSeconds":  "45",
-						"drainTimeoutSeconds": "900",
+						"drainCompleteWebhookURL": "https://traffic-manager.example.com/drained",
 					},
 				}
 
 				config, err := ParseConfig(configMap)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
-				Expect(config.GetDrainTimeout()).To(Equal(900 * time.Second))
+				Expect(config.DrainCompleteWebhookURL).To(Equal("https://traffic-manager.example.com/drained"))
 			})
 
 			It("should parse namespaceSelector correctly", func() {
@@ -172,6 +1325,78 @@ var _ = Describe("Config", func() {
 				Expect(err.Error()).To(ContainSubstring("namespaceSelector"))
 			})
 
+			It("should return error for invalid manageDaemonSetPods", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"manageDaemonSetPods": "sometimes",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid manageDaemonSetPods"))
+			})
+
+			It("should return error for non-http drainCompleteWebhookURL", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"drainCompleteWebhookURL": "ftp://example.com/drained",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("drainCompleteWebhookURL must use http or https"))
+			})
+
+			It("should return a ConfigValidationError identifying the field", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds": "-10",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+
+				var validationErr *ConfigValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("gracePeriodSeconds"))
+				Expect(validationErr.Value).To(Equal("-10"))
+				Expect(validationErr.Err).To(BeNil())
+			})
+
+			It("should wrap the parse error for unparsable values", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"drainTimeoutSeconds": "soon",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+
+				var validationErr *ConfigValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("drainTimeoutSeconds"))
+				Expect(errors.Is(err, strconv.ErrSyntax)).To(BeTrue())
+			})
+
 			It("should return error for negative gracePeriodSeconds", func() {
 				configMap := &corev1.ConfigMap{
 					ObjectMeta: metav1.ObjectMeta{
@@ -220,6 +1445,22 @@ var _ = Describe("Config", func() {
 				Expect(err.Error()).To(ContainSubstring("drainTimeoutSeconds must be positive"))
 			})
 
+			It("should return error for negative hardDeadlineBufferSeconds", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"hardDeadlineBufferSeconds": "-1",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("hardDeadlineBufferSeconds must be non-negative"))
+			})
+
 			It("should return error for gracePeriodSeconds exceeding maximum", func() {
 				configMap := &corev1.ConfigMap{
 					ObjectMeta: metav1.ObjectMeta{
@@ -268,6 +1509,76 @@ var _ = Describe("Config", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("drainTimeoutSeconds (30) must be greater than gracePeriodSeconds (60)"))
 			})
+
+			It("should return error when gracePeriod alone exceeds the default drainTimeout", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds": "600",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("gracePeriodSeconds"))
+				Expect(err.Error()).To(ContainSubstring("drainTimeoutSeconds (300) must be greater than gracePeriodSeconds (600)"))
+
+				_, err = ParseConfigWithBounds(configMap, DefaultConfigBounds(), WithDefaultDrainTimeout(900))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when ConfigMap has managedExpression", func() {
+			It("should compile a valid expression", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"managedExpression": "pod.metadata.annotations['team'] == 'payments' && size(pod.spec.containers) > 1",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.HasManagedExpression()).To(BeTrue())
+			})
+
+			It("should return error for invalid CEL syntax", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"managedExpression": "pod.metadata.annotations['team'] ==",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid managedExpression"))
+			})
+
+			It("should return error for non-bool expression", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"managedExpression": "size(pod.spec.containers)",
+					},
+				}
+
+				_, err := ParseConfig(configMap)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("must evaluate to bool"))
+			})
 		})
 
 		Context("when ConfigMap is nil", func() {
@@ -350,6 +1661,67 @@ var _ = Describe("Config", func() {
 			})
 		})
 
+		Context("when excludeSystemNamespaces is enabled", func() {
+			It("should skip system namespaces and the controller namespace", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"excludeSystemNamespaces": "true",
+					},
+				}
+
+				config, err := ParseConfigWithBounds(configMap, DefaultConfigBounds(), WithControllerNamespace("vpa-system"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.ExcludeSystemNamespaces).To(BeTrue())
+				Expect(config.IsSystemNamespace("kube-system")).To(BeTrue())
+				Expect(config.IsSystemNamespace("kube-public")).To(BeTrue())
+				Expect(config.IsSystemNamespace("kube-node-lease")).To(BeTrue())
+				Expect(config.IsSystemNamespace("vpa-system")).To(BeTrue())
+				Expect(config.IsSystemNamespace("default")).To(BeFalse())
+				Expect(config.IsSystemNamespace("production")).To(BeFalse())
+			})
+
+			It("should leave the namespace selector untouched", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"excludeSystemNamespaces": "true",
+						"namespaceSelector": `{
+							"include": ["kube-system", "production"]
+						}`,
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.NamespaceSelector.Exclude).To(BeEmpty())
+				Expect(config.IsSystemNamespace("kube-system")).To(BeTrue())
+				Expect(config.IsSystemNamespace("production")).To(BeFalse())
+			})
+
+			It("should skip only the well-known namespaces without a controller namespace", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"excludeSystemNamespaces": "true",
+					},
+				}
+
+				config, err := ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.SystemNamespaces).To(ConsistOf("kube-system", "kube-public", "kube-node-lease"))
+			})
+		})
+
 		Context("when empty arrays are specified", func() {
 			It("should handle empty include array", func() {
 				configMap := &corev1.ConfigMap{
@@ -391,6 +1763,60 @@ var _ = Describe("Config", func() {
 		})
 	})
 
+	Describe("mergeConfigMaps", func() {
+		It("should return nil when both ConfigMaps are nil", func() {
+			Expect(mergeConfigMaps(nil, nil)).To(BeNil())
+		})
+
+		It("should let override keys win while keeping the remaining base keys", func() {
+			base := &corev1.ConfigMap{
+				Data: map[string]string{
+					"gracePeriodSeconds":  "30",
+					"drainTimeoutSeconds": "300",
+				},
+			}
+			override := &corev1.ConfigMap{
+				Data: map[string]string{
+					"gracePeriodSeconds": "60",
+				},
+			}
+
+			merged := mergeConfigMaps(base, override)
+			Expect(merged.Data).To(Equal(map[string]string{
+				"gracePeriodSeconds":  "60",
+				"drainTimeoutSeconds": "300",
+			}))
+			Expect(base.Data["gracePeriodSeconds"]).To(Equal("30"))
+		})
+	})
+
+	Describe("filterNamespaceOverrides", func() {
+		It("should return nil for a missing ConfigMap", func() {
+			filtered, ignored := filterNamespaceOverrides(nil)
+			Expect(filtered).To(BeNil())
+			Expect(ignored).To(BeEmpty())
+		})
+
+		It("should keep only the keys a namespace may override", func() {
+			configMap := &corev1.ConfigMap{
+				Data: map[string]string{
+					"drainTimeoutSeconds": "600",
+					"managedExpression":   "true",
+					"enabled":             "false",
+					"connTrackerEndpoint": "http://tracker:8080",
+				},
+			}
+
+			filtered, ignored := filterNamespaceOverrides(configMap)
+			Expect(filtered.Data).To(Equal(map[string]string{
+				"drainTimeoutSeconds": "600",
+				"managedExpression":   "true",
+			}))
+			Expect(ignored).To(Equal([]string{"connTrackerEndpoint", "enabled"}))
+			Expect(configMap.Data).To(HaveLen(4))
+		})
+	})
+
 	Describe("Config struct methods", func() {
 		It("should implement Config interface correctly", func() {
 			config := &Config{
@@ -402,4 +1828,92 @@ var _ = Describe("Config", func() {
 			Expect(config.GetDrainTimeout()).To(Equal(900 * time.Second))
 		})
 	})
-})
\ No newline at end of file
+})
+
+var _ = Describe("ParseConfigWithBounds", func() {
+	var configMap *corev1.ConfigMap
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-config",
+				Namespace: "test-namespace",
+			},
+			Data: map[string]string{
+				"drainTimeoutSeconds": "10800",
+			},
+		}
+	})
+
+	It("should reject a 3-hour drain timeout with the default bounds", func() {
+		_, err := ParseConfig(configMap)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should accept a 3-hour drain timeout with raised bounds", func() {
+		bounds := ConfigBounds{MaxGracePeriodSeconds: 3600, MaxDrainTimeoutSeconds: 14400}
+
+		config, err := ParseConfigWithBounds(configMap, bounds)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.GetDrainTimeout()).To(Equal(3 * time.Hour))
+
+		configMap.Data["ownerKindOverrides"] = `{"Job": {"drainTimeoutSeconds": 14000}}`
+		_, err = ParseConfigWithBounds(configMap, bounds)
+		Expect(err).ToNot(HaveOccurred())
+
+		configMap.Data["ownerKindOverrides"] = `{"Job": {"drainTimeoutSeconds": 14401}}`
+		_, err = ParseConfigWithBounds(configMap, bounds)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fill unset keys from the default config options", func() {
+		configMap.Data = map[string]string{"drainTimeoutSeconds": "600"}
+
+		config, err := ParseConfigWithBounds(configMap, DefaultConfigBounds(), WithDefaultGracePeriod(45), WithDefaultDrainTimeout(900))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+		Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+	})
+
+	It("should apply a lowered grace period bound", func() {
+		configMap.Data = map[string]string{"gracePeriodSeconds": "120"}
+
+		_, err := ParseConfigWithBounds(configMap, ConfigBounds{MaxGracePeriodSeconds: 60, MaxDrainTimeoutSeconds: 7200})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ConfigBounds", func() {
+	It("should reject a non-positive drain timeout bound", func() {
+		Expect(DefaultConfigBounds().Validate()).To(Succeed())
+		Expect(ConfigBounds{MaxGracePeriodSeconds: 3600}.Validate()).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("DefaultConfigOptions", func() {
+	It("should validate the defaults against the bounds", func() {
+		Expect(NewDefaultConfigOptions().Validate(DefaultConfigBounds())).To(Succeed())
+		Expect(DefaultConfigOptions{GracePeriodSeconds: 45, DrainTimeoutSeconds: 600}.Validate(DefaultConfigBounds())).To(Succeed())
+		Expect(DefaultConfigOptions{GracePeriodSeconds: -1, DrainTimeoutSeconds: 300}.Validate(DefaultConfigBounds())).ToNot(Succeed())
+		Expect(DefaultConfigOptions{GracePeriodSeconds: 30, DrainTimeoutSeconds: 0}.Validate(DefaultConfigBounds())).ToNot(Succeed())
+		Expect(DefaultConfigOptions{GracePeriodSeconds: 30, DrainTimeoutSeconds: 10800}.Validate(DefaultConfigBounds())).ToNot(Succeed())
+	})
+
+	It("should reject a default grace period longer than the default drain timeout", func() {
+		err := DefaultConfigOptions{GracePeriodSeconds: 400, DrainTimeoutSeconds: DefaultDrainTimeoutSeconds}.Validate(DefaultConfigBounds())
+		Expect(err).To(MatchError(ContainSubstring("must be greater than the default grace period")))
+	})
+})
+
+var _ = Describe("ValidateFinalizerName", func() {
+	It("should accept the default and other qualified names", func() {
+		Expect(ValidateFinalizerName(VPAGracefulDrainFinalizer)).To(Succeed())
+		Expect(ValidateFinalizerName("example.com/drain-team-a")).To(Succeed())
+	})
+
+	It("should reject names containing spaces", func() {
+		err := ValidateFinalizerName("example.com/graceful drain")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid finalizer name"))
+	})
+})
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DrainClaimAnnotation records which replica is processing a draining pod, so replicas
+// running without leader election don't race on the same pod
+const DrainClaimAnnotation = "vpa-graceful-drain.cho.github.io/processing"
+
+// defaultDrainClaimLease is how long a claim keeps other replicas away without being renewed
+const defaultDrainClaimLease = time.Minute
+
+type drainClaim struct {
+	Holder    string    `json:"holder"`
+	RenewedAt time.Time `json:"renewedAt"`
+}
+
+func (r *PodReconciler) drainClaimLease() time.Duration {
+	if r.DrainClaimLease <= 0 {
+		return defaultDrainClaimLease
+	}
+	return r.DrainClaimLease
+}
+
+// claimDrain claims the pod for this replica, renewing our own claim once half the lease
+// has passed. It reports false with the time left on the claim when another replica holds
+// a live claim or wins the race for it. On success pod is updated to the claimed version.
+func (r *PodReconciler) claimDrain(ctx context.Context, pod *corev1.Pod) (bool, time.Duration, error) {
+	now := r.clock().Now()
+	lease := r.drainClaimLease()
+
+	var current drainClaim
+	if value, ok := pod.Annotations[DrainClaimAnnotation]; ok && json.Unmarshal([]byte(value), &current) == nil {
+		age := now.Sub(current.RenewedAt)
+		if current.Holder != r.ReplicaID && age < lease {
+			return false, lease - age, nil
+		}
+		if current.Holder == r.ReplicaID && age < lease/2 {
+			return true, 0, nil
+		}
+	}
+
+	value, err := json.Marshal(drainClaim{Holder: r.ReplicaID, RenewedAt: now})
+	if err != nil {
+		return false, 0, err
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = map[string]string{}
+	}
+	podCopy.Annotations[DrainClaimAnnotation] = string(value)
+
+	// The optimistic lock makes concurrent claims conflict, so only one replica wins
+	if err := r.Patch(ctx, podCopy, client.MergeFromWithOptions(pod, client.MergeFromWithOptimisticLock{})); err != nil {
+		if errors.IsConflict(err) {
+			log.FromContext(ctx).V(1).Info("Lost the race to claim the pod, leaving it to another replica", "pod", pod.Name)
+			return false, lease, nil
+		}
+		return false, 0, err
+	}
+
+	*pod = *podCopy
+	return true, 0, nil
+}
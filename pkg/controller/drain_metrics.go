@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
+)
+
+// recordDrainCompleted counts the completed drain under its reason and observes its
+// duration, labeled by owner kind and, when metricsNamespaceLabel is set, namespace.
+// It also appends the completion to the audit ConfigMap, if one is configured.
+func (r *PodReconciler) recordDrainCompleted(ctx context.Context, pod *corev1.Pod, config *Config, reason string) {
+	namespace := ""
+	if config.MetricsNamespaceLabel {
+		namespace = pod.Namespace
+	}
+	ownerKind := metrics.OwnerKindLabel(finalizer.ResolveOwnerKind(ctx, r.Client, pod))
+
+	metrics.CompletionReasonTotal.WithLabelValues(reason, namespace, ownerKind).Inc()
+	if pod.DeletionTimestamp != nil {
+		elapsed := r.clock().Now().Sub(pod.DeletionTimestamp.Time)
+		metrics.DrainDurationSeconds.WithLabelValues(namespace, ownerKind).Observe(elapsed.Seconds())
+	}
+
+	// Best effort: the finalizer is already gone, so a failed write can't hold the pod
+	if err := r.auditDrainCompletion(ctx, pod, config, reason); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to append drain completion to the audit ConfigMap",
+			"pod", pod.Name, "configMap", config.AuditConfigMapName)
+	}
+}
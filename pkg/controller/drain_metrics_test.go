@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
+)
+
+var _ = Describe("recordDrainCompleted", func() {
+	var (
+		ctx        context.Context
+		reconciler *PodReconciler
+		config     *Config
+		pod        *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+
+		isController := true
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-5d4f8",
+				Namespace: "team-a",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &isController},
+				},
+			},
+		}
+		deletedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		deletionTime := metav1.NewTime(deletedAt)
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "web-5d4f8-x7k2p",
+				Namespace:         "team-a",
+				DeletionTimestamp: &deletionTime,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: replicaSet.Name, Controller: &isController},
+				},
+			},
+		}
+
+		reconciler = &PodReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(replicaSet).Build(),
+			Clock:  fixedClock{now: deletedAt.Add(45 * time.Second)},
+		}
+		config = NewDefaultConfig()
+	})
+
+	It("should label a Deployment-owned pod with its namespace and owner kind", func() {
+		config.MetricsNamespaceLabel = true
+		completions := metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonNoConnections, "team-a", metrics.OwnerKindDeployment)
+		completionsBefore := testutil.ToFloat64(completions)
+
+		reconciler.recordDrainCompleted(ctx, pod, config, finalizer.CompletionReasonNoConnections)
+
+		Expect(testutil.ToFloat64(completions)).To(Equal(completionsBefore + 1))
+		Expect(testutil.CollectAndCount(metrics.DrainDurationSeconds)).To(BeNumerically(">=", 1))
+	})
+
+	It("should leave the namespace label empty unless enabled", func() {
+		completions := metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonTimeout, "", metrics.OwnerKindDeployment)
+		completionsBefore := testutil.ToFloat64(completions)
+
+		reconciler.recordDrainCompleted(ctx, pod, config, finalizer.CompletionReasonTimeout)
+
+		Expect(testutil.ToFloat64(completions)).To(Equal(completionsBefore + 1))
+	})
+})
+
+var _ = Describe("OwnerKindLabel", func() {
+	It("should keep known owner kinds and fold the rest into Other", func() {
+		Expect(metrics.OwnerKindLabel("StatefulSet")).To(Equal(metrics.OwnerKindStatefulSet))
+		Expect(metrics.OwnerKindLabel("Job")).To(Equal(metrics.OwnerKindJob))
+		Expect(metrics.OwnerKindLabel("ReplicaSet")).To(Equal(metrics.OwnerKindOther))
+		Expect(metrics.OwnerKindLabel("")).To(Equal(metrics.OwnerKindOther))
+	})
+})
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DrainPolicyGVR is the cluster-scoped DrainPolicy resource, see config/crd/drainpolicy.yaml
+var DrainPolicyGVR = schema.GroupVersionResource{
+	Group:    "vpa-graceful-drain.cho.github.io",
+	Version:  "v1alpha1",
+	Resource: "drainpolicies",
+}
+
+// drainPolicyIntFields are the integer DrainPolicy spec fields, named after the
+// ConfigMap keys they override
+var drainPolicyIntFields = []string{"gracePeriodSeconds", "drainTimeoutSeconds"}
+
+// drainPolicyStringFields are the string DrainPolicy spec fields, named after the
+// ConfigMap keys they override
+var drainPolicyStringFields = []string{"managedExpression"}
+
+// drainPolicyResync is how often the DrainPolicy informer replays its cache
+const drainPolicyResync = 10 * time.Minute
+
+// DrainPolicyResolver reads the DrainPolicy resources whose namespaceSelector matches a
+// namespace. Their settings take precedence over the ConfigMaps.
+type DrainPolicyResolver struct {
+	Client dynamic.Interface
+	// Recorder, if set, receives an InvalidDrainPolicy event for each skipped policy
+	Recorder record.EventRecorder
+
+	// lister serves the policies once Start has synced the informer; until then they
+	// are listed from the API server
+	lister atomic.Pointer[cache.GenericLister]
+	// unavailable is set when Start found the DrainPolicy CRD isn't installed
+	unavailable atomic.Bool
+	// invalid remembers the resource version of each policy last reported invalid
+	invalid sync.Map
+}
+
+// Start watches the DrainPolicies until ctx is done, so Resolve reads them from the
+// informer cache rather than listing them on every reconcile. Without the DrainPolicy
+// CRD it returns right away and Resolve finds no policies.
+func (p *DrainPolicyResolver) Start(ctx context.Context) error {
+	if _, err := p.Client.Resource(DrainPolicyGVR).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			log.FromContext(ctx).Info("DrainPolicy CRD is not installed, ignoring drain policies")
+			p.unavailable.Store(true)
+			return nil
+		}
+		return fmt.Errorf("failed to list drain policies: %w", err)
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(p.Client, drainPolicyResync)
+	informer := factory.ForResource(DrainPolicyGVR)
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+		return fmt.Errorf("drain policy cache did not sync")
+	}
+	lister := informer.Lister()
+	p.lister.Store(&lister)
+
+	<-ctx.Done()
+	factory.Shutdown()
+	return nil
+}
+
+// policies returns the DrainPolicies sorted by name
+func (p *DrainPolicyResolver) policies(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	var policies []*unstructured.Unstructured
+	if lister := p.lister.Load(); lister != nil {
+		objects, err := (*lister).List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list drain policies: %w", err)
+		}
+		for _, object := range objects {
+			if policy, ok := object.(*unstructured.Unstructured); ok {
+				policies = append(policies, policy)
+			}
+		}
+	} else {
+		list, err := p.Client.Resource(DrainPolicyGVR).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to list drain policies: %w", err)
+		}
+		for i := range list.Items {
+			policies = append(policies, &list.Items[i])
+		}
+	}
+
+	slices.SortFunc(policies, func(a, b *unstructured.Unstructured) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+	return policies, nil
+}
+
+// Resolve returns the settings of the DrainPolicies matching the namespace as ConfigMap
+// data, for merging over the ConfigMaps. Policies apply in name order, so where several
+// set the same field the last name wins. A policy without a namespaceSelector matches
+// every namespace. Malformed policies are skipped and reported. Returns nil when none
+// match or the DrainPolicy CRD isn't installed.
+func (p *DrainPolicyResolver) Resolve(ctx context.Context, namespace *corev1.Namespace) (*corev1.ConfigMap, error) {
+	if p.unavailable.Load() {
+		return nil, nil
+	}
+	policies, err := p.policies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]string{}
+	var matched []string
+	for _, policy := range policies {
+		matches, err := drainPolicyMatches(policy, namespace)
+		if err != nil {
+			p.reportInvalid(ctx, policy, err)
+			continue
+		}
+		if !matches {
+			continue
+		}
+
+		settings, err := drainPolicySettings(policy)
+		if err != nil {
+			p.reportInvalid(ctx, policy, err)
+			continue
+		}
+		maps.Copy(data, settings)
+		matched = append(matched, policy.GetName())
+	}
+
+	if len(matched) == 0 {
+		return nil, nil
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Join(matched, ",")},
+		Data:       data,
+	}, nil
+}
+
+// reportInvalid logs a skipped policy and records an event on it, once per resource version
+func (p *DrainPolicyResolver) reportInvalid(ctx context.Context, policy *unstructured.Unstructured, err error) {
+	version := policy.GetResourceVersion()
+	if previous, loaded := p.invalid.Swap(policy.GetName(), version); loaded && previous == version {
+		return
+	}
+	log.FromContext(ctx).Error(err, "Ignoring invalid drain policy", "drainPolicy", policy.GetName())
+	if p.Recorder != nil {
+		p.Recorder.Event(policy, corev1.EventTypeWarning, "InvalidDrainPolicy", err.Error())
+	}
+}
+
+// drainPolicySettings returns the policy's spec fields as ConfigMap data
+func drainPolicySettings(policy *unstructured.Unstructured) (map[string]string, error) {
+	settings := map[string]string{}
+	for _, field := range drainPolicyIntFields {
+		value, found, err := unstructured.NestedInt64(policy.Object, "spec", field)
+		if err != nil {
+			return nil, fmt.Errorf("drain policy %s has an invalid %s: %w", policy.GetName(), field, err)
+		}
+		if found {
+			settings[field] = strconv.FormatInt(value, 10)
+		}
+	}
+	for _, field := range drainPolicyStringFields {
+		value, found, err := unstructured.NestedString(policy.Object, "spec", field)
+		if err != nil {
+			return nil, fmt.Errorf("drain policy %s has an invalid %s: %w", policy.GetName(), field, err)
+		}
+		if found {
+			settings[field] = value
+		}
+	}
+	return settings, nil
+}
+
+// drainPolicyMatches reports whether the policy's namespaceSelector selects the namespace
+func drainPolicyMatches(policy *unstructured.Unstructured, namespace *corev1.Namespace) (bool, error) {
+	raw, found, err := unstructured.NestedMap(policy.Object, "spec", "namespaceSelector")
+	if err != nil {
+		return false, fmt.Errorf("drain policy %s has an invalid namespaceSelector: %w", policy.GetName(), err)
+	}
+	if !found {
+		return true, nil
+	}
+
+	var labelSelector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &labelSelector); err != nil {
+		return false, fmt.Errorf("drain policy %s has an invalid namespaceSelector: %w", policy.GetName(), err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil {
+		return false, fmt.Errorf("drain policy %s has an invalid namespaceSelector: %w", policy.GetName(), err)
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
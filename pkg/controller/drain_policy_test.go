@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+// newDrainPolicy builds a DrainPolicy with the given spec
+func newDrainPolicy(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DrainPolicyGVR.GroupVersion().String(),
+		"kind":       "DrainPolicy",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       spec,
+	}}
+}
+
+// newDrainPolicyResolver serves the given DrainPolicies from a fake dynamic client
+func newDrainPolicyResolver(policies ...runtime.Object) *DrainPolicyResolver {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{DrainPolicyGVR: "DrainPolicyList"}, policies...)
+	return &DrainPolicyResolver{Client: client}
+}
+
+var _ = Describe("DrainPolicyResolver", func() {
+	var (
+		ctx        context.Context
+		production *corev1.Namespace
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		production = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "shop",
+				Labels: map[string]string{"env": "production"},
+			},
+		}
+	})
+
+	It("should return the settings of a matching policy as ConfigMap data", func() {
+		resolver := newDrainPolicyResolver(newDrainPolicy("production", map[string]interface{}{
+			"namespaceSelector":   map[string]interface{}{"matchLabels": map[string]interface{}{"env": "production"}},
+			"gracePeriodSeconds":  int64(60),
+			"drainTimeoutSeconds": int64(900),
+			"managedExpression":   `has(pod.metadata.labels.app)`,
+		}))
+
+		policy, err := resolver.Resolve(ctx, production)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.Data).To(Equal(map[string]string{
+			"gracePeriodSeconds":  "60",
+			"drainTimeoutSeconds": "900",
+			"managedExpression":   `has(pod.metadata.labels.app)`,
+		}))
+	})
+
+	It("should ignore policies whose selector does not match", func() {
+		resolver := newDrainPolicyResolver(newDrainPolicy("staging", map[string]interface{}{
+			"namespaceSelector":  map[string]interface{}{"matchLabels": map[string]interface{}{"env": "staging"}},
+			"gracePeriodSeconds": int64(5),
+		}))
+
+		policy, err := resolver.Resolve(ctx, production)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy).To(BeNil())
+	})
+
+	It("should apply matching policies in name order", func() {
+		resolver := newDrainPolicyResolver(
+			newDrainPolicy("b-override", map[string]interface{}{
+				"namespaceSelector":  map[string]interface{}{"matchExpressions": []interface{}{map[string]interface{}{"key": "env", "operator": "In", "values": []interface{}{"production"}}}},
+				"gracePeriodSeconds": int64(120),
+			}),
+			newDrainPolicy("a-cluster-wide", map[string]interface{}{
+				"gracePeriodSeconds":  int64(60),
+				"drainTimeoutSeconds": int64(600),
+			}),
+		)
+
+		policy, err := resolver.Resolve(ctx, production)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.Name).To(Equal("a-cluster-wide,b-override"))
+		Expect(policy.Data).To(Equal(map[string]string{
+			"gracePeriodSeconds":  "120",
+			"drainTimeoutSeconds": "600",
+		}))
+	})
+
+	It("should skip a policy with a malformed field and report it once", func() {
+		recorder := record.NewFakeRecorder(10)
+		resolver := newDrainPolicyResolver(
+			newDrainPolicy("a-cluster-wide", map[string]interface{}{
+				"drainTimeoutSeconds": int64(600),
+			}),
+			newDrainPolicy("broken", map[string]interface{}{
+				"gracePeriodSeconds": "sixty",
+			}),
+		)
+		resolver.Recorder = recorder
+
+		policy, err := resolver.Resolve(ctx, production)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.Name).To(Equal("a-cluster-wide"))
+		Expect(policy.Data).To(Equal(map[string]string{"drainTimeoutSeconds": "600"}))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("InvalidDrainPolicy"), ContainSubstring("broken"))))
+
+		_, err = resolver.Resolve(ctx, production)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should skip a policy with an invalid selector", func() {
+		resolver := newDrainPolicyResolver(newDrainPolicy("broken", map[string]interface{}{
+			"namespaceSelector":  map[string]interface{}{"matchExpressions": []interface{}{map[string]interface{}{"key": "env", "operator": "Sometimes"}}},
+			"gracePeriodSeconds": int64(60),
+		}))
+
+		policy, err := resolver.Resolve(ctx, production)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy).To(BeNil())
+	})
+
+	It("should serve the policies from the informer cache once started", func() {
+		resolver := newDrainPolicyResolver(newDrainPolicy("production", map[string]interface{}{
+			"gracePeriodSeconds": int64(60),
+		}))
+		client := resolver.Client.(*dynamicfake.FakeDynamicClient)
+
+		startCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- resolver.Start(startCtx) }()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+		Eventually(resolver.lister.Load).ShouldNot(BeNil())
+
+		client.ClearActions()
+		policy, err := resolver.Resolve(ctx, production)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.Data).To(Equal(map[string]string{"gracePeriodSeconds": "60"}))
+		Expect(client.Actions()).To(BeEmpty())
+
+		_, err = client.Resource(DrainPolicyGVR).Create(ctx, newDrainPolicy("z-later", map[string]interface{}{
+			"gracePeriodSeconds": int64(90),
+		}), metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func() map[string]string {
+			policy, _ := resolver.Resolve(ctx, production)
+			return policy.Data
+		}).Should(Equal(map[string]string{"gracePeriodSeconds": "90"}))
+	})
+})
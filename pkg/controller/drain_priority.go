@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// DrainPriorityAnnotation orders drains among pods of the same owner: while several are
+// draining, only those with the lowest value may complete. Unannotated pods are unordered.
+const DrainPriorityAnnotation = "vpa-graceful-drain.cho.github.io/drain-priority"
+
+// drainPriority returns the pod's drain priority and whether it carries a valid one
+func drainPriority(pod *corev1.Pod) (int64, bool) {
+	value, exists := pod.Annotations[DrainPriorityAnnotation]
+	if !exists {
+		return 0, false
+	}
+	priority, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return priority, true
+}
+
+// ownerUID returns the UID of the pod's managing controller, falling back to its first owner
+func ownerUID(pod *corev1.Pod) types.UID {
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		return owner.UID
+	}
+	if len(pod.OwnerReferences) > 0 {
+		return pod.OwnerReferences[0].UID
+	}
+	return ""
+}
+
+// drainBlockedBy returns the name of a draining sibling with a lower drain priority that
+// must be released before this pod, or "" if the pod may complete now
+func (r *PodReconciler) drainBlockedBy(ctx context.Context, pod *corev1.Pod) (string, error) {
+	priority, ok := drainPriority(pod)
+	if !ok {
+		return "", nil
+	}
+	owner := ownerUID(pod)
+	if owner == "" {
+		return "", nil
+	}
+
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(pod.Namespace)); err != nil {
+		return "", fmt.Errorf("failed to list sibling pods: %w", err)
+	}
+
+	for i := range podList.Items {
+		sibling := &podList.Items[i]
+		if sibling.UID == pod.UID || ownerUID(sibling) != owner {
+			continue
+		}
+		if sibling.DeletionTimestamp == nil || !controllerutil.ContainsFinalizer(sibling, r.finalizerName()) {
+			continue
+		}
+		if siblingPriority, ok := drainPriority(sibling); ok && siblingPriority < priority {
+			return sibling.Name, nil
+		}
+	}
+	return "", nil
+}
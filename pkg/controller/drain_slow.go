@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
+)
+
+// DrainSlowAnnotation records when a pod's drain was first reported slow, so the
+// DrainSlow event and metric fire once per pod, across restarts too
+const DrainSlowAnnotation = "vpa-graceful-drain.cho.github.io/drain-slow-at"
+
+// recordDrainSlow emits a DrainSlow warning event and counts the pod in DrainSlowTotal
+// once its drain has used slowDrainThreshold of the drain timeout
+func (r *PodReconciler) recordDrainSlow(ctx context.Context, pod *corev1.Pod, config *Config, elapsed, drainTimeout time.Duration) error {
+	if config.SlowDrainThreshold <= 0 || drainTimeout <= 0 {
+		return nil
+	}
+	if _, ok := pod.Annotations[DrainSlowAnnotation]; ok {
+		return nil
+	}
+
+	if elapsed < slowDrainAfter(config, drainTimeout) {
+		return nil
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = map[string]string{}
+	}
+	podCopy.Annotations[DrainSlowAnnotation] = r.clock().Now().UTC().Format(time.RFC3339)
+	if err := r.Patch(ctx, podCopy, client.MergeFrom(pod)); err != nil {
+		return err
+	}
+	*pod = *podCopy
+
+	metrics.DrainSlowTotal.Inc()
+	r.Recorder.Event(pod, corev1.EventTypeWarning, "DrainSlow",
+		fmt.Sprintf("Graceful drain has been running for %s, %.0f%% of the %s drain timeout",
+			elapsed.Truncate(time.Second), config.SlowDrainThreshold*100, drainTimeout))
+	return nil
+}
+
+// untilDrainSlow returns how long until the drain crosses slowDrainThreshold, or 0 when it
+// already has, was already reported, or the threshold is disabled
+func untilDrainSlow(pod *corev1.Pod, config *Config, elapsed, drainTimeout time.Duration) time.Duration {
+	if config.SlowDrainThreshold <= 0 || drainTimeout <= 0 {
+		return 0
+	}
+	if _, ok := pod.Annotations[DrainSlowAnnotation]; ok {
+		return 0
+	}
+	return max(slowDrainAfter(config, drainTimeout)-elapsed, 0)
+}
+
+func slowDrainAfter(config *Config, drainTimeout time.Duration) time.Duration {
+	return time.Duration(float64(drainTimeout) * config.SlowDrainThreshold)
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
+)
+
+var _ = Describe("recordDrainSlow", func() {
+	var (
+		ctx          context.Context
+		reconciler   *PodReconciler
+		recorder     *record.FakeRecorder
+		config       *Config
+		pod          *corev1.Pod
+		drainTimeout time.Duration
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		deletionTime := metav1.NewTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-pod",
+				Namespace:         "default",
+				DeletionTimestamp: &deletionTime,
+				Finalizers:        []string{VPAGracefulDrainFinalizer},
+			},
+		}
+
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &PodReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build(),
+			Recorder: recorder,
+			Clock:    fixedClock{now: deletionTime.Add(4 * time.Minute)},
+		}
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+		config = NewDefaultConfig()
+		drainTimeout = 300 * time.Second
+	})
+
+	It("should not fire just below the threshold", func() {
+		slowBefore := testutil.ToFloat64(metrics.DrainSlowTotal)
+
+		Expect(reconciler.recordDrainSlow(ctx, pod, config, 240*time.Second-time.Millisecond, drainTimeout)).To(Succeed())
+		Expect(testutil.ToFloat64(metrics.DrainSlowTotal)).To(Equal(slowBefore))
+		Expect(recorder.Events).To(BeEmpty())
+		Expect(pod.Annotations).ToNot(HaveKey(DrainSlowAnnotation))
+	})
+
+	It("should fire once the threshold is reached", func() {
+		slowBefore := testutil.ToFloat64(metrics.DrainSlowTotal)
+
+		Expect(reconciler.recordDrainSlow(ctx, pod, config, 240*time.Second, drainTimeout)).To(Succeed())
+		Expect(testutil.ToFloat64(metrics.DrainSlowTotal)).To(Equal(slowBefore + 1))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Warning DrainSlow")))
+
+		var updated corev1.Pod
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(pod), &updated)).To(Succeed())
+		Expect(updated.Annotations).To(HaveKey(DrainSlowAnnotation))
+	})
+
+	It("should fire at most once per pod", func() {
+		slowBefore := testutil.ToFloat64(metrics.DrainSlowTotal)
+
+		Expect(reconciler.recordDrainSlow(ctx, pod, config, 250*time.Second, drainTimeout)).To(Succeed())
+		Expect(reconciler.recordDrainSlow(ctx, pod, config, 260*time.Second, drainTimeout)).To(Succeed())
+
+		var updated corev1.Pod
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(pod), &updated)).To(Succeed())
+		Expect(reconciler.recordDrainSlow(ctx, &updated, config, 270*time.Second, drainTimeout)).To(Succeed())
+
+		Expect(testutil.ToFloat64(metrics.DrainSlowTotal)).To(Equal(slowBefore + 1))
+		Expect(recorder.Events).To(HaveLen(1))
+	})
+
+	It("should use the configured threshold", func() {
+		config.SlowDrainThreshold = 0.5
+
+		Expect(reconciler.recordDrainSlow(ctx, pod, config, 150*time.Second, drainTimeout)).To(Succeed())
+		Expect(recorder.Events).To(HaveLen(1))
+	})
+
+	It("should report the time left until the threshold", func() {
+		Expect(untilDrainSlow(pod, config, 235*time.Second, drainTimeout)).To(Equal(5 * time.Second))
+		Expect(untilDrainSlow(pod, config, 240*time.Second, drainTimeout)).To(BeZero())
+
+		config.SlowDrainThreshold = 0
+		Expect(untilDrainSlow(pod, config, 0, drainTimeout)).To(BeZero())
+	})
+
+	It("should report nothing left once the drain was reported slow", func() {
+		pod.Annotations = map[string]string{DrainSlowAnnotation: "2024-01-01T12:04:00Z"}
+
+		Expect(untilDrainSlow(pod, config, 0, drainTimeout)).To(BeZero())
+	})
+
+	It("should never fire when the threshold is zero", func() {
+		config.SlowDrainThreshold = 0
+
+		Expect(reconciler.recordDrainSlow(ctx, pod, config, drainTimeout, drainTimeout)).To(Succeed())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+// PodDrainStatus is the drain state of one pod carrying our finalizer
+type PodDrainStatus struct {
+	Name string
+	// Draining is true once the pod has a deletion timestamp; Phase and Elapsed are only set then
+	Draining bool
+	Phase    finalizer.DrainPhase
+	Elapsed  time.Duration
+	// InEndpoints reports whether the pod currently looks like it is serving traffic
+	InEndpoints bool
+}
+
+// DrainStatusForNamespace reports the drain state of every pod in the namespace that
+// carries our finalizer, using only the drain handler's read-only checks
+func (r *PodReconciler) DrainStatusForNamespace(ctx context.Context, namespace string) ([]PodDrainStatus, error) {
+	config, err := r.getConfig(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	drainHandler := finalizer.NewDrainHandler(r.Client, config).WithClock(r.clock())
+	switch config.ConnectionCheckMode {
+	case finalizer.ConnectionCheckModeConntrack:
+		drainHandler.WithConnTracker(r.connTracker(config))
+	case finalizer.ConnectionCheckModeGRPCHealth:
+		drainHandler.WithGRPCHealthChecker(r.grpcHealthChecker(config))
+	}
+	if r.GracePeriodOnly {
+		drainHandler.WithoutEndpointCheck()
+	}
+	if r.serviceIndexed {
+		drainHandler.WithServiceSelectorIndex()
+	}
+
+	statuses := []PodDrainStatus{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !controllerutil.ContainsFinalizer(pod, r.finalizerName()) {
+			continue
+		}
+
+		estimate, err := drainHandler.EstimateDrain(ctx, pod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check pod %s: %w", pod.Name, err)
+		}
+
+		status := PodDrainStatus{
+			Name:        pod.Name,
+			Draining:    pod.DeletionTimestamp != nil,
+			InEndpoints: estimate.HasActiveEndpoints,
+		}
+		if status.Draining {
+			drainStatus := drainHandler.DrainStatus(ctx, pod)
+			status.Phase = drainStatus.Phase
+			status.Elapsed = r.clock().Now().Sub(pod.DeletionTimestamp.Time)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
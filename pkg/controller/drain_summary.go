@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+// ConnectionsClearedAnnotation records when a pod's drain completed while its finalizer
+// removal had to wait (e.g. for a sibling with a lower drain priority), so the completion
+// summary can tell time spent draining from time spent queued
+const ConnectionsClearedAnnotation = "vpa-graceful-drain.cho.github.io/connections-cleared-at"
+
+// drainSummary breaks down how long a pod was held by the drain and why it was released
+type drainSummary struct {
+	Held               time.Duration
+	GracePeriod        time.Duration
+	WaitingConnections time.Duration
+	Queued             time.Duration
+	TimedOut           bool
+	Reason             string
+}
+
+// summarizeDrain computes the drain summary at finalizer removal time now
+func summarizeDrain(pod *corev1.Pod, status finalizer.DrainStatus, reason string, now time.Time) drainSummary {
+	summary := drainSummary{
+		Reason:   reason,
+		TimedOut: reason == finalizer.CompletionReasonTimeout || reason == finalizer.CompletionReasonHardTimeout,
+	}
+	if pod.DeletionTimestamp == nil {
+		return summary
+	}
+
+	deletedAt := pod.DeletionTimestamp.Time
+	summary.Held = now.Sub(deletedAt)
+
+	clearedAt := now
+	if value, ok := pod.Annotations[ConnectionsClearedAnnotation]; ok {
+		if recorded, err := time.Parse(time.RFC3339, value); err == nil && !recorded.Before(deletedAt) && recorded.Before(now) {
+			clearedAt = recorded
+		}
+	}
+	summary.Queued = now.Sub(clearedAt)
+
+	drained := clearedAt.Sub(deletedAt)
+	summary.GracePeriod = min(time.Duration(status.GracePeriodSeconds)*time.Second, drained)
+	summary.WaitingConnections = drained - summary.GracePeriod
+	return summary
+}
+
+// keysAndValues renders the summary as structured log fields
+func (s drainSummary) keysAndValues() []interface{} {
+	return []interface{}{
+		"reason", s.Reason,
+		"heldSeconds", s.Held.Seconds(),
+		"gracePeriodSeconds", s.GracePeriod.Seconds(),
+		"waitingConnectionsSeconds", s.WaitingConnections.Seconds(),
+		"queuedSeconds", s.Queued.Seconds(),
+		"timedOut", s.TimedOut,
+	}
+}
+
+// recordConnectionsCleared stamps the first time the pod's drain was found complete,
+// updating pod to the patched version
+func (r *PodReconciler) recordConnectionsCleared(ctx context.Context, pod *corev1.Pod) error {
+	if _, ok := pod.Annotations[ConnectionsClearedAnnotation]; ok {
+		return nil
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = map[string]string{}
+	}
+	podCopy.Annotations[ConnectionsClearedAnnotation] = r.clock().Now().UTC().Format(time.RFC3339)
+	if err := r.Patch(ctx, podCopy, client.MergeFrom(pod)); err != nil {
+		return err
+	}
+
+	*pod = *podCopy
+	return nil
+}
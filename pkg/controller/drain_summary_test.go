@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+var _ = Describe("summarizeDrain", func() {
+	var (
+		deletedAt time.Time
+		pod       *corev1.Pod
+		status    finalizer.DrainStatus
+	)
+
+	BeforeEach(func() {
+		deletedAt = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		deletionTime := metav1.NewTime(deletedAt)
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-pod",
+				Namespace:         "default",
+				DeletionTimestamp: &deletionTime,
+				Annotations:       map[string]string{},
+			},
+		}
+		status = finalizer.DrainStatus{GracePeriodSeconds: 30, DeadlineSeconds: 300}
+	})
+
+	It("should split the hold time into grace period and connection wait", func() {
+		summary := summarizeDrain(pod, status, finalizer.CompletionReasonNoConnections, deletedAt.Add(90*time.Second))
+
+		Expect(summary).To(Equal(drainSummary{
+			Held:               90 * time.Second,
+			GracePeriod:        30 * time.Second,
+			WaitingConnections: 60 * time.Second,
+			Reason:             finalizer.CompletionReasonNoConnections,
+		}))
+	})
+
+	It("should count time after the recorded completion as queued", func() {
+		pod.Annotations[ConnectionsClearedAnnotation] = deletedAt.Add(60 * time.Second).Format(time.RFC3339)
+
+		summary := summarizeDrain(pod, status, finalizer.CompletionReasonNoConnections, deletedAt.Add(90*time.Second))
+		Expect(summary.Held).To(Equal(90 * time.Second))
+		Expect(summary.WaitingConnections).To(Equal(30 * time.Second))
+		Expect(summary.Queued).To(Equal(30 * time.Second))
+	})
+
+	It("should not report a connection wait for drains that ended within the grace period", func() {
+		summary := summarizeDrain(pod, status, finalizer.CompletionReasonForceCompleted, deletedAt.Add(10*time.Second))
+
+		Expect(summary.GracePeriod).To(Equal(10 * time.Second))
+		Expect(summary.WaitingConnections).To(BeZero())
+		Expect(summary.TimedOut).To(BeFalse())
+	})
+
+	It("should flag timeouts", func() {
+		summary := summarizeDrain(pod, status, finalizer.CompletionReasonTimeout, deletedAt.Add(301*time.Second))
+		Expect(summary.TimedOut).To(BeTrue())
+
+		summary = summarizeDrain(pod, status, finalizer.CompletionReasonHardTimeout, deletedAt.Add(361*time.Second))
+		Expect(summary.TimedOut).To(BeTrue())
+	})
+})
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
+)
+
+// DrainEntry describes a pod currently held by the graceful drain finalizer
+type DrainEntry struct {
+	Namespace         string
+	Name              string
+	UID               types.UID
+	DeletionTimestamp time.Time
+	Phase             finalizer.DrainPhase
+}
+
+// DrainTracker keeps an in-memory view of the pods that are currently draining.
+// It is rebuilt from the cluster on startup since it does not survive restarts.
+type DrainTracker struct {
+	mu     sync.RWMutex
+	drains map[types.NamespacedName]DrainEntry
+}
+
+func NewDrainTracker() *DrainTracker {
+	return &DrainTracker{
+		drains: make(map[types.NamespacedName]DrainEntry),
+	}
+}
+
+func (t *DrainTracker) Track(pod *corev1.Pod, phase finalizer.DrainPhase) {
+	if pod.DeletionTimestamp == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.drains[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = DrainEntry{
+		Namespace:         pod.Namespace,
+		Name:              pod.Name,
+		UID:               pod.UID,
+		DeletionTimestamp: pod.DeletionTimestamp.Time,
+		Phase:             phase,
+	}
+	metrics.ActiveDrains.Set(float64(len(t.drains)))
+}
+
+// Get returns the tracked drain for the key, if any
+func (t *DrainTracker) Get(key types.NamespacedName) (DrainEntry, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	entry, ok := t.drains[key]
+	return entry, ok
+}
+
+func (t *DrainTracker) Untrack(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.drains, key)
+	metrics.ActiveDrains.Set(float64(len(t.drains)))
+}
+
+func (t *DrainTracker) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.drains)
+}
+
+// List returns a snapshot of the tracked drains ordered by namespace and name
+func (t *DrainTracker) List() []DrainEntry {
+	t.mu.RLock()
+	entries := make([]DrainEntry, 0, len(t.drains))
+	for _, entry := range t.drains {
+		entries = append(entries, entry)
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+var _ = Describe("DrainTracker", func() {
+	var tracker *DrainTracker
+
+	BeforeEach(func() {
+		tracker = NewDrainTracker()
+	})
+
+	It("should ignore pods without a deletion timestamp", func() {
+		tracker.Track(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"},
+		}, "")
+
+		Expect(tracker.Len()).To(Equal(0))
+	})
+
+	It("should track and untrack draining pods", func() {
+		deletionTime := metav1.NewTime(time.Now())
+		tracker.Track(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-pod",
+				Namespace:         "default",
+				UID:               "uid-1",
+				DeletionTimestamp: &deletionTime,
+			},
+		}, "grace-period")
+
+		Expect(tracker.Len()).To(Equal(1))
+		Expect(tracker.List()[0].UID).To(Equal(types.UID("uid-1")))
+		Expect(tracker.List()[0].Phase).To(Equal(finalizer.DrainPhaseGracePeriod))
+
+		tracker.Untrack(types.NamespacedName{Name: "test-pod", Namespace: "default"})
+		Expect(tracker.Len()).To(Equal(0))
+	})
+})
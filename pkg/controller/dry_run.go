@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+// ManagementReason is why a pod is managed by the controller
+type ManagementReason string
+
+const (
+	// ManagementReasonExpression means the managedExpression selected the pod
+	ManagementReasonExpression ManagementReason = "expression"
+	// ManagementReasonAnnotation means the pod is annotated vpa-managed: "true"
+	ManagementReasonAnnotation ManagementReason = "annotation"
+	// ManagementReasonManageIfAnnotation means the pod carries one of manageIfAnnotations
+	ManagementReasonManageIfAnnotation ManagementReason = "manage-if-annotation"
+	// ManagementReasonVPAAnnotation means the pod carries an annotation set by the VPA
+	ManagementReasonVPAAnnotation ManagementReason = "vpa-annotation"
+	// ManagementReasonLabel means the pod is labelled vpa.k8s.io/managed
+	ManagementReasonLabel ManagementReason = "label"
+	// ManagementReasonHeuristic means the pod's resource requests look set by the VPA
+	ManagementReasonHeuristic ManagementReason = "heuristic"
+)
+
+// ManagedPodReport describes how the controller would handle a running pod
+type ManagedPodReport struct {
+	Namespace string
+	Name      string
+	Reason    ManagementReason
+	// GracePeriod and DrainTimeout are the drain window the pod would get, after owner
+	// kind overrides and the other per-pod adjustments
+	GracePeriod  time.Duration
+	DrainTimeout time.Duration
+	// Config is the effective configuration of the pod's namespace
+	Config *Config
+}
+
+// AuditCluster reports every running pod the controller would manage and the drain it
+// would get, without modifying anything. It works whether or not the controller is
+// enabled, so it can be used as a dry run before turning it on.
+func (r *PodReconciler) AuditCluster(ctx context.Context) ([]ManagedPodReport, error) {
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	configs := map[string]*Config{}
+	reports := []ManagedPodReport{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		config, ok := configs[pod.Namespace]
+		if !ok {
+			var err error
+			config, err = r.getConfig(ctx, pod.Namespace)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get configuration for namespace %s: %w", pod.Namespace, err)
+			}
+			configs[pod.Namespace] = config
+		}
+
+		reason := r.managementReason(pod, config)
+		if reason == "" {
+			continue
+		}
+
+		status := finalizer.NewDrainHandler(r.Client, config).WithClock(r.clock()).DrainStatus(ctx, pod)
+		reports = append(reports, ManagedPodReport{
+			Namespace:    pod.Namespace,
+			Name:         pod.Name,
+			Reason:       reason,
+			GracePeriod:  time.Duration(status.GracePeriodSeconds) * time.Second,
+			DrainTimeout: time.Duration(status.DeadlineSeconds) * time.Second,
+			Config:       config,
+		})
+	}
+	return reports, nil
+}
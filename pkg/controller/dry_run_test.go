@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("AuditCluster", func() {
+	var (
+		ctx        context.Context
+		reconciler *PodReconciler
+	)
+
+	newPod := func(namespace, name string, annotations, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: annotations,
+				Labels:      labels,
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		controller := true
+		heuristic := newPod("default", "heuristic", nil, nil)
+		heuristic.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-abc", UID: "web-abc-uid", Controller: &controller},
+		}
+		heuristic.Spec.Containers = []corev1.Container{{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("152m")},
+			},
+		}}
+		completed := newPod("default", "completed", map[string]string{"vpa-managed": "true"}, nil)
+		completed.Status.Phase = corev1.PodSucceeded
+		teamConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "team-a"},
+			Data:       map[string]string{"gracePeriodSeconds": "90"},
+		}
+
+		objects := []client.Object{
+			newPod("default", "annotated", map[string]string{"vpa-managed": "true"}, nil),
+			newPod("default", "labelled", nil, map[string]string{"vpa.k8s.io/managed": "true"}),
+			newPod("default", "vpa-updated", map[string]string{"vpa-updater.client.k8s.io/last-updated": "2026-01-01T00:00:00Z"}, nil),
+			newPod("default", "opted-out", map[string]string{"vpa-managed": "false", "vpa.k8s.io/resource-name": "web"}, nil),
+			newPod("default", "plain", nil, nil),
+			newPod("team-a", "team-pod", map[string]string{"vpa-managed": "true"}, nil),
+			heuristic,
+			completed,
+			teamConfigMap,
+		}
+
+		reconciler = &PodReconciler{
+			Client:             fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			Scheme:             scheme,
+			Recorder:           record.NewFakeRecorder(10),
+			Tracker:            NewDrainTracker(),
+			ConfigMapName:      "test-config",
+			ConfigMapNamespace: "test-namespace",
+			Rand:               rand.New(rand.NewSource(1)),
+		}
+	})
+
+	It("should report every managed running pod with why it is managed", func() {
+		reports, err := reconciler.AuditCluster(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		reasons := map[string]ManagementReason{}
+		for _, report := range reports {
+			reasons[report.Namespace+"/"+report.Name] = report.Reason
+		}
+		Expect(reasons).To(Equal(map[string]ManagementReason{
+			"default/annotated":   ManagementReasonAnnotation,
+			"default/labelled":    ManagementReasonLabel,
+			"default/vpa-updated": ManagementReasonVPAAnnotation,
+			"default/heuristic":   ManagementReasonHeuristic,
+			"team-a/team-pod":     ManagementReasonAnnotation,
+		}))
+	})
+
+	It("should report the drain window from the namespace's effective configuration", func() {
+		reports, err := reconciler.AuditCluster(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		windows := map[string]time.Duration{}
+		for _, report := range reports {
+			windows[report.Namespace+"/"+report.Name] = report.GracePeriod
+			Expect(report.DrainTimeout).To(Equal(300 * time.Second))
+			Expect(report.Config).ToNot(BeNil())
+		}
+		Expect(windows).To(HaveKeyWithValue("default/annotated", 30*time.Second))
+		Expect(windows).To(HaveKeyWithValue("team-a/team-pod", 90*time.Second))
+	})
+
+	It("should report nothing when the namespace selector excludes every namespace", func() {
+		Expect(reconciler.Client.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "test-namespace"},
+			Data:       map[string]string{"namespaceSelector": `{"include": ["production"]}`},
+		})).To(Succeed())
+
+		reports, err := reconciler.AuditCluster(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(BeEmpty())
+	})
+})
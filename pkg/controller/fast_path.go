@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// selectionVerdictTTL bounds how long a namespace's selection verdict is trusted before
+// the configuration is read again, so ConfigMap edits are picked up
+const selectionVerdictTTL = 30 * time.Second
+
+// selectionVerdict records which configuration-dependent ways of selecting pods were in
+// effect for a namespace when its configuration was last read
+type selectionVerdict struct {
+	// optInByConfig is set when a CEL expression or manageIfAnnotations can select pods
+	optInByConfig bool
+	// resourceHeuristic is set when owned pods may be selected by their resource requests
+	resourceHeuristic bool
+	expires           time.Time
+}
+
+// rememberSelection records the namespace's selection verdict for the fast path
+func (r *PodReconciler) rememberSelection(namespace string, config *Config) {
+	r.selectionVerdicts.Store(namespace, selectionVerdict{
+		optInByConfig:     config.HasManagedExpression() || len(config.ManageIfAnnotations) > 0,
+		resourceHeuristic: !config.DisableResourceHeuristic,
+		expires:           r.clock().Now().Add(selectionVerdictTTL),
+	})
+}
+
+// obviouslyUnmanaged reports whether the pod can be skipped without reading configuration:
+// it isn't terminating, doesn't carry our finalizer, has no quick management signal, and
+// the namespace's recent configuration has no other way to select it. Pods that later gain
+// a signal are reconciled again through the event filter.
+func (r *PodReconciler) obviouslyUnmanaged(pod *corev1.Pod) bool {
+	if !r.shouldAddFinalizer(pod) || hasQuickManagementSignal(pod) {
+		return false
+	}
+
+	value, ok := r.selectionVerdicts.Load(pod.Namespace)
+	if !ok {
+		return false
+	}
+	verdict := value.(selectionVerdict)
+	if r.clock().Now().After(verdict.expires) || verdict.optInByConfig {
+		return false
+	}
+	// The resource heuristic only looks at pods with an owner
+	return !verdict.resourceHeuristic || len(pod.OwnerReferences) == 0
+}
+
+// hasQuickManagementSignal reports whether the pod carries one of the annotations or labels
+// that opt it in regardless of configuration
+func hasQuickManagementSignal(pod *corev1.Pod) bool {
+	if _, exists := pod.Annotations["vpa-managed"]; exists {
+		return true
+	}
+	if _, exists := pod.Annotations["vpa-updater.client.k8s.io/last-updated"]; exists {
+		return true
+	}
+	if pod.Annotations["vpa.k8s.io/resource-name"] != "" {
+		return true
+	}
+	_, exists := pod.Labels["vpa.k8s.io/managed"]
+	return exists
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// BackfillFinalizers adds our finalizer to every managed pod that lacks it, for pods that
+// existed before the controller was enabled. Pods whose namespace config can't be read
+// or whose namespace is paused are skipped. It returns the number of pods that were given the finalizer.
+func (r *PodReconciler) BackfillFinalizers(ctx context.Context) (int, error) {
+	logger := log.FromContext(ctx)
+
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList); err != nil {
+		return 0, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	configs := map[string]*Config{}
+	paused := map[string]bool{}
+	backfilled := 0
+	var lastErr error
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !r.shouldAddFinalizer(pod) {
+			continue
+		}
+
+		config, ok := configs[pod.Namespace]
+		if !ok {
+			var err error
+			config, err = r.getConfig(ctx, pod.Namespace)
+			if err != nil {
+				logger.Error(err, "Failed to get configuration for finalizer backfill", "namespace", pod.Namespace)
+				lastErr = err
+			}
+			configs[pod.Namespace] = config
+		}
+		if config == nil || !config.Enabled || !r.shouldManagePod(pod, config) {
+			continue
+		}
+
+		// Reconcile would release the finalizer again right away
+		namespacePaused, ok := paused[pod.Namespace]
+		if !ok {
+			namespacePaused = r.namespacePaused(ctx, pod.Namespace)
+			paused[pod.Namespace] = namespacePaused
+		}
+		if namespacePaused {
+			continue
+		}
+
+		if err := r.addFinalizer(ctx, pod, config); err != nil {
+			// Keep going so one failing pod doesn't leave the rest unprotected
+			logger.Error(err, "Failed to add finalizer during backfill", "pod", pod.Name, "namespace", pod.Namespace)
+			lastErr = err
+			continue
+		}
+		backfilled++
+	}
+
+	logger.Info("Backfilled finalizers on existing pods", "pods", backfilled)
+	return backfilled, lastErr
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// finalizerCleanupTimeout bounds the shutdown cleanup pass so it finishes within the
+// manager's default graceful shutdown timeout
+const finalizerCleanupTimeout = 20 * time.Second
+
+// CleanupFinalizers removes our finalizer from every pod that is not being deleted, so
+// uninstalling the controller doesn't leave pods whose deletion would hang forever.
+// Draining pods keep the finalizer for the next controller instance to finish.
+// It returns the number of pods cleaned up.
+func (r *PodReconciler) CleanupFinalizers(ctx context.Context, reader client.Reader) (int, error) {
+	logger := log.FromContext(ctx)
+
+	var podList corev1.PodList
+	if err := reader.List(ctx, &podList); err != nil {
+		return 0, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	// The manager's cache is gone by now, so the ConfigMaps aren't read and the finalizer
+	// is written with the default update strategy
+	config := NewDefaultConfig(r.configDefaults()...)
+
+	cleaned, skipped := 0, 0
+	var lastErr error
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !controllerutil.ContainsFinalizer(pod, r.finalizerName()) {
+			continue
+		}
+		if pod.DeletionTimestamp != nil {
+			skipped++
+			continue
+		}
+
+		removed, err := r.cleanupFinalizer(ctx, reader, pod, config)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			// Keep going so one failing pod doesn't strand the rest
+			logger.Error(err, "Failed to remove finalizer during shutdown cleanup", "pod", pod.Name, "namespace", pod.Namespace)
+			lastErr = err
+			continue
+		}
+		if removed {
+			cleaned++
+		}
+	}
+
+	logger.Info("Removed finalizers from idle pods", "pods", cleaned, "skippedDraining", skipped)
+	return cleaned, lastErr
+}
+
+// cleanupFinalizer removes our finalizer from an idle pod, re-reading it through reader and
+// retrying on conflicts. It reports false when the pod lost the finalizer or started
+// draining in the meantime.
+func (r *PodReconciler) cleanupFinalizer(ctx context.Context, reader client.Reader, pod *corev1.Pod, config *Config) (bool, error) {
+	removed := false
+	current := pod
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if current == nil {
+			current = &corev1.Pod{}
+			if err := reader.Get(ctx, client.ObjectKeyFromObject(pod), current); err != nil {
+				return err
+			}
+		}
+
+		if !controllerutil.ContainsFinalizer(current, r.finalizerName()) || current.DeletionTimestamp != nil {
+			return nil
+		}
+
+		original := current
+		podCopy := current.DeepCopy()
+		current = nil
+		controllerutil.RemoveFinalizer(podCopy, r.finalizerName())
+		if err := r.writeFinalizers(ctx, original, podCopy, config); err != nil {
+			return err
+		}
+		removed = true
+		return nil
+	})
+	return removed, err
+}
+
+// finalizerCleanupRunnable waits for the manager to shut down and then runs the cleanup pass.
+// The manager's context is already cancelled by then, so the pass gets its own deadline.
+func (r *PodReconciler) finalizerCleanupRunnable(reader client.Reader) manager.Runnable {
+	return manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalizerCleanupTimeout)
+		defer cancel()
+		if _, err := r.CleanupFinalizers(cleanupCtx, reader); err != nil {
+			log.FromContext(ctx).Error(err, "Finalizer cleanup on shutdown did not complete")
+		}
+		return nil
+	})
+}
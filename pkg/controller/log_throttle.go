@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// LogThrottle limits a repeated per-pod log line to once per interval. A change of
+// state (e.g. the drain phase) is always let through so transitions are never hidden.
+type LogThrottle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[types.UID]loggedState
+}
+
+type loggedState struct {
+	at    time.Time
+	state string
+}
+
+// NewLogThrottle returns a throttle that lets a pod's line through at most once per
+// interval while its state is unchanged. An interval of 0 disables throttling.
+func NewLogThrottle(interval time.Duration) *LogThrottle {
+	return &LogThrottle{
+		interval: interval,
+		last:     make(map[types.UID]loggedState),
+	}
+}
+
+// SetInterval changes the interval, e.g. after the configuration was reloaded
+func (t *LogThrottle) SetInterval(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.interval = interval
+}
+
+// Allow reports whether the pod's line should be logged at now, recording it if so
+func (t *LogThrottle) Allow(uid types.UID, state string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if previous, ok := t.last[uid]; ok && previous.state == state && now.Sub(previous.at) < t.interval {
+		return false
+	}
+	t.last[uid] = loggedState{at: now, state: state}
+	return true
+}
+
+// Forget drops the pod's history once it is no longer draining
+func (t *LogThrottle) Forget(uid types.UID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.last, uid)
+}
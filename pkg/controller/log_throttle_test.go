@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogThrottle", func() {
+	var (
+		throttle *LogThrottle
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		throttle = NewLogThrottle(time.Minute)
+		now = time.Now()
+	})
+
+	It("should suppress repeated lines within the interval", func() {
+		Expect(throttle.Allow("uid-1", "waiting-connections", now)).To(BeTrue())
+		Expect(throttle.Allow("uid-1", "waiting-connections", now.Add(10*time.Second))).To(BeFalse())
+		Expect(throttle.Allow("uid-1", "waiting-connections", now.Add(50*time.Second))).To(BeFalse())
+		Expect(throttle.Allow("uid-1", "waiting-connections", now.Add(time.Minute))).To(BeTrue())
+	})
+
+	It("should always let a state change through", func() {
+		Expect(throttle.Allow("uid-1", "grace-period", now)).To(BeTrue())
+		Expect(throttle.Allow("uid-1", "waiting-connections", now.Add(time.Second))).To(BeTrue())
+		Expect(throttle.Allow("uid-1", "waiting-connections", now.Add(2*time.Second))).To(BeFalse())
+	})
+
+	It("should throttle each pod separately", func() {
+		Expect(throttle.Allow("uid-1", "grace-period", now)).To(BeTrue())
+		Expect(throttle.Allow("uid-2", "grace-period", now)).To(BeTrue())
+		Expect(throttle.Allow("uid-1", "grace-period", now)).To(BeFalse())
+	})
+
+	It("should log again after the pod is forgotten", func() {
+		Expect(throttle.Allow("uid-1", "grace-period", now)).To(BeTrue())
+		throttle.Forget("uid-1")
+		Expect(throttle.Allow("uid-1", "grace-period", now)).To(BeTrue())
+	})
+
+	It("should not throttle with a zero interval", func() {
+		throttle.SetInterval(0)
+		Expect(throttle.Allow("uid-1", "grace-period", now)).To(BeTrue())
+		Expect(throttle.Allow("uid-1", "grace-period", now)).To(BeTrue())
+	})
+})
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// NamespacePausedAnnotation pauses drain management for every pod in a Namespace carrying
+// it with "true": no finalizers are added and existing ones are released
+const NamespacePausedAnnotation = "vpa-graceful-drain.cho.github.io/paused"
+
+// namespacePaused reports whether the namespace is annotated as paused. A namespace that
+// can't be read counts as not paused, so a transient error never releases pods early.
+func (r *PodReconciler) namespacePaused(ctx context.Context, name string) bool {
+	var namespace corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, &namespace); err != nil {
+		if !errors.IsNotFound(err) {
+			log.FromContext(ctx).V(1).Info("Failed to get namespace, assuming it is not paused", "namespace", name, "error", err.Error())
+		}
+		return false
+	}
+	return namespace.Annotations[NamespacePausedAnnotation] == "true"
+}
@@ -2,30 +2,283 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	"github.com/cho/vpa-graceful-drain-controller/pkg/conntrack"
 	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/grpchealth"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
 )
 
 const (
 	VPAGracefulDrainFinalizer = "vpa-graceful-drain.cho.github.io/finalizer"
+
+	// requeueJitterFraction spreads requeues of pods deleted together by up to ±20%
+	requeueJitterFraction = 0.2
+
+	// The endpoint check circuit opens after this many failures within the window
+	// and probes the API again after the cooldown
+	endpointBreakerFailureThreshold = 5
+	endpointBreakerWindow           = time.Minute
+	endpointBreakerCooldown         = 30 * time.Second
 )
 
 type PodReconciler struct {
 	client.Client
 	Scheme             *runtime.Scheme
+	Recorder           record.EventRecorder
+	Tracker            *DrainTracker
 	ConfigMapName      string
 	ConfigMapNamespace string
+	// PodNamespace is the controller's own namespace (downward API POD_NAMESPACE), searched
+	// for the ConfigMap when it is missing from ConfigMapNamespace
+	PodNamespace string
+	// FinalizerName lets several controller instances coexist; defaults to VPAGracefulDrainFinalizer
+	FinalizerName string
+	// WebhookClient sends drain-complete callbacks; defaults to an http.Client with a short timeout
+	WebhookClient HTTPDoer
+	// ConnTracker counts established connections in conntrack mode; defaults to an
+	// HTTPAgent built from the configured connTrackerEndpoint
+	ConnTracker conntrack.ConnTracker
+	// GRPCHealth checks the pod's gRPC health service in grpc-health mode; defaults to a
+	// client dialing the configured grpcHealthPort
+	GRPCHealth grpchealth.HealthChecker
+	// Clock drives drain timing; defaults to the wall clock
+	Clock finalizer.Clock
+	// Rand drives requeue jitter; defaults to a time-seeded source. Set a seeded source in tests.
+	Rand *rand.Rand
+	// EndpointBreaker short-circuits endpoint checks while the Endpoints API keeps failing;
+	// defaults to a breaker shared by all reconciles of this reconciler
+	EndpointBreaker *finalizer.CircuitBreaker
+	// TrafficWeights reports how much traffic a pod receives for trafficWeightThreshold;
+	// defaults to reading the traffic-weight annotation
+	TrafficWeights finalizer.TrafficWeightProvider
+	// ReplicaID identifies this replica when several run without leader election. When set,
+	// a draining pod is claimed through DrainClaimAnnotation before it is processed.
+	ReplicaID string
+	// DrainClaimLease is how long a claim keeps other replicas away; defaults to one minute
+	DrainClaimLease time.Duration
+	// DrainGate holds drains until something external reports the pod drained; defaults to
+	// the custom resource configured with drainGateKind, if any
+	DrainGate finalizer.ExternalDrainGate
+	// TargetHealth describes AWS target groups for awsTargetGroupCheck, e.g. an elbv2.Client.
+	// Without it the check is skipped.
+	TargetHealth finalizer.TargetHealthDescriber
+	// DrainPolicies resolves the DrainPolicy resources that take precedence over the
+	// ConfigMaps; without it only the ConfigMaps are read
+	DrainPolicies *DrainPolicyResolver
+	// GracePeriodOnly skips endpoint checks so drains complete after the grace period, for
+	// when PreflightEndpointAccess finds the controller can't list services or endpoints
+	GracePeriodOnly bool
+	// ConfigBounds limits the configured grace period and drain timeout; defaults to
+	// DefaultConfigBounds
+	ConfigBounds *ConfigBounds
+	// ConfigDefaults are the settings a configuration starts from before the ConfigMaps are
+	// applied; defaults to NewDefaultConfigOptions
+	ConfigDefaults *DefaultConfigOptions
+	// ConfigErrorRequeue is how long to wait before retrying a pod whose configuration could
+	// not be read; defaults to DefaultConfigErrorRequeue
+	ConfigErrorRequeue time.Duration
+	// CleanupFinalizersOnShutdown removes our finalizer from non-draining pods when the
+	// manager shuts down, for uninstalling the controller
+	CleanupFinalizersOnShutdown bool
+	// BackfillFinalizersOnStartup adds our finalizer to existing managed pods once the cache
+	// has synced, for enabling the controller on a cluster that already runs workloads
+	BackfillFinalizersOnStartup bool
+
+	randMu      sync.Mutex
+	breakerOnce sync.Once
+	// serviceIndexed is set once SetupWithManager has registered the service selector index
+	serviceIndexed bool
+	// waitingLogs throttles the per-reconcile "still waiting" line of draining pods
+	waitingLogs     *LogThrottle
+	waitingLogsOnce sync.Once
+	// pollBackoff stretches the requeue interval of pods whose connections persist
+	pollBackoff     *RequeueBackoff
+	pollBackoffOnce sync.Once
+	// selectionVerdicts caches, per namespace, how the configuration selects pods so
+	// obviously unmanaged pods skip the configuration read
+	selectionVerdicts sync.Map
+	// targetHealthWarning limits the missing-TargetHealth warning to once per reconciler
+	targetHealthWarning sync.Once
+	// forbiddenWarning limits the drain handlers' missing-RBAC warning to once per reconciler
+	forbiddenWarning sync.Once
+	// configSource remembers where the global config was last loaded from, so changes are logged once
+	configSource atomic.Value
+	// ignoredOverrides remembers, per namespace, the namespace ConfigMap keys last ignored,
+	// so each change is logged once
+	ignoredOverrides sync.Map
+}
+
+// DefaultConfigErrorRequeue is the retry delay after failing to read the configuration
+const DefaultConfigErrorRequeue = 5 * time.Minute
+
+func (r *PodReconciler) configErrorRequeue() time.Duration {
+	if r.ConfigErrorRequeue <= 0 {
+		return DefaultConfigErrorRequeue
+	}
+	return r.ConfigErrorRequeue
+}
+
+func (r *PodReconciler) configBounds() ConfigBounds {
+	if r.ConfigBounds == nil {
+		return DefaultConfigBounds()
+	}
+	return *r.ConfigBounds
+}
+
+func (r *PodReconciler) configDefaults() []DefaultConfigOption {
+	var opts []DefaultConfigOption
+	if r.ConfigDefaults != nil {
+		opts = append(opts, WithDefaults(*r.ConfigDefaults))
+	}
+	return append(opts, WithControllerNamespace(r.PodNamespace))
+}
+
+func (r *PodReconciler) finalizerName() string {
+	if r.FinalizerName == "" {
+		return VPAGracefulDrainFinalizer
+	}
+	return r.FinalizerName
+}
+
+// similarFinalizers returns the pod's finalizers that share the domain or the name of ours
+// without being ours, such as those of another instance of this controller
+func similarFinalizers(pod *corev1.Pod, ours string) []string {
+	domain, name, _ := strings.Cut(ours, "/")
+	var similar []string
+	for _, f := range pod.Finalizers {
+		if f == ours {
+			continue
+		}
+		fDomain, fName, _ := strings.Cut(f, "/")
+		if fDomain == domain || fName == name {
+			similar = append(similar, f)
+		}
+	}
+	return similar
+}
+
+func (r *PodReconciler) clock() finalizer.Clock {
+	if r.Clock == nil {
+		return finalizer.RealClock{}
+	}
+	return r.Clock
+}
+
+func (r *PodReconciler) connTracker(config *Config) conntrack.ConnTracker {
+	if r.ConnTracker == nil {
+		return conntrack.NewHTTPAgent(config.ConnTrackerEndpoint, nil)
+	}
+	return r.ConnTracker
+}
+
+func (r *PodReconciler) grpcHealthChecker(config *Config) grpchealth.HealthChecker {
+	if r.GRPCHealth == nil {
+		return grpchealth.NewClient(config.GRPCHealthPort, config.GRPCHealthService)
+	}
+	return r.GRPCHealth
+}
+
+func (r *PodReconciler) endpointBreaker() *finalizer.CircuitBreaker {
+	r.breakerOnce.Do(func() {
+		if r.EndpointBreaker == nil {
+			r.EndpointBreaker = finalizer.NewCircuitBreaker(
+				endpointBreakerFailureThreshold, endpointBreakerWindow, endpointBreakerCooldown).
+				WithClock(r.clock()).
+				WithStateChangeHook(func(state finalizer.CircuitState) {
+					metrics.EndpointCircuitTransitionsTotal.WithLabelValues(string(state)).Inc()
+				})
+		}
+	})
+	return r.EndpointBreaker
+}
+
+func (r *PodReconciler) waitingLogThrottle() *LogThrottle {
+	r.waitingLogsOnce.Do(func() {
+		r.waitingLogs = NewLogThrottle(NewDefaultConfig().GetWaitingLogInterval())
+	})
+	return r.waitingLogs
+}
+
+func (r *PodReconciler) connectionPollBackoff() *RequeueBackoff {
+	r.pollBackoffOnce.Do(func() {
+		r.pollBackoff = NewRequeueBackoff()
+	})
+	return r.pollBackoff
+}
+
+func (r *PodReconciler) drainGate(ctx context.Context, config *Config) finalizer.ExternalDrainGate {
+	var gates finalizer.AllDrainGates
+	if r.DrainGate != nil {
+		gates = append(gates, r.DrainGate)
+	} else if config.DrainGateKind != "" {
+		gates = append(gates, &finalizer.CustomResourceDrainGate{
+			Reader:    r.Client,
+			GVK:       config.DrainGateGVK(),
+			FieldPath: config.DrainGateFieldPath,
+		})
+	}
+	if config.AWSTargetGroupCheck {
+		if r.TargetHealth != nil {
+			gates = append(gates, &finalizer.TargetGroupDrainGate{Reader: r.Client, Targets: r.TargetHealth})
+		} else {
+			r.targetHealthWarning.Do(func() {
+				log.FromContext(ctx).Info("WARNING: awsTargetGroupCheck is enabled but no target health client is configured " +
+					"(see --aws-target-health), ignoring it")
+			})
+		}
+	}
+	if config.PreventLastReplicaDrain {
+		gates = append(gates, &finalizer.LastReadyReplicaGate{Reader: r.Client})
+	}
+	if config.PreserveZoneAvailability {
+		gates = append(gates, &finalizer.ZoneAvailabilityGate{Reader: r.Client})
+	}
+
+	switch len(gates) {
+	case 0:
+		return nil
+	case 1:
+		return gates[0]
+	default:
+		return gates
+	}
+}
+
+// jitter randomizes d by up to ±requeueJitterFraction so pods deleted at the same
+// instant don't requeue in lockstep
+func (r *PodReconciler) jitter(d time.Duration) time.Duration {
+	r.randMu.Lock()
+	defer r.randMu.Unlock()
+
+	if r.Rand == nil {
+		r.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	factor := 1 + requeueJitterFraction*(2*r.Rand.Float64()-1)
+	return time.Duration(float64(d) * factor)
 }
 
 func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -35,41 +288,66 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	if err := r.Get(ctx, req.NamespacedName, &pod); err != nil {
 		if errors.IsNotFound(err) {
 			logger.Info("Pod not found. Ignoring since object must be deleted")
+			if entry, tracked := r.Tracker.Get(req.NamespacedName); tracked {
+				r.waitingLogThrottle().Forget(entry.UID)
+				r.connectionPollBackoff().Reset(entry.UID)
+			}
+			r.Tracker.Untrack(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "Failed to get Pod")
 		return ctrl.Result{}, err
 	}
 
-	config, err := r.getConfig(ctx)
+	// A pod recreated under the same name is a new pod; drop the previous generation's drain
+	if entry, tracked := r.Tracker.Get(req.NamespacedName); tracked && entry.UID != pod.UID {
+		logger.Info("Pod was recreated with the same name, discarding stale drain state",
+			"pod", pod.Name, "previousUID", entry.UID, "uid", pod.UID)
+		r.Tracker.Untrack(req.NamespacedName)
+	}
+
+	if r.obviouslyUnmanaged(&pod) {
+		return ctrl.Result{}, nil
+	}
+
+	config, err := r.getConfig(ctx, pod.Namespace)
 	if err != nil {
 		logger.Error(err, "Failed to get configuration")
-		return ctrl.Result{RequeueAfter: time.Minute * 5}, err
+		return r.holdWithoutConfig(ctx, &pod, err)
+	}
+	r.rememberSelection(pod.Namespace, config)
+
+	if !config.Enabled {
+		return r.releasePod(ctx, &pod, config, finalizer.CompletionReasonDisabled, "Graceful drain is disabled, removing finalizer")
+	}
+
+	if r.namespacePaused(ctx, pod.Namespace) {
+		return r.releasePod(ctx, &pod, config, finalizer.CompletionReasonNamespacePaused,
+			"Graceful drain is paused for the namespace, removing finalizer")
 	}
 
 	if !r.shouldManagePod(&pod, config) {
-		logger.V(1).Info("Pod is not managed by VPA graceful drain controller")
-		return ctrl.Result{}, nil
+		// The pod stopped qualifying (e.g. annotated vpa-managed: "false") after we added the
+		// finalizer; drop it now rather than hold a drain nobody wants on deletion
+		if pod.DeletionTimestamp == nil {
+			return r.releasePod(ctx, &pod, config, finalizer.CompletionReasonUnmanaged, "Pod is no longer managed, removing finalizer")
+		}
+		// A drain that already started is finished, so the pod never stays stuck terminating
+		if !controllerutil.ContainsFinalizer(&pod, r.finalizerName()) {
+			logger.V(1).Info("Pod is not managed by VPA graceful drain controller")
+			return ctrl.Result{}, nil
+		}
 	}
 
 	if pod.DeletionTimestamp != nil {
-		logger.Info("Pod is being deleted, handling graceful drain", "pod", pod.Name, "namespace", pod.Namespace)
+		logger.V(1).Info("Pod is being deleted, handling graceful drain", "pod", pod.Name, "namespace", pod.Namespace)
 		return r.handlePodDeletion(ctx, &pod, config)
 	}
 
 	if r.shouldAddFinalizer(&pod) {
 		logger.Info("Adding VPA graceful drain finalizer to pod", "pod", pod.Name, "namespace", pod.Namespace)
 
-		// Create a copy to avoid modifying the cache
-		podCopy := pod.DeepCopy()
-		controllerutil.AddFinalizer(podCopy, VPAGracefulDrainFinalizer)
-
-		if err := r.Update(ctx, podCopy); err != nil {
-			if errors.IsConflict(err) {
-				// Conflict error means the resource was modified, retry
-				logger.V(1).Info("Conflict updating pod, will retry", "pod", pod.Name)
-				return ctrl.Result{RequeueAfter: time.Millisecond * 100}, nil
-			}
+		if err := r.addFinalizer(ctx, &pod, config); err != nil {
 			logger.Error(err, "Failed to add finalizer to pod")
 			return ctrl.Result{}, err
 		}
@@ -79,56 +357,435 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	return ctrl.Result{}, nil
 }
 
+// releasePod removes our finalizer from a pod we should no longer hold, because the
+// controller is disabled through the ConfigMap, its namespace is paused or the pod stopped
+// being managed. A pod being deleted completes its drain immediately with the given reason.
+func (r *PodReconciler) releasePod(ctx context.Context, pod *corev1.Pod, config *Config, reason, message string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(pod, r.finalizerName()) {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info(message, "pod", pod.Name, "namespace", pod.Namespace)
+
+	podCopy := pod.DeepCopy()
+	controllerutil.RemoveFinalizer(podCopy, r.finalizerName())
+	delete(podCopy.Annotations, DrainClaimAnnotation)
+	if err := r.writeFinalizers(ctx, pod, podCopy, config); err != nil {
+		if errors.IsConflict(err) {
+			logger.V(1).Info("Conflict removing finalizer, will retry", "pod", pod.Name)
+			return ctrl.Result{RequeueAfter: r.jitter(time.Millisecond * 100)}, nil
+		}
+		logger.Error(err, "Failed to remove finalizer from pod")
+		return ctrl.Result{}, err
+	}
+
+	if pod.DeletionTimestamp != nil {
+		r.Tracker.Untrack(client.ObjectKeyFromObject(pod))
+		r.waitingLogThrottle().Forget(pod.UID)
+		r.connectionPollBackoff().Reset(pod.UID)
+		r.recordDrainCompleted(ctx, pod, config, reason)
+	}
+	return ctrl.Result{}, nil
+}
+
+// holdWithoutConfig retries a pod whose configuration can't be read. A deleting pod is still
+// released once it is past the longest hard deadline any configuration could give it, so a
+// broken ConfigMap or DrainPolicy never holds it forever.
+func (r *PodReconciler) holdWithoutConfig(ctx context.Context, pod *corev1.Pod, configErr error) (ctrl.Result, error) {
+	requeueAfter := r.configErrorRequeue()
+	if pod.DeletionTimestamp == nil || !controllerutil.ContainsFinalizer(pod, r.finalizerName()) {
+		return ctrl.Result{RequeueAfter: requeueAfter}, configErr
+	}
+
+	defaults := NewDefaultConfig(r.configDefaults()...)
+	hardDeadline := time.Duration(r.configBounds().MaxDrainTimeoutSeconds)*time.Second + defaults.GetHardDeadlineBuffer()
+	elapsed := r.clock().Now().Sub(pod.DeletionTimestamp.Time)
+	if elapsed > hardDeadline {
+		metrics.HardTimeoutTotal.Inc()
+		return r.releasePod(ctx, pod, defaults, finalizer.CompletionReasonHardTimeout,
+			"Drain exceeded the hard deadline while the configuration is unreadable, removing finalizer")
+	}
+	return ctrl.Result{RequeueAfter: min(requeueAfter, hardDeadline-elapsed+time.Second)}, configErr
+}
+
+// writeFinalizers persists the finalizer change made to modified, either as a merge patch
+// against original or as a full update, depending on finalizerUpdateStrategy, and records
+// the outcome in the finalizer metrics
+func (r *PodReconciler) writeFinalizers(ctx context.Context, original, modified *corev1.Pod, config *Config) error {
+	var err error
+	if config.FinalizerUpdateStrategy == FinalizerUpdateStrategyPatch {
+		err = r.Patch(ctx, modified, client.MergeFrom(original))
+	} else {
+		err = r.Update(ctx, modified)
+	}
+
+	operation, counter := metrics.OperationRemoveFinalizer, metrics.FinalizerRemovedTotal
+	if controllerutil.ContainsFinalizer(modified, r.finalizerName()) {
+		operation, counter = metrics.OperationAddFinalizer, metrics.FinalizerAddedTotal
+	}
+	switch {
+	case err == nil:
+		counter.Inc()
+	case errors.IsConflict(err):
+		metrics.UpdateErrorsTotal.WithLabelValues(operation, metrics.UpdateErrorReasonConflict).Inc()
+	default:
+		metrics.UpdateErrorsTotal.WithLabelValues(operation, metrics.UpdateErrorReasonOther).Inc()
+	}
+	return err
+}
+
+// evaluateDrain completes the drain at once when the pod's namespace is being deleted,
+// so our finalizer doesn't block namespace termination, and otherwise defers to the drain handler
+func (r *PodReconciler) evaluateDrain(ctx context.Context, pod *corev1.Pod, config *Config, drainHandler *finalizer.DrainHandler) (finalizer.DrainResult, error) {
+	if !config.BlockNamespaceTermination {
+		var namespace corev1.Namespace
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Namespace}, &namespace); err != nil {
+			// Not knowing the namespace state is no reason to cut the drain short
+			log.FromContext(ctx).V(1).Info("Failed to get pod namespace", "namespace", pod.Namespace, "error", err.Error())
+		} else if namespace.DeletionTimestamp != nil {
+			r.Recorder.Event(pod, corev1.EventTypeNormal, "NamespaceTerminating",
+				"Namespace "+pod.Namespace+" is being deleted, completing graceful drain immediately")
+			return finalizer.DrainResult{
+				Completed: true,
+				Reason:    finalizer.CompletionReasonNamespaceTerminating,
+				Elapsed:   r.clock().Now().Sub(pod.DeletionTimestamp.Time),
+				Phase:     finalizer.DrainPhaseCompleted,
+			}, nil
+		}
+	}
+
+	return drainHandler.HandleGracefulDrain(ctx, pod)
+}
+
+// addFinalizer adds the drain finalizer, re-reading the pod and retrying on conflicts.
+// Other errors are returned so the workqueue retries with backoff.
+func (r *PodReconciler) addFinalizer(ctx context.Context, pod *corev1.Pod, config *Config) error {
+	logger := log.FromContext(ctx)
+
+	current := pod
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if current == nil {
+			current = &corev1.Pod{}
+			if err := r.Get(ctx, client.ObjectKeyFromObject(pod), current); err != nil {
+				return err
+			}
+		}
+
+		// The pod may have started terminating since it was last read
+		if !r.shouldAddFinalizer(current) {
+			return nil
+		}
+
+		// Create a copy to avoid modifying the cache
+		original := current
+		podCopy := current.DeepCopy()
+		current = nil
+		controllerutil.AddFinalizer(podCopy, r.finalizerName())
+
+		err := r.writeFinalizers(ctx, original, podCopy, config)
+		if errors.IsConflict(err) {
+			logger.V(1).Info("Conflict updating pod, will retry", "pod", pod.Name)
+		}
+		return err
+	})
+}
+
 func (r *PodReconciler) handlePodDeletion(ctx context.Context, pod *corev1.Pod, config *Config) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
-	if !controllerutil.ContainsFinalizer(pod, VPAGracefulDrainFinalizer) {
+	if !controllerutil.ContainsFinalizer(pod, r.finalizerName()) {
 		logger.V(1).Info("Pod does not have VPA graceful drain finalizer, skipping")
 		return ctrl.Result{}, nil
 	}
 
-	drainHandler := finalizer.NewDrainHandler(r.Client, config)
+	if r.ReplicaID != "" {
+		claimed, remaining, err := r.claimDrain(ctx, pod)
+		if err != nil {
+			logger.Error(err, "Failed to claim pod for draining")
+			return ctrl.Result{RequeueAfter: r.jitter(time.Second * 30)}, err
+		}
+		if !claimed {
+			logger.V(1).Info("Pod is being drained by another replica, skipping", "pod", pod.Name)
+			return ctrl.Result{RequeueAfter: r.jitter(remaining)}, nil
+		}
+	}
+
+	drainHandler := finalizer.NewDrainHandler(r.Client, config).
+		WithClock(r.clock()).
+		WithForbiddenWarningOnce(&r.forbiddenWarning)
+	switch config.ConnectionCheckMode {
+	case finalizer.ConnectionCheckModeConntrack:
+		drainHandler.WithConnTracker(r.connTracker(config))
+	case finalizer.ConnectionCheckModeGRPCHealth:
+		drainHandler.WithGRPCHealthChecker(r.grpcHealthChecker(config))
+	default:
+		drainHandler.WithEndpointBreaker(r.endpointBreaker())
+	}
+	if r.GracePeriodOnly {
+		drainHandler.WithoutEndpointCheck()
+	}
+	if r.TrafficWeights != nil {
+		drainHandler.WithTrafficWeightProvider(r.TrafficWeights)
+	}
+	if r.serviceIndexed {
+		drainHandler.WithServiceSelectorIndex()
+	}
+	if gate := r.drainGate(ctx, config); gate != nil {
+		drainHandler.WithDrainGate(gate)
+	}
+	drainStatus := drainHandler.DrainStatus(ctx, pod)
+	r.Tracker.Track(pod, drainStatus.Phase)
 
-	completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+	result, err := r.evaluateDrain(ctx, pod, config, drainHandler)
 	if err != nil {
 		logger.Error(err, "Failed to handle graceful drain")
-		return ctrl.Result{RequeueAfter: time.Second * 30}, err
+		return ctrl.Result{RequeueAfter: r.jitter(time.Second * 30)}, err
+	}
+
+	drainStatus.Phase = r.transitionDrainPhase(ctx, pod, result.Phase)
+	if !result.Completed {
+		r.Tracker.Track(pod, drainStatus.Phase)
+		drainStatus.EndpointServices = drainHandler.EndpointServices()
+		drainTimeout := time.Duration(drainStatus.DeadlineSeconds) * time.Second
+		if err := r.recordDrainSlow(ctx, pod, config, result.Elapsed, drainTimeout); err != nil {
+			// Only the alert relies on it; it is retried on the next reconcile
+			logger.V(1).Info("Failed to record slow drain", "pod", pod.Name, "error", err.Error())
+		}
+		if err := r.updateDrainStatus(ctx, pod, drainStatus); err != nil {
+			// Status is informational only, so keep draining
+			logger.V(1).Info("Failed to update drain status annotation", "pod", pod.Name, "error", err.Error())
+		}
+		requeueAfter := result.RequeueAfter
+		if result.HadActiveConnections {
+			requeueAfter = r.connectionPollBackoff().Next(pod.UID, requeueAfter, config.GetMaxPollInterval())
+			// Backing off must not push the check past the drain timeout
+			untilTimeout := drainTimeout - result.Elapsed
+			if requeueAfter > untilTimeout {
+				requeueAfter = max(untilTimeout, result.RequeueAfter)
+			}
+		} else {
+			r.connectionPollBackoff().Reset(pod.UID)
+		}
+		// Land a reconcile on the slow-drain threshold so the alert isn't skipped over
+		if untilSlow := untilDrainSlow(pod, config, result.Elapsed, drainTimeout); untilSlow > 0 && untilSlow < requeueAfter {
+			requeueAfter = untilSlow
+		}
+		// Phase changes always get through so transitions stay visible
+		waitingLogs := r.waitingLogThrottle()
+		waitingLogs.SetInterval(config.GetWaitingLogInterval())
+		if waitingLogs.Allow(pod.UID, string(drainStatus.Phase), r.clock().Now()) {
+			logger.Info("Graceful drain not yet completed, requeuing",
+				"pod", pod.Name, "phase", drainStatus.Phase, "requeueAfter", requeueAfter,
+				"activeConnections", result.HadActiveConnections)
+		}
+		return ctrl.Result{RequeueAfter: r.jitter(requeueAfter)}, nil
+	}
+
+	// The hard deadline, operator overrides, force deletes and namespace teardown must never be held back by ordering
+	if result.Reason != finalizer.CompletionReasonHardTimeout && result.Reason != finalizer.CompletionReasonForceCompleted &&
+		result.Reason != finalizer.CompletionReasonForceDeleted && result.Reason != finalizer.CompletionReasonNamespaceTerminating {
+		blockedBy, err := r.drainBlockedBy(ctx, pod)
+		if err != nil {
+			logger.Error(err, "Failed to check drain priority")
+			return ctrl.Result{RequeueAfter: r.jitter(time.Second * 30)}, err
+		}
+		if blockedBy != "" {
+			if err := r.recordConnectionsCleared(ctx, pod); err != nil {
+				// Only the completion summary relies on it
+				logger.V(1).Info("Failed to record drain completion time", "pod", pod.Name, "error", err.Error())
+			}
+			// Persist the terminal phase so waiting on the sibling doesn't log the transition again
+			if err := r.updateDrainStatus(ctx, pod, drainStatus); err != nil {
+				logger.V(1).Info("Failed to update drain status annotation", "pod", pod.Name, "error", err.Error())
+			}
+			logger.Info("Drain completed but waiting for a sibling with a lower drain priority", "pod", pod.Name, "sibling", blockedBy)
+			return ctrl.Result{RequeueAfter: r.jitter(time.Second * 10)}, nil
+		}
 	}
 
-	if !completed {
-		logger.Info("Graceful drain not yet completed, requeuing", "pod", pod.Name)
-		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	if result.Reason == finalizer.CompletionReasonForceCompleted {
+		r.Recorder.Event(pod, corev1.EventTypeWarning, "ForceCompleted",
+			"Graceful drain was force-completed via the "+finalizer.ForceCompleteAnnotation+" annotation")
 	}
 
-	logger.Info("Graceful drain completed, removing finalizer", "pod", pod.Name)
+	if config.DrainCompleteWebhookURL != "" {
+		// Best effort: a failed callback must never keep the pod from being deleted.
+		// Callbacks are repeated if the finalizer removal below has to be retried.
+		if err := r.notifyDrainComplete(ctx, config.DrainCompleteWebhookURL, pod); err != nil {
+			logger.Error(err, "Failed to send drain complete webhook", "pod", pod.Name)
+		}
+	}
+
+	// Only our exact finalizer is removed; a look-alike usually means another instance of
+	// the controller is configured with a different finalizer name and drains the pod too
+	if similar := similarFinalizers(pod, r.finalizerName()); len(similar) > 0 {
+		logger.Info("Pod carries finalizers similar to ours, leaving them in place",
+			"pod", pod.Name, "finalizer", r.finalizerName(), "similar", similar)
+	}
 
 	// Create a copy to avoid modifying the cache
 	podCopy := pod.DeepCopy()
-	controllerutil.RemoveFinalizer(podCopy, VPAGracefulDrainFinalizer)
+	controllerutil.RemoveFinalizer(podCopy, r.finalizerName())
+	delete(podCopy.Annotations, DrainClaimAnnotation)
+	delete(podCopy.Annotations, ConnectionsClearedAnnotation)
+	delete(podCopy.Annotations, DrainSlowAnnotation)
 
-	if err := r.Update(ctx, podCopy); err != nil {
+	if err := r.writeFinalizers(ctx, pod, podCopy, config); err != nil {
 		if errors.IsConflict(err) {
 			// Conflict error means the resource was modified, retry
 			logger.V(1).Info("Conflict removing finalizer, will retry", "pod", pod.Name)
-			return ctrl.Result{RequeueAfter: time.Millisecond * 100}, nil
+			return ctrl.Result{RequeueAfter: r.jitter(time.Millisecond * 100)}, nil
 		}
 		logger.Error(err, "Failed to remove finalizer from pod")
 		return ctrl.Result{}, err
 	}
 
+	summary := summarizeDrain(pod, drainStatus, result.Reason, r.clock().Now())
+	logger.Info("Graceful drain completed, removed finalizer",
+		append([]interface{}{"pod", pod.Name, "namespace", pod.Namespace}, summary.keysAndValues()...)...)
+
+	r.Tracker.Untrack(client.ObjectKeyFromObject(pod))
+	r.waitingLogThrottle().Forget(pod.UID)
+	r.connectionPollBackoff().Reset(pod.UID)
+	r.recordDrainCompleted(ctx, pod, config, result.Reason)
+
 	return ctrl.Result{}, nil
 }
 
+// updateDrainStatus patches the status annotation, skipping the API call when nothing changed,
+// and updates pod to the patched version
+func (r *PodReconciler) updateDrainStatus(ctx context.Context, pod *corev1.Pod, status finalizer.DrainStatus) error {
+	value, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	if pod.Annotations[finalizer.StatusAnnotation] == string(value) {
+		return nil
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = map[string]string{}
+	}
+	podCopy.Annotations[finalizer.StatusAnnotation] = string(value)
+	if err := r.Patch(ctx, podCopy, client.MergeFrom(pod)); err != nil {
+		return err
+	}
+
+	*pod = *podCopy
+	return nil
+}
+
+// transitionDrainPhase moves the pod from the phase recorded in its status annotation to
+// the phase just computed for it, logging and counting the transition if the phase changed
+func (r *PodReconciler) transitionDrainPhase(ctx context.Context, pod *corev1.Pod, computed finalizer.DrainPhase) finalizer.DrainPhase {
+	previous := drainPhaseFromAnnotation(pod)
+	if previous == "" {
+		previous = finalizer.DrainPhasePending
+	}
+
+	phase, changed := previous.Transition(computed)
+	if changed {
+		log.FromContext(ctx).Info("Drain phase changed", "pod", pod.Name, "from", previous, "to", phase)
+		metrics.DrainPhaseTransitionsTotal.WithLabelValues(string(previous), string(phase)).Inc()
+	}
+	return phase
+}
+
+// drainPhaseFromAnnotation returns the last phase recorded in the status annotation, if any
+func drainPhaseFromAnnotation(pod *corev1.Pod) finalizer.DrainPhase {
+	var status finalizer.DrainStatus
+	if err := json.Unmarshal([]byte(pod.Annotations[finalizer.StatusAnnotation]), &status); err != nil {
+		return ""
+	}
+	return status.Phase
+}
+
+// RebuildStateFromCluster re-registers every pod that is still held by our finalizer,
+// restoring in-memory drain tracking lost on restart or leadership change
+func (r *PodReconciler) RebuildStateFromCluster(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	rebuilt := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp == nil || !controllerutil.ContainsFinalizer(pod, r.finalizerName()) {
+			continue
+		}
+		r.Tracker.Track(pod, drainPhaseFromAnnotation(pod))
+		rebuilt++
+	}
+
+	logger.Info("Rebuilt drain state from cluster", "drainingPods", rebuilt)
+	return nil
+}
+
 func (r *PodReconciler) shouldManagePod(pod *corev1.Pod, config *Config) bool {
+	return r.managementReason(pod, config) != ""
+}
+
+// managementReason reports why the pod is managed, or "" if it isn't
+func (r *PodReconciler) managementReason(pod *corev1.Pod, config *Config) ManagementReason {
+	// Static pods are owned by the kubelet; holding their mirror pods only blocks node drains
+	if _, isMirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; isMirror {
+		return ""
+	}
+
+	// Named exclusions win over every other signal
+	if config.ExcludesPodName(pod.Name) {
+		return ""
+	}
+
+	if !config.ManageDaemonSetPods && isOwnedBy(pod, "DaemonSet") {
+		return ""
+	}
+
+	// Job pods don't serve traffic and holding them only delays Job cleanup
+	if !config.ManageJobPods && isJobPod(pod) {
+		return ""
+	}
+
+	// System namespaces are skipped even when the namespace selector includes them
+	if config.IsSystemNamespace(pod.Namespace) {
+		return ""
+	}
+
 	// Check namespace selector first
 	if config.NamespaceSelector != nil && !config.NamespaceSelector.Matches(pod.Namespace) {
-		return false
+		return ""
+	}
+
+	// A configured CEL expression replaces the annotation and label heuristics.
+	// Evaluation errors (e.g. a missing map key) are treated as "not managed".
+	if config.HasManagedExpression() {
+		if managed, err := config.EvaluateManagedExpression(pod); err == nil && managed {
+			return ManagementReasonExpression
+		}
+		return ""
 	}
 
 	// Primary check: Look for explicit vpa-managed annotation
 	if pod.Annotations != nil {
 		if vpaManaged, exists := pod.Annotations["vpa-managed"]; exists {
-			return vpaManaged == "true"
+			if vpaManaged == "true" {
+				return ManagementReasonAnnotation
+			}
+			return ""
+		}
+	}
+
+	// Annotations injected by other tooling (e.g. sidecar.istio.io/status) opt pods in
+	for _, key := range config.ManageIfAnnotations {
+		if _, exists := pod.Annotations[key]; exists {
+			return ManagementReasonManageIfAnnotation
 		}
 	}
 
@@ -136,12 +793,12 @@ func (r *PodReconciler) shouldManagePod(pod *corev1.Pod, config *Config) bool {
 	if pod.Annotations != nil {
 		// VPA updater adds this annotation when it creates a new pod
 		if _, hasVPAAnnotation := pod.Annotations["vpa-updater.client.k8s.io/last-updated"]; hasVPAAnnotation {
-			return true
+			return ManagementReasonVPAAnnotation
 		}
 
 		// Alternative: check for VPA resource name annotation
 		if vpaName, hasVPAResourceAnnotation := pod.Annotations["vpa.k8s.io/resource-name"]; hasVPAResourceAnnotation && vpaName != "" {
-			return true
+			return ManagementReasonVPAAnnotation
 		}
 	}
 
@@ -149,20 +806,20 @@ func (r *PodReconciler) shouldManagePod(pod *corev1.Pod, config *Config) bool {
 	if pod.Labels != nil {
 		// VPA might add labels to identify managed pods
 		if _, hasVPALabel := pod.Labels["vpa.k8s.io/managed"]; hasVPALabel {
-			return true
+			return ManagementReasonLabel
 		}
 	}
 
 	// Check if pod's owner is a Deployment/ReplicaSet that might be managed by VPA
 	// This is a more heuristic approach - look for specific patterns
-	if r.isPodFromVPAManagedWorkload(pod) {
-		return true
+	if !config.DisableResourceHeuristic && r.isPodFromVPAManagedWorkload(pod, config) {
+		return ManagementReasonHeuristic
 	}
 
-	return false
+	return ""
 }
 
-func (r *PodReconciler) isPodFromVPAManagedWorkload(pod *corev1.Pod) bool {
+func (r *PodReconciler) isPodFromVPAManagedWorkload(pod *corev1.Pod, config *Config) bool {
 	// Check if pod has owner references
 	if len(pod.OwnerReferences) == 0 {
 		return false
@@ -176,7 +833,7 @@ func (r *PodReconciler) isPodFromVPAManagedWorkload(pod *corev1.Pod) bool {
 			if cpu := container.Resources.Requests.Cpu(); cpu != nil {
 				// VPA often sets precise values like "25m" or "152m"
 				cpuMillis := cpu.MilliValue()
-				if cpuMillis > 0 && cpuMillis%100 != 0 && cpuMillis%50 != 0 {
+				if cpuMillis > 0 && !divisibleByAny(cpuMillis, config.HeuristicCPUModulos) {
 					return true
 				}
 			}
@@ -185,8 +842,9 @@ func (r *PodReconciler) isPodFromVPAManagedWorkload(pod *corev1.Pod) bool {
 			if memory := container.Resources.Requests.Memory(); memory != nil {
 				// VPA often sets precise values that aren't round numbers
 				memoryBytes := memory.Value()
-				// Check if it's not a round number in MB
-				if memoryBytes > 0 && (memoryBytes%(1024*1024) != 0) {
+				// Check if it's not aligned to a round size (1Mi by default)
+				if memoryBytes > 0 && config.HeuristicMemoryAlignmentBytes > 0 &&
+					memoryBytes%config.HeuristicMemoryAlignmentBytes != 0 {
 					return true
 				}
 			}
@@ -196,57 +854,287 @@ func (r *PodReconciler) isPodFromVPAManagedWorkload(pod *corev1.Pod) bool {
 	return false
 }
 
+// divisibleByAny reports whether value is a multiple of one of the modulos. An empty
+// list counts every value as round, which turns the CPU check off.
+func divisibleByAny(value int64, modulos []int64) bool {
+	if len(modulos) == 0 {
+		return true
+	}
+	for _, modulo := range modulos {
+		if modulo > 0 && value%modulo == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnedBy reports whether any of the pod's owner references has the given kind
+func isOwnedBy(pod *corev1.Pod, kind string) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// isJobPod reports whether the pod belongs to a Job, including Jobs created by a CronJob.
+// The Job is always the pod's direct owner, so its owner references are enough.
+func isJobPod(pod *corev1.Pod) bool {
+	return isOwnedBy(pod, "Job") || isOwnedBy(pod, "CronJob")
+}
+
+// shouldAddFinalizer reports whether the pod still needs our finalizer. The API server
+// rejects new finalizers on terminating pods, so those are never candidates.
 func (r *PodReconciler) shouldAddFinalizer(pod *corev1.Pod) bool {
-	return !controllerutil.ContainsFinalizer(pod, VPAGracefulDrainFinalizer)
+	return pod.DeletionTimestamp == nil && !controllerutil.ContainsFinalizer(pod, r.finalizerName())
+}
+
+// getConfig loads the global ConfigMap and overlays the ConfigMap of the same name
+// in the pod's namespace, if one exists, on a field-by-field basis
+func (r *PodReconciler) getConfig(ctx context.Context, namespace string) (*Config, error) {
+	globalNamespace := r.ConfigMapNamespace
+	globalConfigMap, err := r.getConfigMap(ctx, globalNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fall back to the controller's own namespace in case the ConfigMap was moved there
+	if globalConfigMap == nil && r.PodNamespace != "" && r.PodNamespace != r.ConfigMapNamespace {
+		globalConfigMap, err = r.getConfigMap(ctx, r.PodNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if globalConfigMap != nil {
+			globalNamespace = r.PodNamespace
+		}
+	}
+
+	source := "defaults"
+	if globalConfigMap != nil {
+		source = globalNamespace + "/" + r.ConfigMapName
+	}
+	if previous, _ := r.configSource.Swap(source).(string); previous != source {
+		log.FromContext(ctx).Info("Loading global configuration", "source", source)
+	}
+
+	var namespaceConfigMap *corev1.ConfigMap
+	if namespace != "" && namespace != globalNamespace {
+		namespaceConfigMap, err = r.getConfigMap(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		var ignored []string
+		namespaceConfigMap, ignored = filterNamespaceOverrides(namespaceConfigMap)
+		r.logIgnoredOverrides(ctx, namespace, ignored)
+	}
+
+	var policy *corev1.ConfigMap
+	if r.DrainPolicies != nil && namespace != "" {
+		policy, err = r.resolveDrainPolicy(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config, err := r.parseConfig(globalConfigMap, namespaceConfigMap, policy)
+	if err != nil && namespaceConfigMap != nil {
+		// A broken namespace override must not stall the namespace's pods
+		log.FromContext(ctx).Error(err, "Ignoring invalid namespace configuration", "namespace", namespace)
+		config, err = r.parseConfig(globalConfigMap, nil, policy)
+	}
+	if err != nil && policy != nil {
+		// Neither may drain policies that combine into an invalid configuration
+		log.FromContext(ctx).Error(err, "Ignoring drain policies", "namespace", namespace, "drainPolicies", policy.Name)
+		config, err = r.parseConfig(globalConfigMap, nil, nil)
+	}
+	return config, err
+}
+
+// parseConfig layers the namespace ConfigMap and the DrainPolicy settings over the global
+// ConfigMap and parses the result; any of them may be nil
+func (r *PodReconciler) parseConfig(global, namespace, policy *corev1.ConfigMap) (*Config, error) {
+	merged := mergeConfigMaps(mergeConfigMaps(global, namespace), policy)
+	if merged == nil {
+		return NewDefaultConfig(r.configDefaults()...), nil
+	}
+
+	return ParseConfigWithBounds(merged, r.configBounds(), r.configDefaults()...)
+}
+
+// logIgnoredOverrides reports namespace ConfigMap keys that may only be set globally
+func (r *PodReconciler) logIgnoredOverrides(ctx context.Context, namespace string, ignored []string) {
+	keys := strings.Join(ignored, ",")
+	if previous, _ := r.ignoredOverrides.Swap(namespace, keys); keys != "" && previous != keys {
+		log.FromContext(ctx).Info("Ignoring namespace configuration keys that can only be set globally",
+			"namespace", namespace, "keys", ignored)
+	}
+}
+
+// resolveDrainPolicy returns the settings of the DrainPolicies matching the namespace, or nil
+func (r *PodReconciler) resolveDrainPolicy(ctx context.Context, namespace string) (*corev1.ConfigMap, error) {
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		// Selectors on the automatic name label still apply
+		ns.Name = namespace
+		ns.Labels = map[string]string{corev1.LabelMetadataName: namespace}
+	}
+	return r.DrainPolicies.Resolve(ctx, &ns)
 }
 
-func (r *PodReconciler) getConfig(ctx context.Context) (*Config, error) {
+// getConfigMap returns the controller ConfigMap in the given namespace, or nil if it does not exist
+func (r *PodReconciler) getConfigMap(ctx context.Context, namespace string) (*corev1.ConfigMap, error) {
 	var configMap corev1.ConfigMap
 	namespacedName := types.NamespacedName{
 		Name:      r.ConfigMapName,
-		Namespace: r.ConfigMapNamespace,
+		Namespace: namespace,
 	}
 
 	if err := r.Get(ctx, namespacedName, &configMap); err != nil {
 		if errors.IsNotFound(err) {
-			return NewDefaultConfig(), nil
+			return nil, nil
 		}
 		return nil, err
 	}
 
-	return ParseConfig(&configMap)
+	return &configMap, nil
 }
 
 func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Runs once this replica holds leadership and the cache has synced
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return fmt.Errorf("cache did not sync before drain state rebuild")
+		}
+		if err := r.RebuildStateFromCluster(ctx); err != nil {
+			// Not fatal: reconciles re-register draining pods as they are processed
+			log.FromContext(ctx).Error(err, "Failed to rebuild drain state from cluster")
+		}
+		return nil
+	})); err != nil {
+		return err
+	}
+
+	if r.DrainPolicies != nil {
+		if err := mgr.Add(r.DrainPolicies); err != nil {
+			return err
+		}
+	}
+
+	// Lets endpoint checks fetch only the services that may select a pod
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Service{},
+		finalizer.ServiceSelectorIndexField, finalizer.ServiceSelectorPairs); err != nil {
+		return err
+	}
+	r.serviceIndexed = true
+
+	if r.BackfillFinalizersOnStartup {
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			if !mgr.GetCache().WaitForCacheSync(ctx) {
+				return fmt.Errorf("cache did not sync before finalizer backfill")
+			}
+			if _, err := r.BackfillFinalizers(ctx); err != nil {
+				// Not fatal: pods missing the finalizer get it on their next event
+				log.FromContext(ctx).Error(err, "Finalizer backfill did not complete")
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+
+	if r.CleanupFinalizersOnShutdown {
+		// Read from the API server: the cache stops together with the manager
+		if err := mgr.Add(r.finalizerCleanupRunnable(mgr.GetAPIReader())); err != nil {
+			return err
+		}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Pod{}).
-		WithEventFilter(predicate.Or(
-			predicate.GenerationChangedPredicate{},
-			predicate.AnnotationChangedPredicate{},
-			predicate.NewPredicateFuncs(func(object client.Object) bool {
-				// Handle Pod creation events for VPA-managed pods
-				pod, ok := object.(*corev1.Pod)
-				if !ok {
-					return false
+		WithEventFilter(predicate.And(
+			ignoreDrainStatusUpdates(),
+			r.ignoreOptedOutPods(),
+			r.podEventFilter(),
+		)).
+		Complete(r)
+}
+
+// ignoreDrainStatusUpdates drops update events caused solely by our own status or claim annotations,
+// otherwise every status patch would immediately trigger another reconcile
+func ignoreDrainStatusUpdates() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(withoutDrainStatus(e.ObjectOld), withoutDrainStatus(e.ObjectNew))
+		},
+	}
+}
+
+// ignoreOptedOutPods keeps pods annotated vpa-managed: "false" out of the workqueue entirely,
+// unless they still carry our finalizer and have to be processed to release it
+func (r *PodReconciler) ignoreOptedOutPods() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(object client.Object) bool {
+		if object.GetAnnotations()["vpa-managed"] != "false" {
+			return true
+		}
+		return controllerutil.ContainsFinalizer(object, r.finalizerName())
+	})
+}
+
+func withoutDrainStatus(object client.Object) client.Object {
+	objectCopy := object.DeepCopyObject().(client.Object)
+	annotations := objectCopy.GetAnnotations()
+	delete(annotations, finalizer.StatusAnnotation)
+	delete(annotations, DrainClaimAnnotation)
+	delete(annotations, ConnectionsClearedAnnotation)
+	delete(annotations, DrainSlowAnnotation)
+	delete(annotations, finalizer.DeregisteredAtAnnotation)
+	delete(annotations, finalizer.KnativeUnroutedAtAnnotation)
+	delete(annotations, finalizer.TimeoutExtensionsAnnotation)
+	objectCopy.SetAnnotations(annotations)
+	objectCopy.SetResourceVersion("")
+	objectCopy.SetManagedFields(nil)
+	return objectCopy
+}
+
+func (r *PodReconciler) podEventFilter() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.AnnotationChangedPredicate{},
+		predicate.NewPredicateFuncs(func(object client.Object) bool {
+			// Handle Pod creation events for VPA-managed pods
+			pod, ok := object.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+
+			// Pods already carrying our finalizer must always be processed so it can be released
+			if controllerutil.ContainsFinalizer(pod, r.finalizerName()) {
+				return true
+			}
+
+			// Check if pod has vpa-managed annotation
+			if pod.Annotations != nil {
+				if vpaManaged, exists := pod.Annotations["vpa-managed"]; exists && vpaManaged == "true" {
+					return true
 				}
 
-				// Check if pod has vpa-managed annotation
-				if pod.Annotations != nil {
-					if vpaManaged, exists := pod.Annotations["vpa-managed"]; exists && vpaManaged == "true" {
-						return true
-					}
-
-					// Also check for standard VPA annotations
-					if _, hasVPA := pod.Annotations["vpa-updater.client.k8s.io/last-updated"]; hasVPA {
-						return true
-					}
-					if vpaName, hasVPAResource := pod.Annotations["vpa.k8s.io/resource-name"]; hasVPAResource && vpaName != "" {
-						return true
-					}
+				// Also check for standard VPA annotations
+				if _, hasVPA := pod.Annotations["vpa-updater.client.k8s.io/last-updated"]; hasVPA {
+					return true
+				}
+				if vpaName, hasVPAResource := pod.Annotations["vpa.k8s.io/resource-name"]; hasVPAResource && vpaName != "" {
+					return true
 				}
+			}
 
-				return false
-			}),
-		)).
-		Complete(r)
+			return false
+		}),
+	)
 }
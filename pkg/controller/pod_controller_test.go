@@ -2,24 +2,58 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"math/rand"
 	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
 )
 
-func TestPodController(t *testing.T) {
+type stubConnTracker struct {
+	established int
+}
+
+func (s *stubConnTracker) EstablishedConnections(ctx context.Context, pod *corev1.Pod) (int, error) {
+	return s.established, nil
+}
+
+// closedDrainGate never reports the pod drained
+type closedDrainGate struct{}
+
+func (closedDrainGate) IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	return false, nil
+}
+
+// emptyTargetHealth reports every target group as empty
+type emptyTargetHealth struct{}
+
+func (emptyTargetHealth) DescribeTargetHealth(ctx context.Context, targetGroupARN string) ([]finalizer.TargetHealth, error) {
+	return nil, nil
+}
+
+func TestController(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "PodController Suite")
+	RunSpecs(t, "Controller Suite")
 }
 
 var _ = Describe("PodReconciler", func() {
@@ -29,6 +63,7 @@ var _ = Describe("PodReconciler", func() {
 		fakeClient      client.Client
 		req             ctrl.Request
 		testScheme      *runtime.Scheme
+		recorder        *record.FakeRecorder
 		now             time.Time
 	)
 
@@ -39,10 +74,15 @@ var _ = Describe("PodReconciler", func() {
 		
 		now = time.Now()
 		
+		recorder = record.NewFakeRecorder(10)
+
 		reconciler = &PodReconciler{
 			Scheme:             testScheme,
+			Recorder:           recorder,
+			Tracker:            NewDrainTracker(),
 			ConfigMapName:      "test-config",
 			ConfigMapNamespace: "test-namespace",
+			Rand:               rand.New(rand.NewSource(1)),
 		}
 		
 		req = ctrl.Request{
@@ -150,210 +190,343 @@ var _ = Describe("PodReconciler", func() {
 					Build()
 				reconciler.Client = fakeClient
 
-				// Pod is being deleted but grace period hasn't elapsed
+				// Pod is being deleted but grace period hasn't elapsed, so wait it out
 				result, err := reconciler.Reconcile(ctx, req)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+				Expect(result.RequeueAfter).To(BeNumerically("~", 30*time.Second, 6*time.Second))
 			})
 		})
 
-		Context("when pod needs finalizer", func() {
-			It("should add finalizer", func() {
+		Context("when the configuration cannot be read", func() {
+			BeforeEach(func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"vpa-managed": "true",
-						},
-					},
-					Status: corev1.PodStatus{
-						Phase: corev1.PodRunning,
+						Name:        "test-pod",
+						Namespace:   "default",
+						Annotations: map[string]string{"vpa-managed": "true"},
 					},
 				}
 
-				configMap := &corev1.ConfigMap{
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					WithInterceptorFuncs(interceptor.Funcs{
+						Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+							if _, ok := obj.(*corev1.ConfigMap); ok {
+								return apierrors.NewServiceUnavailable("apiserver unavailable")
+							}
+							return c.Get(ctx, key, obj, opts...)
+						},
+					}).
+					Build()
+				reconciler.Client = fakeClient
+			})
+
+			It("should requeue after the default delay", func() {
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).To(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(DefaultConfigErrorRequeue))
+			})
+
+			It("should requeue after the configured delay", func() {
+				reconciler.ConfigErrorRequeue = 30 * time.Second
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).To(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+			})
+		})
+
+		Context("when the configuration is invalid and the pod is deleting", func() {
+			newDeletingPod := func(deletedAgo time.Duration) *corev1.Pod {
+				return &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-config",
-						Namespace: "test-namespace",
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &metav1.Time{Time: now.Add(-deletedAgo)},
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+						Annotations:       map[string]string{"vpa-managed": "true"},
 					},
-					Data: map[string]string{},
 				}
+			}
+			configMap := func() *corev1.ConfigMap {
+				return &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "test-namespace"},
+					Data:       map[string]string{"gracePeriodSeconds": "not-a-number"},
+				}
+			}
+			hardDeadline := DefaultMaxDrainTimeoutSeconds*time.Second + 60*time.Second
 
+			It("should remove the finalizer once past the longest hard deadline", func() {
 				fakeClient = fake.NewClientBuilder().
 					WithScheme(testScheme).
-					WithObjects(pod, configMap).
+					WithObjects(configMap(), newDeletingPod(hardDeadline+time.Minute)).
 					Build()
 				reconciler.Client = fakeClient
 
-				result, err := reconciler.Reconcile(ctx, req)
+				_, err := reconciler.Reconcile(ctx, req)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(result).To(Equal(ctrl.Result{}))
 
-				// Verify finalizer was added
-				updatedPod := &corev1.Pod{}
-				err = fakeClient.Get(ctx, req.NamespacedName, updatedPod)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(updatedPod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+				var updated corev1.Pod
+				Expect(fakeClient.Get(ctx, req.NamespacedName, &updated)).To(Succeed())
+				Expect(updated.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
 			})
 
-			It("should handle conflict error and retry", func() {
+			It("should keep the finalizer before the hard deadline", func() {
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(configMap(), newDeletingPod(time.Minute)).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).To(HaveOccurred())
+
+				var updated corev1.Pod
+				Expect(fakeClient.Get(ctx, req.NamespacedName, &updated)).To(Succeed())
+				Expect(updated.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			})
+		})
+
+		Context("when a pod is obviously unmanaged", func() {
+			var configReads int
+
+			BeforeEach(func() {
+				configReads = 0
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
 						Namespace: "default",
-						Annotations: map[string]string{
-							"vpa-managed": "true",
-						},
-					},
-					Status: corev1.PodStatus{
-						Phase: corev1.PodRunning,
 					},
 				}
-
 				configMap := &corev1.ConfigMap{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-config",
-						Namespace: "test-namespace",
-					},
-					Data: map[string]string{},
+					ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "test-namespace"},
+					Data:       map[string]string{"disableResourceHeuristic": "true"},
 				}
 
 				fakeClient = fake.NewClientBuilder().
 					WithScheme(testScheme).
 					WithObjects(pod, configMap).
+					WithInterceptorFuncs(interceptor.Funcs{
+						Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+							if _, ok := obj.(*corev1.ConfigMap); ok {
+								configReads++
+							}
+							return c.Get(ctx, key, obj, opts...)
+						},
+					}).
 					Build()
 				reconciler.Client = fakeClient
+			})
 
-				result, err := reconciler.Reconcile(ctx, req)
+			It("should skip the configuration read once the namespace's selection is known", func() {
+				_, err := reconciler.Reconcile(ctx, req)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(result).To(Equal(ctrl.Result{}))
+				readsAfterFirst := configReads
+				Expect(readsAfterFirst).To(BeNumerically(">", 0))
+
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(configReads).To(Equal(readsAfterFirst))
 			})
-		})
-	})
 
-	Describe("handlePodDeletion", func() {
-		var (
-			config *Config
-		)
+			It("should still manage the pod once it gains a management annotation", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
 
-		BeforeEach(func() {
-			config = NewDefaultConfig()
-			fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
-			reconciler.Client = fakeClient
-		})
+				pod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, pod)).To(Succeed())
+				pod.Annotations = map[string]string{"vpa-managed": "true"}
+				Expect(fakeClient.Update(ctx, pod)).To(Succeed())
 
-		Context("when pod does not have finalizer", func() {
-			It("should return without action", func() {
-				pod := &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
-						Namespace: "default",
-						DeletionTimestamp: &metav1.Time{Time: now},
-					},
-				}
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeClient.Get(ctx, req.NamespacedName, pod)).To(Succeed())
+				Expect(pod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			})
 
-				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+			It("should read the configuration when the resource heuristic may select an owned pod", func() {
+				configMap := &corev1.ConfigMap{}
+				Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-config", Namespace: "test-namespace"}, configMap)).To(Succeed())
+				configMap.Data = map[string]string{}
+				Expect(fakeClient.Update(ctx, configMap)).To(Succeed())
+
+				pod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, pod)).To(Succeed())
+				pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", UID: "rs-uid"}}
+				Expect(fakeClient.Update(ctx, pod)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, req)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(result).To(Equal(ctrl.Result{}))
+				readsAfterFirst := configReads
+
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(configReads).To(BeNumerically(">", readsAfterFirst))
 			})
 		})
 
-		Context("when graceful drain is not completed", func() {
-			It("should requeue", func() {
+		Context("when a pod carrying the finalizer is no longer managed", func() {
+			It("should remove the finalizer once the pod opts out", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:              "test-pod",
-						Namespace:         "default",
-						DeletionTimestamp: &metav1.Time{Time: now},
-						Finalizers:        []string{VPAGracefulDrainFinalizer},
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+						Finalizers: []string{VPAGracefulDrainFinalizer},
 					},
 					Status: corev1.PodStatus{
 						Phase: corev1.PodRunning,
-						Conditions: []corev1.PodCondition{
-							{
-								Type:   corev1.PodReady,
-								Status: corev1.ConditionTrue,
-							},
-						},
 					},
 				}
 
-				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+
+				updatedPod.Annotations["vpa-managed"] = "false"
+				Expect(fakeClient.Update(ctx, updatedPod)).To(Succeed())
+
+				result, err := reconciler.Reconcile(ctx, req)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
 			})
-		})
 
-		Context("when graceful drain is completed", func() {
-			It("should remove finalizer", func() {
-				deletionTime := metav1.NewTime(now.Add(-400 * time.Second)) // Exceeded timeout
-				pod := &corev1.Pod{
+			newDeletingPod := func(deletedAgo time.Duration) *corev1.Pod {
+				deletionTime := metav1.NewTime(now.Add(-deletedAgo))
+				return &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:              "test-pod",
-						Namespace:         "default",
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "false",
+						},
 						DeletionTimestamp: &deletionTime,
-						Finalizers:        []string{VPAGracefulDrainFinalizer},
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
 					},
 					Status: corev1.PodStatus{
 						Phase: corev1.PodRunning,
+						Conditions: []corev1.PodCondition{
+							{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+						},
 					},
 				}
+			}
 
-				// Add pod to fake client so it can be updated
+			It("should keep draining a deleting pod that stopped being managed", func() {
 				fakeClient = fake.NewClientBuilder().
 					WithScheme(testScheme).
-					WithObjects(pod).
+					WithObjects(newDeletingPod(time.Second)).
 					Build()
 				reconciler.Client = fakeClient
 
-				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				result, err := reconciler.Reconcile(ctx, req)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(result).To(Equal(ctrl.Result{}))
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
 
-				// Verify finalizer was removed
 				updatedPod := &corev1.Pod{}
-				err = fakeClient.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, updatedPod)
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			})
+
+			It("should release a deleting pod that stopped being managed once its drain ends", func() {
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(newDeletingPod(400 * time.Second)).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.Reconcile(ctx, req)
 				Expect(err).ToNot(HaveOccurred())
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
 				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
 			})
-		})
-	})
 
-	Describe("shouldManagePod", func() {
-		var config *Config
+			It("should release a running pod that stopped being managed as unmanaged", func() {
+				pod := newDeletingPod(0)
+				pod.DeletionTimestamp = nil
+				pod.Finalizers = []string{VPAGracefulDrainFinalizer}
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					Build()
+				reconciler.Client = fakeClient
 
-		BeforeEach(func() {
-			config = NewDefaultConfig()
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+			})
 		})
 
-		Context("with namespace selector", func() {
+		Context("when the controller is disabled in the ConfigMap", func() {
+			var configMap *corev1.ConfigMap
+
 			BeforeEach(func() {
-				config.NamespaceSelector = &NamespaceSelector{
-					Include: []string{"default"},
-					Exclude: []string{"kube-system"},
+				configMap = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{"enabled": "false"},
 				}
 			})
 
-			It("should return false for excluded namespace", func() {
+			It("should remove the finalizer from a draining pod right away", func() {
+				deletionTime := metav1.NewTime(now)
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
-						Namespace: "kube-system",
+						Namespace: "default",
 						Annotations: map[string]string{
 							"vpa-managed": "true",
 						},
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
 					},
 				}
 
-				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, configMap).
+					Build()
 				reconciler.Client = fakeClient
+				reconciler.Tracker.Track(pod, finalizer.DrainPhaseGracePeriod)
+				removedBefore := testutil.ToFloat64(metrics.FinalizerRemovedTotal)
 
-				shouldManage := reconciler.shouldManagePod(pod, config)
-				Expect(shouldManage).To(BeFalse())
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+				Expect(reconciler.Tracker.Len()).To(Equal(0))
+				Expect(testutil.ToFloat64(metrics.FinalizerRemovedTotal)).To(Equal(removedBefore + 1))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
 			})
 
-			It("should return true for included namespace", func() {
+			It("should neither add nor keep finalizers on running pods", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
@@ -363,35 +536,2425 @@ var _ = Describe("PodReconciler", func() {
 						},
 					},
 				}
+				otherPod := pod.DeepCopy()
+				otherPod.Name = "other-pod"
+				otherPod.Finalizers = []string{VPAGracefulDrainFinalizer}
 
-				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, otherPod, configMap).
+					Build()
 				reconciler.Client = fakeClient
 
-				shouldManage := reconciler.shouldManagePod(pod, config)
-				Expect(shouldManage).To(BeTrue())
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(otherPod)})
+				Expect(err).ToNot(HaveOccurred())
+
+				for _, key := range []client.ObjectKey{req.NamespacedName, client.ObjectKeyFromObject(otherPod)} {
+					updatedPod := &corev1.Pod{}
+					Expect(fakeClient.Get(ctx, key, updatedPod)).To(Succeed())
+					Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+				}
 			})
 		})
 
-		Context("with vpa-managed annotation", func() {
-			It("should return true when annotation is 'true'", func() {
-				pod := &corev1.Pod{
+		Context("when the namespace is paused", func() {
+			var namespace *corev1.Namespace
+
+			BeforeEach(func() {
+				namespace = &corev1.Namespace{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"vpa-managed": "true",
-						},
+						Name:        "default",
+						Annotations: map[string]string{NamespacePausedAnnotation: "true"},
 					},
 				}
-
-				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
-				reconciler.Client = fakeClient
-
-				shouldManage := reconciler.shouldManagePod(pod, config)
-				Expect(shouldManage).To(BeTrue())
 			})
 
-			It("should return false when annotation is 'false'", func() {
+			It("should release a draining pod right away", func() {
+				deletionTime := metav1.NewTime(now)
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						Annotations:       map[string]string{"vpa-managed": "true"},
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, namespace).
+					Build()
+				reconciler.Client = fakeClient
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+			})
+
+			It("should not add the finalizer to running pods", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test-pod",
+						Namespace:   "default",
+						Annotations: map[string]string{"vpa-managed": "true"},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, namespace).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(BeEmpty())
+			})
+
+			It("should resume once the annotation is removed", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test-pod",
+						Namespace:   "default",
+						Annotations: map[string]string{"vpa-managed": "true"},
+					},
+				}
+				namespace.Annotations[NamespacePausedAnnotation] = "false"
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, namespace).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			})
+		})
+
+		Context("when pod was recreated with the same name", func() {
+			It("should discard the previous pod's drain state and treat it as new", func() {
+				deletionTime := metav1.NewTime(now)
+				oldPod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						UID:               "old-uid",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+				}
+				reconciler.Tracker.Track(oldPod, finalizer.DrainPhaseGracePeriod)
+
+				newPod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						UID:       "new-uid",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+					},
+				}
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(newPod).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reconciler.Tracker.Len()).To(Equal(0))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			})
+		})
+
+		Context("with finalizerUpdateStrategy patch", func() {
+			var updates, patches int
+
+			build := func(objects ...client.Object) {
+				updates, patches = 0, 0
+				objects = append(objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "test-namespace"},
+					Data:       map[string]string{"finalizerUpdateStrategy": "patch"},
+				})
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(objects...).
+					WithInterceptorFuncs(interceptor.Funcs{
+						Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+							updates++
+							return c.Update(ctx, obj, opts...)
+						},
+						Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+							patches++
+							return c.Patch(ctx, obj, patch, opts...)
+						},
+					}).
+					Build()
+				reconciler.Client = fakeClient
+			}
+
+			It("should add the finalizer with a patch", func() {
+				build(&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test-pod",
+						Namespace:   "default",
+						Annotations: map[string]string{"vpa-managed": "true"},
+						Finalizers:  []string{"example.com/other"},
+					},
+				})
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updates).To(Equal(0))
+				Expect(patches).To(Equal(1))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(ConsistOf("example.com/other", VPAGracefulDrainFinalizer))
+			})
+
+			It("should remove the finalizer with a patch when the drain completes", func() {
+				// Past the grace period and not ready, so the drain completes right away
+				deletionTime := metav1.NewTime(now.Add(-time.Minute))
+				build(&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						Annotations:       map[string]string{"vpa-managed": "true"},
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					},
+					Status: corev1.PodStatus{Phase: corev1.PodRunning},
+				})
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updates).To(Equal(0))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(Equal([]string{"example.com/other"}))
+			})
+		})
+
+		Context("when pod needs finalizer", func() {
+			It("should add finalizer", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, configMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				// Verify finalizer was added
+				updatedPod := &corev1.Pod{}
+				err = fakeClient.Get(ctx, req.NamespacedName, updatedPod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updatedPod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			})
+
+			It("should handle conflict error and retry", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, configMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+			})
+
+			It("should retry the finalizer add after a conflict", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+					},
+				}
+
+				updates := 0
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					WithInterceptorFuncs(interceptor.Funcs{
+						Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+							updates++
+							if updates == 1 {
+								return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, obj.GetName(), nil)
+							}
+							return c.Update(ctx, obj, opts...)
+						},
+					}).
+					Build()
+				reconciler.Client = fakeClient
+				conflicts := metrics.UpdateErrorsTotal.WithLabelValues(metrics.OperationAddFinalizer, metrics.UpdateErrorReasonConflict)
+				conflictsBefore := testutil.ToFloat64(conflicts)
+				addedBefore := testutil.ToFloat64(metrics.FinalizerAddedTotal)
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+				Expect(updates).To(Equal(2))
+				Expect(testutil.ToFloat64(conflicts)).To(Equal(conflictsBefore + 1))
+				Expect(testutil.ToFloat64(metrics.FinalizerAddedTotal)).To(Equal(addedBefore + 1))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			})
+
+			It("should return a persistent update error for workqueue backoff", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+					},
+				}
+
+				updates := 0
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					WithInterceptorFuncs(interceptor.Funcs{
+						Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+							updates++
+							return apierrors.NewServiceUnavailable("apiserver unavailable")
+						},
+					}).
+					Build()
+				reconciler.Client = fakeClient
+				failures := metrics.UpdateErrorsTotal.WithLabelValues(metrics.OperationAddFinalizer, metrics.UpdateErrorReasonOther)
+				failuresBefore := testutil.ToFloat64(failures)
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+				Expect(updates).To(Equal(1))
+				Expect(testutil.ToFloat64(failures)).To(Equal(failuresBefore + 1))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("handlePodDeletion", func() {
+		var (
+			config *Config
+		)
+
+		BeforeEach(func() {
+			config = NewDefaultConfig()
+			fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+			reconciler.Client = fakeClient
+		})
+
+		Context("when pod does not have finalizer", func() {
+			It("should return without action", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						DeletionTimestamp: &metav1.Time{Time: now},
+					},
+				}
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+			})
+		})
+
+		Context("when graceful drain is not completed", func() {
+			It("should requeue", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &metav1.Time{Time: now},
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						Conditions: []corev1.PodCondition{
+							{
+								Type:   corev1.PodReady,
+								Status: corev1.ConditionTrue,
+							},
+						},
+					},
+				}
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 30*time.Second, 6*time.Second))
+				Expect(reconciler.Tracker.Len()).To(Equal(1))
+			})
+
+			It("should requeue at the end of the remaining grace period", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &metav1.Time{Time: now.Add(-20 * time.Second)},
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 10*time.Second, 2*time.Second))
+			})
+
+			It("should poll connections at connectionPollIntervalSeconds after the grace period", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &metav1.Time{Time: now.Add(-60 * time.Second)},
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				}
+				config.ConnectionCheckMode = finalizer.ConnectionCheckModeConntrack
+				config.ConnectionPollIntervalSeconds = 3
+				reconciler.ConnTracker = &stubConnTracker{established: 1}
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 3*time.Second, 600*time.Millisecond))
+			})
+
+			It("should requeue at the slow-drain threshold instead of skipping past it", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &metav1.Time{Time: now.Add(-235 * time.Second)},
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				}
+				config.ConnectionCheckMode = finalizer.ConnectionCheckModeConntrack
+				reconciler.ConnTracker = &stubConnTracker{established: 1}
+
+				// The 10s poll would land at 245s, past the 240s threshold of the 300s drain timeout
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 5*time.Second, time.Second))
+			})
+		})
+
+		Context("when the pod's namespace is terminating", func() {
+			var pod *corev1.Pod
+
+			BeforeEach(func() {
+				namespace := &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "default",
+						DeletionTimestamp: &metav1.Time{Time: now},
+						Finalizers:        []string{"kubernetes"},
+					},
+				}
+				pod = &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &metav1.Time{Time: now},
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(namespace, pod).
+					Build()
+				reconciler.Client = fakeClient
+			})
+
+			It("should complete the drain immediately", func() {
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+				Expect(recorder.Events).To(Receive(ContainSubstring("Normal NamespaceTerminating")))
+			})
+
+			It("should keep draining when blockNamespaceTermination is set", func() {
+				config.BlockNamespaceTermination = true
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+				Expect(recorder.Events).ToNot(Receive())
+			})
+		})
+
+		Context("when connectionCheckMode is conntrack", func() {
+			It("should hold the pod while the conn tracker reports connections", func() {
+				deletionTime := metav1.NewTime(now.Add(-60 * time.Second)) // Past grace period
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+					Spec: corev1.PodSpec{
+						NodeName: "node-1",
+						Containers: []corev1.Container{
+							{
+								Name:  "app",
+								Image: "app",
+								Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+							},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						Conditions: []corev1.PodCondition{
+							{
+								Type:   corev1.PodReady,
+								Status: corev1.ConditionTrue,
+							},
+						},
+					},
+				}
+				config.ConnectionCheckMode = finalizer.ConnectionCheckModeConntrack
+				reconciler.ConnTracker = &stubConnTracker{established: 1}
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			})
+		})
+
+		Context("when graceful drain is completed", func() {
+			It("should remove finalizer", func() {
+				deletionTime := metav1.NewTime(now.Add(-400 * time.Second)) // Exceeded timeout
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						// A second finalizer keeps the fake client from deleting the pod
+						// once ours is removed, so the result can be inspected
+						Finalizers: []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+
+				// Add pod to fake client so it can be updated
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					Build()
+				reconciler.Client = fakeClient
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				// Verify finalizer was removed
+				updatedPod := &corev1.Pod{}
+				err = fakeClient.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, updatedPod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+				Expect(reconciler.Tracker.Len()).To(Equal(0))
+			})
+		})
+
+		Context("when recording completion metrics", func() {
+			completeDrain := func(deletionAge time.Duration, status corev1.PodStatus) {
+				deletionTime := metav1.NewTime(now.Add(-deletionAge))
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					},
+					Status: status,
+				}
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			It("should count a timeout completion under the timeout reason", func() {
+				timeoutBefore := testutil.ToFloat64(metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonTimeout, "", metrics.OwnerKindOther))
+				noConnectionsBefore := testutil.ToFloat64(metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonNoConnections, "", metrics.OwnerKindOther))
+
+				// Past the 300s drain timeout but within the 60s hard deadline buffer
+				completeDrain(330*time.Second, corev1.PodStatus{Phase: corev1.PodRunning})
+
+				Expect(testutil.ToFloat64(metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonTimeout, "", metrics.OwnerKindOther))).To(Equal(timeoutBefore + 1))
+				Expect(testutil.ToFloat64(metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonNoConnections, "", metrics.OwnerKindOther))).To(Equal(noConnectionsBefore))
+			})
+
+			It("should count a drained pod under the no-connections reason", func() {
+				timeoutBefore := testutil.ToFloat64(metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonTimeout, "", metrics.OwnerKindOther))
+				noConnectionsBefore := testutil.ToFloat64(metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonNoConnections, "", metrics.OwnerKindOther))
+
+				completeDrain(60*time.Second, corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				})
+
+				Expect(testutil.ToFloat64(metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonNoConnections, "", metrics.OwnerKindOther))).To(Equal(noConnectionsBefore + 1))
+				Expect(testutil.ToFloat64(metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonTimeout, "", metrics.OwnerKindOther))).To(Equal(timeoutBefore))
+			})
+
+			It("should reflect in-progress drains in the active gauge", func() {
+				deletionTime := metav1.NewTime(now)
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+				}
+
+				reconciler.Tracker.Track(pod, finalizer.DrainPhaseGracePeriod)
+				Expect(testutil.ToFloat64(metrics.ActiveDrains)).To(Equal(1.0))
+
+				reconciler.Tracker.Untrack(client.ObjectKeyFromObject(pod))
+				Expect(testutil.ToFloat64(metrics.ActiveDrains)).To(Equal(0.0))
+			})
+		})
+
+		Context("when drain is in progress", func() {
+			It("should record the grace-period phase in the status annotation", func() {
+				deletionTime := metav1.NewTime(now)
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+
+				updatedPod := &corev1.Pod{}
+				err = fakeClient.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, updatedPod)
+				Expect(err).ToNot(HaveOccurred())
+
+				var status finalizer.DrainStatus
+				Expect(json.Unmarshal([]byte(updatedPod.Annotations[finalizer.StatusAnnotation]), &status)).To(Succeed())
+				Expect(status.Phase).To(Equal(finalizer.DrainPhaseGracePeriod))
+				Expect(status.DeadlineSeconds).To(Equal(int64(300)))
+			})
+
+			It("should record the waiting-connections phase once the grace period elapsed", func() {
+				deletionTime := metav1.NewTime(now.Add(-42 * time.Second))
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+						Labels:            map[string]string{"app": "web"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "app",
+								Image: "nginx",
+								Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+							},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						PodIP: "10.0.0.1",
+						Conditions: []corev1.PodCondition{
+							{
+								Type:   corev1.PodReady,
+								Status: corev1.ConditionTrue,
+							},
+						},
+					},
+				}
+				service := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec: corev1.ServiceSpec{
+						Selector: map[string]string{"app": "web"},
+					},
+				}
+				endpoints := &corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Subsets: []corev1.EndpointSubset{
+						{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, service, endpoints).
+					Build()
+				reconciler.Client = fakeClient
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 10*time.Second, 2*time.Second))
+
+				updatedPod := &corev1.Pod{}
+				err = fakeClient.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, updatedPod)
+				Expect(err).ToNot(HaveOccurred())
+
+				var status finalizer.DrainStatus
+				Expect(json.Unmarshal([]byte(updatedPod.Annotations[finalizer.StatusAnnotation]), &status)).To(Succeed())
+				Expect(status.Phase).To(Equal(finalizer.DrainPhaseDraining))
+				Expect(status.ElapsedSeconds).To(BeNumerically(">=", 42))
+			})
+
+			It("should complete after the grace period in grace-period-only mode", func() {
+				deletionTime := metav1.NewTime(now.Add(-42 * time.Second))
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+						Labels:            map[string]string{"app": "web"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "app", Image: "nginx", Ports: []corev1.ContainerPort{{ContainerPort: 80}}},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						PodIP:      "10.0.0.1",
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				}
+				service := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec: corev1.ServiceSpec{
+						Selector: map[string]string{"app": "web"},
+					},
+				}
+				endpoints := &corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Subsets: []corev1.EndpointSubset{
+						{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, service, endpoints).
+					Build()
+				reconciler.Client = fakeClient
+				reconciler.GracePeriodOnly = true
+
+				_, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+			})
+
+			It("should back off while connections persist and reset once they clear", func() {
+				deletionTime := metav1.NewTime(now.Add(-42 * time.Second))
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						UID:               "test-pod-uid",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+						Labels:            map[string]string{"app": "web"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "app", Image: "nginx", Ports: []corev1.ContainerPort{{ContainerPort: 80}}},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						PodIP:      "10.0.0.1",
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				}
+				service := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+				}
+				endpoints := &corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Subsets: []corev1.EndpointSubset{
+						{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+					},
+				}
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod, service, endpoints).
+					Build()
+				reconciler.Client = fakeClient
+				config.MaxPollIntervalSeconds = 30
+
+				expectRequeue := func(expected time.Duration) {
+					result, err := reconciler.handlePodDeletion(ctx, pod, config)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(BeNumerically("~", expected, expected/4))
+				}
+
+				expectRequeue(10 * time.Second)
+				expectRequeue(20 * time.Second)
+				expectRequeue(30 * time.Second)
+				expectRequeue(30 * time.Second)
+
+				// A check that finds no connections starts the pod over at the poll interval
+				reconciler.DrainGate = closedDrainGate{}
+				expectRequeue(10 * time.Second)
+				reconciler.DrainGate = nil
+				expectRequeue(10 * time.Second)
+				expectRequeue(20 * time.Second)
+			})
+
+			It("should not back off past the drain timeout", func() {
+				deletionTime := metav1.NewTime(now.Add(-295 * time.Second))
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						UID:               "test-pod-uid",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+						Annotations:       map[string]string{"example.com/active": "true"},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				}
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(pod).Build()
+				reconciler.Client = fakeClient
+				reconciler.Clock = fixedClock{now: now}
+				config.ActiveTrafficAnnotations = []string{"example.com/active"}
+				for range 3 {
+					reconciler.connectionPollBackoff().Next(pod.UID, 10*time.Second, time.Minute)
+				}
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 10*time.Second, 2*time.Second))
+			})
+
+			It("should count a phase transition once", func() {
+				deletionTime := metav1.NewTime(now.Add(-10 * time.Second))
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+					Status: corev1.PodStatus{Phase: corev1.PodRunning},
+				}
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(pod).Build()
+				reconciler.Client = fakeClient
+				transitions := metrics.DrainPhaseTransitionsTotal.WithLabelValues(
+					string(finalizer.DrainPhasePending), string(finalizer.DrainPhaseGracePeriod))
+				before := testutil.ToFloat64(transitions)
+
+				_, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(testutil.ToFloat64(transitions)).To(Equal(before + 1))
+			})
+
+			It("should not move the phase back when the grace period grows", func() {
+				deletionTime := metav1.NewTime(now.Add(-10 * time.Second))
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+						// Recorded by an earlier reconcile, before the grace period was extended
+						Annotations: map[string]string{finalizer.StatusAnnotation: `{"phase":"waiting-connections"}`},
+					},
+					Status: corev1.PodStatus{Phase: corev1.PodRunning},
+				}
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(pod).Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+
+				var status finalizer.DrainStatus
+				Expect(json.Unmarshal([]byte(pod.Annotations[finalizer.StatusAnnotation]), &status)).To(Succeed())
+				Expect(status.Phase).To(Equal(finalizer.DrainPhaseDraining))
+			})
+		})
+
+		Context("when force-complete annotation is set", func() {
+			It("should remove finalizer within the grace period and emit a warning event", func() {
+				deletionTime := metav1.NewTime(now)
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-pod",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+						Annotations: map[string]string{
+							finalizer.ForceCompleteAnnotation: "true",
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					Build()
+				reconciler.Client = fakeClient
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				updatedPod := &corev1.Pod{}
+				err = fakeClient.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, updatedPod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning ForceCompleted")))
+			})
+		})
+
+		Context("when the pod was force-deleted", func() {
+			It("should remove the finalizer right away", func() {
+				deletionTime := metav1.NewTime(now)
+				var zero int64
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:                       "test-pod",
+						Namespace:                  "default",
+						Annotations:                map[string]string{"vpa-managed": "true"},
+						DeletionTimestamp:          &deletionTime,
+						DeletionGracePeriodSeconds: &zero,
+						Finalizers:                 []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						Conditions: []corev1.PodCondition{
+							{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+						},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(pod).
+					Build()
+				reconciler.Client = fakeClient
+				completions := metrics.CompletionReasonTotal.WithLabelValues(finalizer.CompletionReasonForceDeleted, "", metrics.OwnerKindOther)
+				completionsBefore := testutil.ToFloat64(completions)
+
+				result, err := reconciler.handlePodDeletion(ctx, pod, config)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+				Expect(testutil.ToFloat64(completions)).To(Equal(completionsBefore + 1))
+
+				updatedPod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+				Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+			})
+		})
+	})
+
+	Describe("drain claims across replicas", func() {
+		var (
+			pod      *corev1.Pod
+			replicaB *PodReconciler
+		)
+
+		newDrainingPod := func(deletedAgo time.Duration) *corev1.Pod {
+			deletionTime := metav1.NewTime(now.Add(-deletedAgo))
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"vpa-managed": "true",
+					},
+					DeletionTimestamp: &deletionTime,
+					Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+				},
+			}
+		}
+
+		setup := func(objects ...client.Object) {
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(objects...).
+				Build()
+			reconciler.Client = fakeClient
+			reconciler.ReplicaID = "replica-a"
+			reconciler.Clock = fixedClock{now: now}
+
+			replicaB = &PodReconciler{
+				Client:             fakeClient,
+				Scheme:             testScheme,
+				Recorder:           record.NewFakeRecorder(10),
+				Tracker:            NewDrainTracker(),
+				ConfigMapName:      "test-config",
+				ConfigMapNamespace: "test-namespace",
+				Rand:               rand.New(rand.NewSource(1)),
+				ReplicaID:          "replica-b",
+				Clock:              fixedClock{now: now},
+			}
+		}
+
+		claimHolder := func() string {
+			updatedPod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+			var claim drainClaim
+			if value, ok := updatedPod.Annotations[DrainClaimAnnotation]; ok {
+				Expect(json.Unmarshal([]byte(value), &claim)).To(Succeed())
+			}
+			return claim.Holder
+		}
+
+		It("should skip a pod freshly claimed by another replica", func() {
+			pod = newDrainingPod(0)
+			setup(pod)
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(claimHolder()).To(Equal("replica-a"))
+
+			result, err := replicaB.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", defaultDrainClaimLease, 12*time.Second))
+			Expect(claimHolder()).To(Equal("replica-a"))
+			Expect(replicaB.Tracker.Len()).To(Equal(0))
+		})
+
+		It("should take over a claim whose lease has expired", func() {
+			pod = newDrainingPod(0)
+			// Keep the pod in its grace period past the lease
+			setup(pod, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "test-namespace"},
+				Data:       map[string]string{"gracePeriodSeconds": "120"},
+			})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			replicaB.Clock = fixedClock{now: now.Add(defaultDrainClaimLease + time.Second)}
+			_, err = replicaB.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(claimHolder()).To(Equal("replica-b"))
+		})
+
+		It("should remove the claim when the drain completes", func() {
+			// Past the grace period and not ready, so the drain completes right away
+			pod = newDrainingPod(time.Minute)
+			setup(pod)
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			updatedPod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+			Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+			Expect(updatedPod.Annotations).ToNot(HaveKey(DrainClaimAnnotation))
+		})
+	})
+
+	Describe("custom finalizer names", func() {
+		It("should not interfere between reconcilers with different finalizer names", func() {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"vpa-managed": "true",
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+				},
+			}
+
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(pod).
+				Build()
+
+			prodReconciler := &PodReconciler{
+				Client:             fakeClient,
+				Scheme:             testScheme,
+				Recorder:           recorder,
+				Tracker:            NewDrainTracker(),
+				ConfigMapName:      "test-config",
+				ConfigMapNamespace: "test-namespace",
+				FinalizerName:      "prod.example.com/graceful-drain",
+			}
+			stagingReconciler := &PodReconciler{
+				Client:             fakeClient,
+				Scheme:             testScheme,
+				Recorder:           recorder,
+				Tracker:            NewDrainTracker(),
+				ConfigMapName:      "test-config",
+				ConfigMapNamespace: "test-namespace",
+				FinalizerName:      "staging.example.com/graceful-drain",
+			}
+
+			_, err := prodReconciler.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = stagingReconciler.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			updatedPod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+			Expect(updatedPod.Finalizers).To(ConsistOf(
+				"prod.example.com/graceful-drain",
+				"staging.example.com/graceful-drain",
+			))
+
+			// Release only the prod finalizer once the drain completes
+			updatedPod.Annotations[finalizer.ForceCompleteAnnotation] = "true"
+			Expect(fakeClient.Update(ctx, updatedPod)).To(Succeed())
+			Expect(fakeClient.Delete(ctx, updatedPod)).To(Succeed())
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPod)).To(Succeed())
+
+			result, err := prodReconciler.handlePodDeletion(ctx, updatedPod, NewDefaultConfig())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+
+			finalPod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, finalPod)).To(Succeed())
+			Expect(finalPod.Finalizers).To(ConsistOf("staging.example.com/graceful-drain"))
+		})
+
+		It("should default to VPAGracefulDrainFinalizer", func() {
+			Expect(reconciler.finalizerName()).To(Equal(VPAGracefulDrainFinalizer))
+		})
+
+		It("should remove only its own finalizer from a pod carrying a look-alike", func() {
+			deletionTime := metav1.NewTime(time.Now())
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+					Finalizers:        []string{VPAGracefulDrainFinalizer, VPAGracefulDrainFinalizer + "-canary"},
+					Annotations:       map[string]string{finalizer.ForceCompleteAnnotation: "true"},
+				},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(pod).Build()
+			reconciler.Client = fakeClient
+
+			_, err := reconciler.handlePodDeletion(ctx, pod, NewDefaultConfig())
+			Expect(err).ToNot(HaveOccurred())
+
+			updatedPod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), updatedPod)).To(Succeed())
+			Expect(updatedPod.Finalizers).To(Equal([]string{VPAGracefulDrainFinalizer + "-canary"}))
+		})
+
+		It("should report finalizers similar to its own", func() {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Finalizers: []string{
+						VPAGracefulDrainFinalizer,
+						VPAGracefulDrainFinalizer + "-canary",
+						"fork.example.com/finalizer",
+						"example.com/other",
+						"foregroundDeletion",
+					},
+				},
+			}
+
+			Expect(similarFinalizers(pod, VPAGracefulDrainFinalizer)).To(Equal([]string{
+				VPAGracefulDrainFinalizer + "-canary",
+				"fork.example.com/finalizer",
+			}))
+			Expect(similarFinalizers(pod, "prod.example.com/graceful-drain")).To(BeEmpty())
+		})
+
+		It("should pass events for pods carrying the configured finalizer", func() {
+			reconciler.FinalizerName = "prod.example.com/graceful-drain"
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-pod",
+					Namespace:  "default",
+					Finalizers: []string{"prod.example.com/graceful-drain"},
+				},
+			}
+			foreignPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-pod",
+					Namespace:  "default",
+					Finalizers: []string{VPAGracefulDrainFinalizer},
+				},
+			}
+
+			Expect(reconciler.podEventFilter().Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: pod})).To(BeTrue())
+			Expect(reconciler.podEventFilter().Update(event.UpdateEvent{ObjectOld: foreignPod, ObjectNew: foreignPod})).To(BeFalse())
+		})
+	})
+
+	Describe("ignoreOptedOutPods", func() {
+		newPod := func(vpaManaged string, finalizers ...string) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					Annotations: map[string]string{"vpa-managed": vpaManaged},
+					Finalizers:  finalizers,
+				},
+			}
+		}
+
+		It("should drop events for pods annotated vpa-managed false", func() {
+			oldPod := newPod("false")
+			updatedPod := newPod("false")
+			updatedPod.Annotations["team"] = "payments"
+
+			Expect(reconciler.ignoreOptedOutPods().Create(event.CreateEvent{Object: oldPod})).To(BeFalse())
+			Expect(reconciler.ignoreOptedOutPods().Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: updatedPod})).To(BeFalse())
+		})
+
+		It("should keep events for other pods", func() {
+			Expect(reconciler.ignoreOptedOutPods().Create(event.CreateEvent{Object: newPod("true")})).To(BeTrue())
+			Expect(reconciler.ignoreOptedOutPods().Create(event.CreateEvent{Object: &corev1.Pod{}})).To(BeTrue())
+		})
+
+		It("should keep events for opted-out pods that still carry our finalizer", func() {
+			pod := newPod("false", VPAGracefulDrainFinalizer)
+
+			Expect(reconciler.ignoreOptedOutPods().Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: pod})).To(BeTrue())
+		})
+	})
+
+	Describe("ignoreDrainStatusUpdates", func() {
+		var oldPod *corev1.Pod
+
+		BeforeEach(func() {
+			oldPod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "test-pod",
+					Namespace:       "default",
+					ResourceVersion: "1",
+					Annotations: map[string]string{
+						"vpa-managed": "true",
+					},
+				},
+			}
+		})
+
+		It("should drop updates that only change the status annotation", func() {
+			newPod := oldPod.DeepCopy()
+			newPod.ResourceVersion = "2"
+			newPod.Annotations[finalizer.StatusAnnotation] = `{"phase":"grace-period"}`
+
+			Expect(ignoreDrainStatusUpdates().Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: newPod})).To(BeFalse())
+		})
+
+		DescribeTable("should drop updates that only change a bookkeeping annotation",
+			func(annotation, value string) {
+				newPod := oldPod.DeepCopy()
+				newPod.ResourceVersion = "2"
+				newPod.Annotations[annotation] = value
+
+				Expect(ignoreDrainStatusUpdates().Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: newPod})).To(BeFalse())
+			},
+			Entry("knative unrouted at", finalizer.KnativeUnroutedAtAnnotation, "2024-01-01T00:00:00Z"),
+			Entry("timeout extensions", finalizer.TimeoutExtensionsAnnotation, "1"),
+		)
+
+		It("should keep updates that change other annotations", func() {
+			newPod := oldPod.DeepCopy()
+			newPod.ResourceVersion = "2"
+			newPod.Annotations[finalizer.ForceCompleteAnnotation] = "true"
+
+			Expect(ignoreDrainStatusUpdates().Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: newPod})).To(BeTrue())
+		})
+	})
+
+	Describe("drain priority", func() {
+		var config *Config
+
+		newDrainingPod := func(name, uid, priority string) *corev1.Pod {
+			controller := true
+			// Past the grace period, ready and without ports, so the drain itself is done
+			deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         "default",
+					UID:               types.UID(uid),
+					DeletionTimestamp: &deletionTime,
+					Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", UID: "db-uid", Controller: &controller},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+			if priority != "" {
+				pod.Annotations = map[string]string{DrainPriorityAnnotation: priority}
+			}
+			return pod
+		}
+
+		BeforeEach(func() {
+			config = NewDefaultConfig()
+		})
+
+		It("should release sibling pods in drain-priority order", func() {
+			db0 := newDrainingPod("db-0", "uid-0", "0")
+			db1 := newDrainingPod("db-1", "uid-1", "1")
+			db2 := newDrainingPod("db-2", "uid-2", "2")
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(db0, db1, db2).
+				Build()
+			reconciler.Client = fakeClient
+
+			hasOurFinalizer := func(name string) bool {
+				pod := &corev1.Pod{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod)).To(Succeed())
+				return controllerutil.ContainsFinalizer(pod, VPAGracefulDrainFinalizer)
+			}
+
+			// db-2 and db-1 are held while db-0 is still draining
+			result, err := reconciler.handlePodDeletion(ctx, db2, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			result, err = reconciler.handlePodDeletion(ctx, db1, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(hasOurFinalizer("db-1")).To(BeTrue())
+			Expect(hasOurFinalizer("db-2")).To(BeTrue())
+
+			// The time their drains completed is kept for the completion summary
+			held := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "db-1", Namespace: "default"}, held)).To(Succeed())
+			Expect(held.Annotations).To(HaveKey(ConnectionsClearedAnnotation))
+
+			_, err = reconciler.handlePodDeletion(ctx, db0, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasOurFinalizer("db-0")).To(BeFalse())
+
+			// With db-0 released, db-1 goes next while db-2 keeps waiting
+			result, err = reconciler.handlePodDeletion(ctx, db2, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			_, err = reconciler.handlePodDeletion(ctx, db1, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasOurFinalizer("db-1")).To(BeFalse())
+
+			_, err = reconciler.handlePodDeletion(ctx, db2, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasOurFinalizer("db-2")).To(BeFalse())
+		})
+
+		It("should not order pods without the annotation", func() {
+			db0 := newDrainingPod("db-0", "uid-0", "0")
+			db1 := newDrainingPod("db-1", "uid-1", "")
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(db0, db1).
+				Build()
+			reconciler.Client = fakeClient
+
+			result, err := reconciler.handlePodDeletion(ctx, db1, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+		})
+
+		It("should not hold a pod past the hard deadline", func() {
+			db0 := newDrainingPod("db-0", "uid-0", "0")
+			db1 := newDrainingPod("db-1", "uid-1", "1")
+			hardDeadlineExceeded := metav1.NewTime(now.Add(-400 * time.Second))
+			db1.DeletionTimestamp = &hardDeadlineExceeded
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(db0, db1).
+				Build()
+			reconciler.Client = fakeClient
+
+			result, err := reconciler.handlePodDeletion(ctx, db1, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+		})
+	})
+
+	Describe("preventLastReplicaDrain", func() {
+		var config *Config
+
+		newReplica := func(name string, deleteAgo time.Duration, ready bool) *corev1.Pod {
+			controller := true
+			readyStatus := corev1.ConditionFalse
+			if ready {
+				readyStatus = corev1.ConditionTrue
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       name,
+					Namespace:  "default",
+					UID:        types.UID(name + "-uid"),
+					Finalizers: []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-abc", UID: "web-abc-uid", Controller: &controller},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						{Type: corev1.PodReady, Status: readyStatus},
+					},
+				},
+			}
+			if deleteAgo > 0 {
+				deletionTime := metav1.NewTime(now.Add(-deleteAgo))
+				pod.DeletionTimestamp = &deletionTime
+			}
+			return pod
+		}
+
+		hasOurFinalizer := func(name string) bool {
+			pod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod)).To(Succeed())
+			return controllerutil.ContainsFinalizer(pod, VPAGracefulDrainFinalizer)
+		}
+
+		BeforeEach(func() {
+			config = NewDefaultConfig()
+			config.PreventLastReplicaDrain = true
+		})
+
+		It("should hold the last ready pod until its replacement is ready", func() {
+			// Past the grace period and without ports, so only the last-replica check holds it
+			draining := newReplica("web-0", 60*time.Second, true)
+			replacement := newReplica("web-1", 0, false)
+			alsoDraining := newReplica("web-2", 60*time.Second, true)
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(draining, replacement, alsoDraining).
+				Build()
+			reconciler.Client = fakeClient
+
+			result, err := reconciler.handlePodDeletion(ctx, draining, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(hasOurFinalizer("web-0")).To(BeTrue())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(replacement), replacement)).To(Succeed())
+			replacement.Status.Conditions[0].Status = corev1.ConditionTrue
+			Expect(fakeClient.Status().Update(ctx, replacement)).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(draining), draining)).To(Succeed())
+			_, err = reconciler.handlePodDeletion(ctx, draining, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasOurFinalizer("web-0")).To(BeFalse())
+		})
+
+		It("should release the last ready pod at the drain timeout", func() {
+			draining := newReplica("web-0", 301*time.Second, true)
+			replacement := newReplica("web-1", 0, false)
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(draining, replacement).
+				Build()
+			reconciler.Client = fakeClient
+
+			_, err := reconciler.handlePodDeletion(ctx, draining, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasOurFinalizer("web-0")).To(BeFalse())
+		})
+
+		It("should not hold the last ready pod when disabled", func() {
+			config.PreventLastReplicaDrain = false
+			draining := newReplica("web-0", 60*time.Second, true)
+			replacement := newReplica("web-1", 0, false)
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(draining, replacement).
+				Build()
+			reconciler.Client = fakeClient
+
+			_, err := reconciler.handlePodDeletion(ctx, draining, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasOurFinalizer("web-0")).To(BeFalse())
+		})
+	})
+
+	Describe("preserveZoneAvailability", func() {
+		var config *Config
+
+		newReplica := func(name, nodeName string, deleteAgo time.Duration, ready bool) *corev1.Pod {
+			controller := true
+			readyStatus := corev1.ConditionFalse
+			if ready {
+				readyStatus = corev1.ConditionTrue
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       name,
+					Namespace:  "default",
+					UID:        types.UID(name + "-uid"),
+					Finalizers: []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-abc", UID: "web-abc-uid", Controller: &controller},
+					},
+				},
+				Spec: corev1.PodSpec{NodeName: nodeName},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						{Type: corev1.PodReady, Status: readyStatus},
+					},
+				},
+			}
+			if deleteAgo > 0 {
+				deletionTime := metav1.NewTime(now.Add(-deleteAgo))
+				pod.DeletionTimestamp = &deletionTime
+			}
+			return pod
+		}
+
+		newZoneNode := func(name, zone string) *corev1.Node {
+			return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{corev1.LabelTopologyZone: zone},
+			}}
+		}
+
+		hasOurFinalizer := func(name string) bool {
+			pod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod)).To(Succeed())
+			return controllerutil.ContainsFinalizer(pod, VPAGracefulDrainFinalizer)
+		}
+
+		BeforeEach(func() {
+			config = NewDefaultConfig()
+			config.PreserveZoneAvailability = true
+		})
+
+		It("should hold the last ready pod of its zone until a pod in the zone is ready", func() {
+			// Past the grace period and without ports, so only the zone check holds it
+			draining := newReplica("web-0", "node-a1", 60*time.Second, true)
+			otherZone := newReplica("web-1", "node-b1", 0, true)
+			replacement := newReplica("web-2", "node-a2", 0, false)
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(draining, otherZone, replacement,
+					newZoneNode("node-a1", "zone-a"), newZoneNode("node-a2", "zone-a"), newZoneNode("node-b1", "zone-b")).
+				Build()
+			reconciler.Client = fakeClient
+
+			result, err := reconciler.handlePodDeletion(ctx, draining, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(hasOurFinalizer("web-0")).To(BeTrue())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(replacement), replacement)).To(Succeed())
+			replacement.Status.Conditions[0].Status = corev1.ConditionTrue
+			Expect(fakeClient.Status().Update(ctx, replacement)).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(draining), draining)).To(Succeed())
+			_, err = reconciler.handlePodDeletion(ctx, draining, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasOurFinalizer("web-0")).To(BeFalse())
+		})
+
+		It("should release the last ready pod of its zone at the drain timeout", func() {
+			draining := newReplica("web-0", "node-a1", 301*time.Second, true)
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(draining, newZoneNode("node-a1", "zone-a")).
+				Build()
+			reconciler.Client = fakeClient
+
+			_, err := reconciler.handlePodDeletion(ctx, draining, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasOurFinalizer("web-0")).To(BeFalse())
+		})
+	})
+
+	Describe("jitter", func() {
+		It("should keep requeue durations within ±20% of the base", func() {
+			seen := map[time.Duration]bool{}
+			for i := 0; i < 100; i++ {
+				d := reconciler.jitter(10 * time.Second)
+				Expect(d).To(BeNumerically(">=", 8*time.Second))
+				Expect(d).To(BeNumerically("<=", 12*time.Second))
+				seen[d] = true
+			}
+			Expect(len(seen)).To(BeNumerically(">", 1))
+		})
+
+		It("should be deterministic for a given seed", func() {
+			other := &PodReconciler{Rand: rand.New(rand.NewSource(1))}
+			for i := 0; i < 10; i++ {
+				Expect(reconciler.jitter(100 * time.Millisecond)).To(Equal(other.jitter(100 * time.Millisecond)))
+			}
+		})
+
+		It("should default to a time-seeded source", func() {
+			unseeded := &PodReconciler{}
+			d := unseeded.jitter(10 * time.Second)
+			Expect(d).To(BeNumerically("~", 10*time.Second, 2*time.Second))
+			Expect(unseeded.Rand).ToNot(BeNil())
+		})
+	})
+
+	Describe("RebuildStateFromCluster", func() {
+		It("should track only pods being deleted that carry our finalizer", func() {
+			deletionTime := metav1.NewTime(now.Add(-time.Minute))
+			drainingPods := []client.Object{
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "draining-1",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+				},
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "draining-2",
+						Namespace:         "production",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{VPAGracefulDrainFinalizer},
+					},
+				},
+			}
+			otherPods := []client.Object{
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "running",
+						Namespace:  "default",
+						Finalizers: []string{VPAGracefulDrainFinalizer},
+					},
+				},
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "foreign-finalizer",
+						Namespace:         "default",
+						DeletionTimestamp: &deletionTime,
+						Finalizers:        []string{"example.com/other"},
+					},
+				},
+			}
+
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(append(drainingPods, otherPods...)...).
+				Build()
+			reconciler.Client = fakeClient
+
+			err := reconciler.RebuildStateFromCluster(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reconciler.Tracker.Len()).To(Equal(2))
+
+			entries := reconciler.Tracker.List()
+			Expect(entries[0].Name).To(Equal("draining-1"))
+			Expect(entries[1].Name).To(Equal("draining-2"))
+			Expect(entries[1].DeletionTimestamp).To(BeTemporally("~", deletionTime.Time, time.Second))
+		})
+	})
+
+	Describe("DrainStatusForNamespace", func() {
+		It("should report every pod carrying our finalizer in the namespace", func() {
+			// Timestamps are stored with second precision
+			deletionTime := metav1.NewTime(now.Truncate(time.Second).Add(-45 * time.Second))
+			reconciler.Clock = fixedClock{now: deletionTime.Add(45 * time.Second)}
+			servingStatus := corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				PodIP:      "10.0.0.1",
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			}
+			servingSpec := corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+				},
+			}
+
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "draining",
+							Namespace:         "default",
+							Labels:            map[string]string{"app": "web"},
+							DeletionTimestamp: &deletionTime,
+							Finalizers:        []string{VPAGracefulDrainFinalizer},
+						},
+						Spec:   servingSpec,
+						Status: servingStatus,
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:       "running",
+							Namespace:  "default",
+							Finalizers: []string{VPAGracefulDrainFinalizer},
+						},
+						Status: corev1.PodStatus{Phase: corev1.PodRunning},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "unmanaged",
+							Namespace: "default",
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:       "elsewhere",
+							Namespace:  "production",
+							Finalizers: []string{VPAGracefulDrainFinalizer},
+						},
+					},
+					&corev1.Service{
+						ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+						Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+					},
+					&corev1.Endpoints{
+						ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+						Subsets: []corev1.EndpointSubset{
+							{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+						},
+					},
+				).
+				Build()
+			reconciler.Client = fakeClient
+
+			statuses, err := reconciler.DrainStatusForNamespace(ctx, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statuses).To(ConsistOf(
+				PodDrainStatus{
+					Name:        "draining",
+					Draining:    true,
+					Phase:       finalizer.DrainPhaseDraining,
+					Elapsed:     45 * time.Second,
+					InEndpoints: true,
+				},
+				PodDrainStatus{
+					Name: "running",
+				},
+			))
+		})
+	})
+
+	Describe("CleanupFinalizers", func() {
+		It("should remove our finalizer from pods that are not being deleted", func() {
+			deletionTime := metav1.NewTime(now.Add(-time.Minute))
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:       "running-1",
+							Namespace:  "default",
+							Finalizers: []string{VPAGracefulDrainFinalizer},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:       "running-2",
+							Namespace:  "production",
+							Finalizers: []string{VPAGracefulDrainFinalizer, "example.com/other"},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "draining",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+							Finalizers:        []string{VPAGracefulDrainFinalizer},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:       "unmanaged",
+							Namespace:  "default",
+							Finalizers: []string{"example.com/other"},
+						},
+					},
+				).
+				Build()
+			reconciler.Client = fakeClient
+
+			cleaned, err := reconciler.CleanupFinalizers(ctx, fakeClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cleaned).To(Equal(2))
+
+			pod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "running-1", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(BeEmpty())
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "running-2", Namespace: "production"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(Equal([]string{"example.com/other"}))
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "draining", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "unmanaged", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(Equal([]string{"example.com/other"}))
+		})
+
+		It("should keep going and report an error when an update fails", func() {
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:       "failing",
+							Namespace:  "default",
+							Finalizers: []string{VPAGracefulDrainFinalizer},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:       "running",
+							Namespace:  "default",
+							Finalizers: []string{VPAGracefulDrainFinalizer},
+						},
+					},
+				).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						if obj.GetName() == "failing" {
+							return apierrors.NewServiceUnavailable("apiserver unavailable")
+						}
+						return c.Update(ctx, obj, opts...)
+					},
+				}).
+				Build()
+			reconciler.Client = fakeClient
+
+			cleaned, err := reconciler.CleanupFinalizers(ctx, fakeClient)
+			Expect(err).To(HaveOccurred())
+			Expect(cleaned).To(Equal(1))
+
+			pod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "running", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(BeEmpty())
+		})
+
+		It("should retry on conflicts and record the removal", func() {
+			updates := 0
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "running",
+						Namespace:  "default",
+						Finalizers: []string{VPAGracefulDrainFinalizer, "example.com/other"},
+					},
+				}).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						updates++
+						if updates == 1 {
+							return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, obj.GetName(), nil)
+						}
+						return c.Update(ctx, obj, opts...)
+					},
+				}).
+				Build()
+			reconciler.Client = fakeClient
+			conflicts := metrics.UpdateErrorsTotal.WithLabelValues(metrics.OperationRemoveFinalizer, metrics.UpdateErrorReasonConflict)
+			conflictsBefore := testutil.ToFloat64(conflicts)
+			removedBefore := testutil.ToFloat64(metrics.FinalizerRemovedTotal)
+
+			cleaned, err := reconciler.CleanupFinalizers(ctx, fakeClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cleaned).To(Equal(1))
+			Expect(updates).To(Equal(2))
+			Expect(testutil.ToFloat64(conflicts)).To(Equal(conflictsBefore + 1))
+			Expect(testutil.ToFloat64(metrics.FinalizerRemovedTotal)).To(Equal(removedBefore + 1))
+
+			pod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "running", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(Equal([]string{"example.com/other"}))
+		})
+	})
+
+	Describe("BackfillFinalizers", func() {
+		It("should add our finalizer to existing managed pods that lack it", func() {
+			deletionTime := metav1.NewTime(now.Add(-time.Minute))
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "managed-1",
+							Namespace:   "default",
+							Annotations: map[string]string{"vpa-managed": "true"},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "managed-2",
+							Namespace:   "production",
+							Annotations: map[string]string{"vpa-managed": "true"},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "opted-out",
+							Namespace:   "default",
+							Annotations: map[string]string{"vpa-managed": "false"},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "draining",
+							Namespace:         "default",
+							Annotations:       map[string]string{"vpa-managed": "true"},
+							DeletionTimestamp: &deletionTime,
+							Finalizers:        []string{"example.com/other"},
+						},
+					},
+				).
+				Build()
+			reconciler.Client = fakeClient
+
+			backfilled, err := reconciler.BackfillFinalizers(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backfilled).To(Equal(2))
+
+			pod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "managed-1", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "managed-2", Namespace: "production"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "opted-out", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(BeEmpty())
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "draining", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(Equal([]string{"example.com/other"}))
+		})
+
+		It("should not add finalizers while the controller is disabled", func() {
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "managed",
+							Namespace:   "default",
+							Annotations: map[string]string{"vpa-managed": "true"},
+						},
+					},
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "test-namespace"},
+						Data:       map[string]string{"enabled": "false"},
+					},
+				).
+				Build()
+			reconciler.Client = fakeClient
+
+			backfilled, err := reconciler.BackfillFinalizers(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backfilled).To(Equal(0))
+		})
+
+		It("should skip pods in paused namespaces", func() {
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(
+					&corev1.Namespace{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "paused",
+							Annotations: map[string]string{NamespacePausedAnnotation: "true"},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "managed-1",
+							Namespace:   "paused",
+							Annotations: map[string]string{"vpa-managed": "true"},
+						},
+					},
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "managed-2",
+							Namespace:   "default",
+							Annotations: map[string]string{"vpa-managed": "true"},
+						},
+					},
+				).
+				Build()
+			reconciler.Client = fakeClient
+
+			backfilled, err := reconciler.BackfillFinalizers(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backfilled).To(Equal(1))
+
+			pod := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "managed-1", Namespace: "paused"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(BeEmpty())
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "managed-2", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Finalizers).To(ContainElement(VPAGracefulDrainFinalizer))
+		})
+	})
+
+	Describe("shouldManagePod", func() {
+		var config *Config
+
+		BeforeEach(func() {
+			config = NewDefaultConfig()
+		})
+
+		Context("with namespace selector", func() {
+			BeforeEach(func() {
+				config.NamespaceSelector = &NamespaceSelector{
+					Include: []string{"default"},
+					Exclude: []string{"kube-system"},
+				}
+			})
+
+			It("should return false for excluded namespace", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+				reconciler.Client = fakeClient
+
+				shouldManage := reconciler.shouldManagePod(pod, config)
+				Expect(shouldManage).To(BeFalse())
+			})
+
+			It("should return true for included namespace", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+				reconciler.Client = fakeClient
+
+				shouldManage := reconciler.shouldManagePod(pod, config)
+				Expect(shouldManage).To(BeTrue())
+			})
+		})
+
+		Context("with excludeSystemNamespaces", func() {
+			newPod := func(namespace string) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test-pod",
+						Namespace:   namespace,
+						Annotations: map[string]string{"vpa-managed": "true"},
+					},
+				}
+			}
+
+			BeforeEach(func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "test-namespace"},
+					Data: map[string]string{
+						"excludeSystemNamespaces": "true",
+						"namespaceSelector":       `{"include": ["kube-system", "vpa-system", "default"]}`,
+					},
+				}
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(configMap).Build()
+				reconciler.Client = fakeClient
+				reconciler.PodNamespace = "vpa-system"
+
+				var err error
+				config, err = reconciler.getConfig(ctx, "default")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should skip system namespaces even when the selector includes them", func() {
+				Expect(reconciler.shouldManagePod(newPod("kube-system"), config)).To(BeFalse())
+			})
+
+			It("should skip the controller's own namespace", func() {
+				Expect(reconciler.shouldManagePod(newPod("vpa-system"), config)).To(BeFalse())
+			})
+
+			It("should still apply the selector to other namespaces", func() {
+				Expect(reconciler.shouldManagePod(newPod("default"), config)).To(BeTrue())
+				Expect(reconciler.shouldManagePod(newPod("production"), config)).To(BeFalse())
+			})
+		})
+
+		Context("with excludePodNames", func() {
+			newPod := func(name string) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        name,
+						Namespace:   "default",
+						Annotations: map[string]string{"vpa-managed": "true"},
+					},
+				}
+			}
+
+			BeforeEach(func() {
+				config.ExcludePodNames = []string{"prometheus-*"}
+			})
+
+			It("should not manage a pod matching a pattern", func() {
+				Expect(reconciler.shouldManagePod(newPod("prometheus-0"), config)).To(BeFalse())
+			})
+
+			It("should still manage other pods", func() {
+				Expect(reconciler.shouldManagePod(newPod("web-5d4f8-x7k2p"), config)).To(BeTrue())
+				Expect(reconciler.shouldManagePod(newPod("my-prometheus-0"), config)).To(BeTrue())
+			})
+
+			It("should take precedence over managedExpression", func() {
+				parsed, err := ParseConfig(&corev1.ConfigMap{
+					Data: map[string]string{
+						"managedExpression": "true",
+						"excludePodNames":   `["prometheus-*"]`,
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reconciler.shouldManagePod(newPod("prometheus-0"), parsed)).To(BeFalse())
+				Expect(reconciler.shouldManagePod(newPod("web-0"), parsed)).To(BeTrue())
+			})
+		})
+
+		Context("with mirror and DaemonSet pods", func() {
+			It("should return false for a mirror pod", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "kube-apiserver-node-1",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed":                 "true",
+							corev1.MirrorPodAnnotationKey: "abc123",
+						},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+			})
+
+			It("should return false for a DaemonSet pod by default", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "node-exporter-abcde",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion: "apps/v1",
+								Kind:       "DaemonSet",
+								Name:       "node-exporter",
+								UID:        "ds-uid",
+							},
+						},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+			})
+
+			It("should return true for a DaemonSet pod when manageDaemonSetPods is enabled", func() {
+				config.ManageDaemonSetPods = true
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "node-exporter-abcde",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion: "apps/v1",
+								Kind:       "DaemonSet",
+								Name:       "node-exporter",
+								UID:        "ds-uid",
+							},
+						},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeTrue())
+			})
+		})
+
+		Context("with Job pods", func() {
+			var pod *corev1.Pod
+
+			BeforeEach(func() {
+				pod = &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backup-28000000-abcde",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion: "batch/v1",
+								Kind:       "Job",
+								Name:       "backup-28000000",
+								UID:        "job-uid",
+							},
+						},
+					},
+				}
+			})
+
+			It("should return false for a Job pod by default", func() {
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+			})
+
+			It("should return true for a Job pod when manageJobPods is enabled", func() {
+				config.ManageJobPods = true
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeTrue())
+			})
+		})
+
+		Context("with managedExpression", func() {
+			BeforeEach(func() {
+				configMap := &corev1.ConfigMap{
+					Data: map[string]string{
+						"managedExpression": "pod.metadata.annotations['team'] == 'payments' && size(pod.spec.containers) > 1",
+					},
+				}
+
+				var err error
+				config, err = ParseConfig(configMap)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should return true when the expression matches", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"team": "payments",
+						},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeTrue())
+			})
+
+			It("should take precedence over the vpa-managed annotation", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"team":        "payments",
+							"vpa-managed": "true",
+						},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app"}},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+			})
+
+			It("should return false when the expression fails to evaluate", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+			})
+		})
+
+		Context("with vpa-managed annotation", func() {
+			It("should return true when annotation is 'true'", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa-managed": "true",
+						},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+				reconciler.Client = fakeClient
+
+				shouldManage := reconciler.shouldManagePod(pod, config)
+				Expect(shouldManage).To(BeTrue())
+			})
+
+			It("should return false when annotation is 'false'", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
@@ -462,33 +3025,152 @@ var _ = Describe("PodReconciler", func() {
 				Expect(shouldManage).To(BeTrue())
 			})
 
-			It("should return false for empty vpa resource name annotation", func() {
+			It("should return false for empty vpa resource name annotation", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"vpa.k8s.io/resource-name": "",
+						},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+				reconciler.Client = fakeClient
+
+				shouldManage := reconciler.shouldManagePod(pod, config)
+				Expect(shouldManage).To(BeFalse())
+			})
+		})
+
+		Context("with VPA labels", func() {
+			It("should return true for vpa.k8s.io/managed label", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Labels: map[string]string{
+							"vpa.k8s.io/managed": "true",
+						},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+				reconciler.Client = fakeClient
+
+				shouldManage := reconciler.shouldManagePod(pod, config)
+				Expect(shouldManage).To(BeTrue())
+			})
+		})
+
+		Context("with manageIfAnnotations configured", func() {
+			BeforeEach(func() {
+				config.ManageIfAnnotations = []string{"sidecar.istio.io/status"}
+			})
+
+			It("should manage a pod carrying the configured annotation", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"sidecar.istio.io/status": `{"containers":["istio-proxy"]}`,
+						},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeTrue())
+			})
+
+			It("should skip a pod without the configured annotation", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"team": "payments",
+						},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+			})
+
+			It("should still honor an explicit vpa-managed opt-out", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"sidecar.istio.io/status": `{"containers":["istio-proxy"]}`,
+							"vpa-managed":             "false",
+						},
+					},
+				}
+
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+			})
+		})
+
+		Context("with VPA-managed workload detection", func() {
+			It("should return true for pod with non-round CPU values", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								Kind: "ReplicaSet",
+								Name: "test-rs",
+							},
+						},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "app",
+								Image: "nginx",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU: mustParseQuantity("125m"), // VPA-like value
+									},
+								},
+							},
+						},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+				reconciler.Client = fakeClient
+
+				shouldManage := reconciler.shouldManagePod(pod, config)
+				Expect(shouldManage).To(BeTrue())
+			})
+
+			It("should skip the resource heuristic when disableResourceHeuristic is set", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
 						Namespace: "default",
-						Annotations: map[string]string{
-							"vpa.k8s.io/resource-name": "",
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								Kind: "ReplicaSet",
+								Name: "test-rs",
+							},
 						},
 					},
-				}
-
-				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
-				reconciler.Client = fakeClient
-
-				shouldManage := reconciler.shouldManagePod(pod, config)
-				Expect(shouldManage).To(BeFalse())
-			})
-		})
-
-		Context("with VPA labels", func() {
-			It("should return true for vpa.k8s.io/managed label", func() {
-				pod := &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
-						Namespace: "default",
-						Labels: map[string]string{
-							"vpa.k8s.io/managed": "true",
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "app",
+								Image: "nginx",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU: mustParseQuantity("125m"),
+									},
+								},
+							},
 						},
 					},
 				}
@@ -496,13 +3178,17 @@ var _ = Describe("PodReconciler", func() {
 				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
 				reconciler.Client = fakeClient
 
-				shouldManage := reconciler.shouldManagePod(pod, config)
-				Expect(shouldManage).To(BeTrue())
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeTrue())
+
+				config.DisableResourceHeuristic = true
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+
+				// Explicit annotations still apply
+				pod.Annotations = map[string]string{"vpa-managed": "true"}
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeTrue())
 			})
-		})
 
-		Context("with VPA-managed workload detection", func() {
-			It("should return true for pod with non-round CPU values", func() {
+			It("should classify resource values with the configured heuristic thresholds", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
@@ -521,7 +3207,8 @@ var _ = Describe("PodReconciler", func() {
 								Image: "nginx",
 								Resources: corev1.ResourceRequirements{
 									Requests: corev1.ResourceList{
-										corev1.ResourceCPU: mustParseQuantity("125m"), // VPA-like value
+										corev1.ResourceCPU:    mustParseQuantity("125m"),
+										corev1.ResourceMemory: mustParseQuantity("100M"),
 									},
 								},
 							},
@@ -532,8 +3219,17 @@ var _ = Describe("PodReconciler", func() {
 				fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
 				reconciler.Client = fakeClient
 
-				shouldManage := reconciler.shouldManagePod(pod, config)
-				Expect(shouldManage).To(BeTrue())
+				// 125m is not a multiple of 100m or 50m
+				config.HeuristicMemoryAlignmentBytes = 1000 * 1000
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeTrue())
+
+				// Clusters whose VPA rounds CPU to 25m consider 125m round
+				config.HeuristicCPUModulos = []int64{25}
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeFalse())
+
+				// 100M is not aligned to 1Mi
+				config.HeuristicMemoryAlignmentBytes = 1024 * 1024
+				Expect(reconciler.shouldManagePod(pod, config)).To(BeTrue())
 			})
 
 			It("should return true for pod with non-round memory values", func() {
@@ -732,6 +3428,46 @@ var _ = Describe("PodReconciler", func() {
 			shouldAdd := reconciler.shouldAddFinalizer(pod)
 			Expect(shouldAdd).To(BeTrue())
 		})
+
+		It("should return false when a terminating pod is missing our finalizer", func() {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &metav1.Time{Time: now},
+					Finalizers:        []string{"other-finalizer"},
+				},
+			}
+
+			Expect(reconciler.shouldAddFinalizer(pod)).To(BeFalse())
+		})
+
+		It("should not try to add the finalizer to a pod that started terminating", func() {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &metav1.Time{Time: now},
+					Finalizers:        []string{"other-finalizer"},
+				},
+			}
+
+			updates := 0
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(pod).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						updates++
+						return c.Update(ctx, obj, opts...)
+					},
+				}).
+				Build()
+			reconciler.Client = fakeClient
+
+			Expect(reconciler.addFinalizer(ctx, pod, NewDefaultConfig())).To(Succeed())
+			Expect(updates).To(Equal(0))
+		})
 	})
 
 	Describe("getConfig", func() {
@@ -739,12 +3475,62 @@ var _ = Describe("PodReconciler", func() {
 			fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
 			reconciler.Client = fakeClient
 
-			config, err := reconciler.getConfig(ctx)
+			config, err := reconciler.getConfig(ctx, "default")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(config.GetGracePeriod()).To(Equal(30 * time.Second))
 			Expect(config.GetDrainTimeout()).To(Equal(300 * time.Second))
 		})
 
+		It("should start from the configured defaults", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-config",
+					Namespace: "test-namespace",
+				},
+				Data: map[string]string{
+					"drainTimeoutSeconds": "600",
+				},
+			}
+
+			fakeClient = fake.NewClientBuilder().WithScheme(testScheme).Build()
+			reconciler.Client = fakeClient
+			reconciler.ConfigDefaults = &DefaultConfigOptions{GracePeriodSeconds: 45, DrainTimeoutSeconds: 900}
+
+			config, err := reconciler.getConfig(ctx, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+			Expect(config.GetDrainTimeout()).To(Equal(900 * time.Second))
+
+			Expect(fakeClient.Create(ctx, configMap)).To(Succeed())
+			config, err = reconciler.getConfig(ctx, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+			Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+		})
+
+		It("should ignore awsTargetGroupCheck without a target health client", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-config",
+					Namespace: "test-namespace",
+				},
+				Data: map[string]string{
+					"awsTargetGroupCheck": "true",
+				},
+			}
+
+			fakeClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(configMap).Build()
+			reconciler.Client = fakeClient
+
+			config, err := reconciler.getConfig(ctx, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.AWSTargetGroupCheck).To(BeTrue())
+			Expect(reconciler.drainGate(ctx, config)).To(BeNil())
+
+			reconciler.TargetHealth = emptyTargetHealth{}
+			Expect(reconciler.drainGate(ctx, config)).To(BeAssignableToTypeOf(&finalizer.TargetGroupDrainGate{}))
+		})
+
 		It("should parse config from ConfigMap", func() {
 			configMap := &corev1.ConfigMap{
 				ObjectMeta: metav1.ObjectMeta{
@@ -763,11 +3549,289 @@ var _ = Describe("PodReconciler", func() {
 				Build()
 			reconciler.Client = fakeClient
 
-			config, err := reconciler.getConfig(ctx)
+			config, err := reconciler.getConfig(ctx, "default")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(config.GetGracePeriod()).To(Equal(60 * time.Second))
 			Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
 		})
+
+		Context("with a POD_NAMESPACE fallback", func() {
+			var fallbackConfigMap *corev1.ConfigMap
+
+			BeforeEach(func() {
+				reconciler.PodNamespace = "controller-system"
+				fallbackConfigMap = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "controller-system",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds": "90",
+					},
+				}
+			})
+
+			It("should load the ConfigMap from the controller namespace when the configured one is missing", func() {
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(fallbackConfigMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				config, err := reconciler.getConfig(ctx, "default")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(90 * time.Second))
+				Expect(reconciler.configSource.Load()).To(Equal("controller-system/test-config"))
+			})
+
+			It("should prefer the configured namespace when both exist", func() {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds": "60",
+					},
+				}
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(configMap, fallbackConfigMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				config, err := reconciler.getConfig(ctx, "default")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(60 * time.Second))
+				Expect(reconciler.configSource.Load()).To(Equal("test-namespace/test-config"))
+			})
+		})
+
+		Context("with namespace-specific ConfigMaps", func() {
+			var globalConfigMap *corev1.ConfigMap
+
+			BeforeEach(func() {
+				globalConfigMap = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "test-namespace",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds":  "45",
+						"drainTimeoutSeconds": "600",
+					},
+				}
+			})
+
+			It("should use the global config when the namespace has no ConfigMap", func() {
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(globalConfigMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				config, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+			})
+
+			It("should override only the fields set in the namespace ConfigMap", func() {
+				namespaceConfigMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "team-a",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds": "90",
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(globalConfigMap, namespaceConfigMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				config, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(90 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+
+				otherConfig, err := reconciler.getConfig(ctx, "team-b")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(otherConfig.GetGracePeriod()).To(Equal(45 * time.Second))
+			})
+
+			It("should overlay the namespace ConfigMap on defaults when there is no global ConfigMap", func() {
+				namespaceConfigMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "team-a",
+					},
+					Data: map[string]string{
+						"drainTimeoutSeconds": "900",
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(namespaceConfigMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				config, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(30 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(900 * time.Second))
+			})
+
+			It("should fall back to the global config when the namespace override is invalid", func() {
+				namespaceConfigMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "team-a",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds": "900",
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(globalConfigMap, namespaceConfigMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				config, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+			})
+
+			It("should still fail when the global config itself is invalid", func() {
+				globalConfigMap.Data["gracePeriodSeconds"] = "900"
+				namespaceConfigMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "team-a",
+					},
+					Data: map[string]string{
+						"manageJobPods": "true",
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(globalConfigMap, namespaceConfigMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				_, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("must be greater than gracePeriodSeconds"))
+			})
+
+			It("should ignore controller-wide keys in the namespace ConfigMap", func() {
+				globalConfigMap.Data["auditConfigMapName"] = "drain-audit"
+				namespaceConfigMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config",
+						Namespace: "team-a",
+					},
+					Data: map[string]string{
+						"gracePeriodSeconds":      "90",
+						"enabled":                 "false",
+						"drainCompleteWebhookURL": "http://169.254.169.254/latest",
+						"auditConfigMapName":      "tenant-audit",
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(globalConfigMap, namespaceConfigMap).
+					Build()
+				reconciler.Client = fakeClient
+
+				config, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(90 * time.Second))
+				Expect(config.Enabled).To(BeTrue())
+				Expect(config.DrainCompleteWebhookURL).To(BeEmpty())
+				Expect(config.AuditConfigMapName).To(Equal("drain-audit"))
+			})
+		})
+
+		Context("with DrainPolicies", func() {
+			var globalConfigMap, namespaceConfigMap *corev1.ConfigMap
+
+			BeforeEach(func() {
+				globalConfigMap = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "test-namespace"},
+					Data: map[string]string{
+						"gracePeriodSeconds":  "45",
+						"drainTimeoutSeconds": "600",
+						"tcpPortsOnly":        "false",
+					},
+				}
+				namespaceConfigMap = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "team-a"},
+					Data:       map[string]string{"gracePeriodSeconds": "90"},
+				}
+				teamA := &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tier": "critical"}},
+				}
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(globalConfigMap, namespaceConfigMap, teamA).
+					Build()
+				reconciler.Client = fakeClient
+				reconciler.DrainPolicies = newDrainPolicyResolver(newDrainPolicy("critical", map[string]interface{}{
+					"namespaceSelector":   map[string]interface{}{"matchLabels": map[string]interface{}{"tier": "critical"}},
+					"gracePeriodSeconds":  int64(120),
+					"drainTimeoutSeconds": int64(1200),
+				}))
+			})
+
+			It("should let a matching policy take precedence over the ConfigMaps", func() {
+				config, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(120 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(1200 * time.Second))
+				// Fields the policy doesn't set still come from the ConfigMaps
+				Expect(config.TCPPortsOnly).To(BeFalse())
+			})
+
+			It("should keep the ConfigMaps where no policy matches", func() {
+				config, err := reconciler.getConfig(ctx, "team-b")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+			})
+
+			It("should fall back to the global config when the policies make it invalid", func() {
+				reconciler.DrainPolicies = newDrainPolicyResolver(newDrainPolicy("too-short", map[string]interface{}{
+					"drainTimeoutSeconds": int64(40),
+				}))
+
+				config, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+				Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+			})
+
+			It("should still fail when the global config itself is invalid", func() {
+				globalConfigMap.Data["gracePeriodSeconds"] = "900"
+				Expect(fakeClient.Update(ctx, globalConfigMap)).To(Succeed())
+				reconciler.DrainPolicies = newDrainPolicyResolver(newDrainPolicy("too-short", map[string]interface{}{
+					"drainTimeoutSeconds": int64(40),
+				}))
+
+				_, err := reconciler.getConfig(ctx, "team-a")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("must be greater than gracePeriodSeconds"))
+			})
+		})
 	})
 
 	Describe("SetupWithManager", func() {
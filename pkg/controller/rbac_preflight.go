@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// endpointLookupAccess are the permissions endpoint-based connection checks depend on.
+// The controller reads through a cluster-wide cache, so they are checked at cluster scope.
+var endpointLookupAccess = []authorizationv1.ResourceAttributes{
+	{Verb: "list", Resource: "services"},
+	{Verb: "list", Resource: "endpoints"},
+}
+
+// PreflightEndpointAccess asks the API server, through SelfSubjectAccessReviews, whether
+// the controller may list services and endpoints, and returns the permissions it lacks as
+// "verb resource". Without them every endpoint check would fail, so the caller should fall
+// back to grace-period-only drains (see PodReconciler.GracePeriodOnly).
+func PreflightEndpointAccess(ctx context.Context, reviews authorizationclient.SelfSubjectAccessReviewInterface) ([]string, error) {
+	var missing []string
+	for _, attributes := range endpointLookupAccess {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+		}
+		result, err := reviews.Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review access to %s %s: %w", attributes.Verb, attributes.Resource, err)
+		}
+		if !result.Status.Allowed {
+			missing = append(missing, attributes.Verb+" "+attributes.Resource)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("PreflightEndpointAccess", func() {
+	var (
+		ctx       context.Context
+		clientset *fake.Clientset
+		allowed   map[string]bool
+		reviewErr error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		allowed = map[string]bool{"services": true, "endpoints": true}
+		reviewErr = nil
+
+		clientset = fake.NewSimpleClientset()
+		clientset.PrependReactor("create", "selfsubjectaccessreviews",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if reviewErr != nil {
+					return true, nil, reviewErr
+				}
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				Expect(attributes.Verb).To(Equal("list"))
+				Expect(attributes.Namespace).To(BeEmpty())
+				review.Status.Allowed = allowed[attributes.Resource]
+				return true, review, nil
+			})
+	})
+
+	It("should report nothing missing when access is allowed", func() {
+		missing, err := PreflightEndpointAccess(ctx, clientset.AuthorizationV1().SelfSubjectAccessReviews())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(BeEmpty())
+	})
+
+	It("should report the denied permissions", func() {
+		allowed["endpoints"] = false
+
+		missing, err := PreflightEndpointAccess(ctx, clientset.AuthorizationV1().SelfSubjectAccessReviews())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(Equal([]string{"list endpoints"}))
+	})
+
+	It("should return an error when the review fails", func() {
+		reviewErr = errors.New("connection refused")
+
+		_, err := PreflightEndpointAccess(ctx, clientset.AuthorizationV1().SelfSubjectAccessReviews())
+		Expect(err).To(HaveOccurred())
+	})
+})
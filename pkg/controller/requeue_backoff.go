@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RequeueBackoff doubles a pod's requeue interval each time its drain is still held by
+// active connections, so long-lived connections don't keep the endpoint checks at full
+// rate. A check without connections starts the pod over at the base interval.
+type RequeueBackoff struct {
+	mu       sync.Mutex
+	attempts map[types.UID]int
+}
+
+func NewRequeueBackoff() *RequeueBackoff {
+	return &RequeueBackoff{attempts: make(map[types.UID]int)}
+}
+
+// Next returns the interval before the pod's next check: base on the first check that
+// found connections, doubling on every consecutive one up to maxInterval. A maxInterval
+// not above base disables the backoff.
+func (b *RequeueBackoff) Next(uid types.UID, base, maxInterval time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	attempts := b.attempts[uid]
+	interval := base
+	for range attempts {
+		if interval >= maxInterval {
+			break
+		}
+		interval *= 2
+	}
+	if interval > maxInterval {
+		interval = max(maxInterval, base)
+	}
+	b.attempts[uid] = attempts + 1
+	return interval
+}
+
+// Reset starts the pod over at the base interval, once its connections cleared
+func (b *RequeueBackoff) Reset(uid types.UID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.attempts, uid)
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequeueBackoff", func() {
+	var backoff *RequeueBackoff
+
+	BeforeEach(func() {
+		backoff = NewRequeueBackoff()
+	})
+
+	It("should double the interval up to the cap", func() {
+		Expect(backoff.Next("uid-1", 10*time.Second, time.Minute)).To(Equal(10 * time.Second))
+		Expect(backoff.Next("uid-1", 10*time.Second, time.Minute)).To(Equal(20 * time.Second))
+		Expect(backoff.Next("uid-1", 10*time.Second, time.Minute)).To(Equal(40 * time.Second))
+		Expect(backoff.Next("uid-1", 10*time.Second, time.Minute)).To(Equal(time.Minute))
+		Expect(backoff.Next("uid-1", 10*time.Second, time.Minute)).To(Equal(time.Minute))
+	})
+
+	It("should start over at the base interval after a reset", func() {
+		backoff.Next("uid-1", 10*time.Second, time.Minute)
+		backoff.Next("uid-1", 10*time.Second, time.Minute)
+		backoff.Reset("uid-1")
+		Expect(backoff.Next("uid-1", 10*time.Second, time.Minute)).To(Equal(10 * time.Second))
+	})
+
+	It("should back off each pod separately", func() {
+		backoff.Next("uid-1", 10*time.Second, time.Minute)
+		backoff.Next("uid-1", 10*time.Second, time.Minute)
+		Expect(backoff.Next("uid-2", 10*time.Second, time.Minute)).To(Equal(10 * time.Second))
+	})
+
+	It("should not back off when the cap is not above the base interval", func() {
+		Expect(backoff.Next("uid-1", 10*time.Second, 10*time.Second)).To(Equal(10 * time.Second))
+		Expect(backoff.Next("uid-1", 10*time.Second, 10*time.Second)).To(Equal(10 * time.Second))
+		Expect(backoff.Next("uid-1", 10*time.Second, 5*time.Second)).To(Equal(10 * time.Second))
+	})
+})
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ValidateConfigFile reads a ConfigMap manifest (YAML or JSON) from disk and parses it the
+// way the controller would, returning the effective configuration. It needs no cluster, so
+// CI pipelines can lint drain configuration before applying it.
+func ValidateConfigFile(path string, bounds ConfigBounds, opts ...DefaultConfigOption) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var configMap corev1.ConfigMap
+	if err := yaml.UnmarshalStrict(data, &configMap); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if configMap.Kind != "" && configMap.Kind != "ConfigMap" {
+		return nil, fmt.Errorf("%s is a %s, not a ConfigMap", path, configMap.Kind)
+	}
+
+	return ParseConfigWithBounds(&configMap, bounds, opts...)
+}
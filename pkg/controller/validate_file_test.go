@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateConfigFile", func() {
+	var dir string
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("should return the effective configuration of a valid YAML manifest", func() {
+		path := writeFile("config.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: vpa-graceful-drain-config
+  namespace: kube-system
+data:
+  gracePeriodSeconds: "45"
+  connectionCheckMode: "endpoints"
+`)
+
+		config, err := ValidateConfigFile(path, DefaultConfigBounds())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.GetGracePeriod()).To(Equal(45 * time.Second))
+		Expect(config.GetDrainTimeout()).To(Equal(300 * time.Second))
+	})
+
+	It("should accept a JSON manifest and apply default config options", func() {
+		path := writeFile("config.json", `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"gracePeriodSeconds": "20"}}`)
+
+		config, err := ValidateConfigFile(path, DefaultConfigBounds(), WithDefaultDrainTimeout(600))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.GetGracePeriod()).To(Equal(20 * time.Second))
+		Expect(config.GetDrainTimeout()).To(Equal(600 * time.Second))
+	})
+
+	It("should report an invalid setting", func() {
+		path := writeFile("config.yaml", `kind: ConfigMap
+data:
+  gracePeriodSeconds: "-1"
+`)
+
+		_, err := ValidateConfigFile(path, DefaultConfigBounds())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("gracePeriodSeconds"))
+	})
+
+	It("should reject settings outside the bounds", func() {
+		path := writeFile("config.yaml", `kind: ConfigMap
+data:
+  drainTimeoutSeconds: "10800"
+`)
+
+		_, err := ValidateConfigFile(path, DefaultConfigBounds())
+		Expect(err).To(HaveOccurred())
+
+		_, err = ValidateConfigFile(path, ConfigBounds{MaxGracePeriodSeconds: 3600, MaxDrainTimeoutSeconds: 14400})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject a manifest of another kind", func() {
+		path := writeFile("secret.yaml", `kind: Secret
+metadata:
+  name: not-a-configmap
+`)
+
+		_, err := ValidateConfigFile(path, DefaultConfigBounds())
+		Expect(err).To(MatchError(ContainSubstring("not a ConfigMap")))
+	})
+
+	It("should reject malformed and unknown fields", func() {
+		_, err := ValidateConfigFile(writeFile("typo.yaml", `kind: ConfigMap
+dta:
+  gracePeriodSeconds: "45"
+`), DefaultConfigBounds())
+		Expect(err).To(HaveOccurred())
+
+		_, err = ValidateConfigFile(writeFile("broken.yaml", "data: [\n"), DefaultConfigBounds())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error for a missing file", func() {
+		_, err := ValidateConfigFile(filepath.Join(dir, "missing.yaml"), DefaultConfigBounds())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const drainCompleteWebhookTimeout = 5 * time.Second
+
+// HTTPDoer is the subset of *http.Client used for webhook callbacks, replaceable in tests
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DrainCompletePayload is POSTed to drainCompleteWebhookURL when a pod finishes draining
+type DrainCompletePayload struct {
+	Pod         string    `json:"pod"`
+	Namespace   string    `json:"namespace"`
+	UID         string    `json:"uid"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+func (r *PodReconciler) webhookClient() HTTPDoer {
+	if r.WebhookClient == nil {
+		return &http.Client{Timeout: drainCompleteWebhookTimeout}
+	}
+	return r.WebhookClient
+}
+
+// notifyDrainComplete POSTs the completion payload to the configured webhook
+func (r *PodReconciler) notifyDrainComplete(ctx context.Context, webhookURL string, pod *corev1.Pod) error {
+	body, err := json.Marshal(DrainCompletePayload{
+		Pod:         pod.Name,
+		Namespace:   pod.Namespace,
+		UID:         string(pod.UID),
+		CompletedAt: r.clock().Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, drainCompleteWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.webhookClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("drain complete webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type mockHTTPDoer struct {
+	requests   []*http.Request
+	bodies     [][]byte
+	statusCode int
+	err        error
+}
+
+func (m *mockHTTPDoer) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	m.requests = append(m.requests, req)
+	m.bodies = append(m.bodies, body)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &http.Response{
+		StatusCode: m.statusCode,
+		Body:       io.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+var _ = Describe("Drain complete webhook", func() {
+	var (
+		ctx        context.Context
+		reconciler *PodReconciler
+		doer       *mockHTTPDoer
+		testScheme *runtime.Scheme
+		pod        *corev1.Pod
+		config     *Config
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		testScheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(testScheme)).To(Succeed())
+
+		doer = &mockHTTPDoer{statusCode: http.StatusOK}
+
+		deletionTime := metav1.NewTime(time.Now().Add(-400 * time.Second))
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-pod",
+				Namespace:         "default",
+				UID:               "pod-uid",
+				DeletionTimestamp: &deletionTime,
+				Finalizers:        []string{VPAGracefulDrainFinalizer, "example.com/other"},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+			},
+		}
+
+		config = NewDefaultConfig()
+		config.DrainCompleteWebhookURL = "http://traffic-manager.example.com/drained"
+
+		reconciler = &PodReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(pod).
+				Build(),
+			Scheme:        testScheme,
+			Tracker:       NewDrainTracker(),
+			WebhookClient: doer,
+		}
+	})
+
+	It("should POST the completion payload before removing the finalizer", func() {
+		_, err := reconciler.handlePodDeletion(ctx, pod, config)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(doer.requests).To(HaveLen(1))
+		Expect(doer.requests[0].Method).To(Equal(http.MethodPost))
+		Expect(doer.requests[0].URL.String()).To(Equal("http://traffic-manager.example.com/drained"))
+		Expect(doer.requests[0].Header.Get("Content-Type")).To(Equal("application/json"))
+
+		var payload DrainCompletePayload
+		Expect(json.Unmarshal(doer.bodies[0], &payload)).To(Succeed())
+		Expect(payload.Pod).To(Equal("test-pod"))
+		Expect(payload.Namespace).To(Equal("default"))
+		Expect(payload.UID).To(Equal("pod-uid"))
+		Expect(payload.CompletedAt).To(BeTemporally("~", time.Now(), 5*time.Second))
+	})
+
+	It("should stamp completedAt from the reconciler clock", func() {
+		completedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		reconciler.Clock = fixedClock{now: completedAt}
+
+		Expect(reconciler.notifyDrainComplete(ctx, config.DrainCompleteWebhookURL, pod)).To(Succeed())
+
+		var payload DrainCompletePayload
+		Expect(json.Unmarshal(doer.bodies[0], &payload)).To(Succeed())
+		Expect(payload.CompletedAt).To(Equal(completedAt))
+	})
+
+	It("should still remove the finalizer when the webhook fails", func() {
+		doer.err = errors.New("connection refused")
+
+		_, err := reconciler.handlePodDeletion(ctx, pod, config)
+		Expect(err).ToNot(HaveOccurred())
+
+		updatedPod := &corev1.Pod{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-pod", Namespace: "default"}, updatedPod)).To(Succeed())
+		Expect(updatedPod.Finalizers).ToNot(ContainElement(VPAGracefulDrainFinalizer))
+	})
+
+	It("should report non-2xx responses as errors", func() {
+		doer.statusCode = http.StatusInternalServerError
+
+		err := reconciler.notifyDrainComplete(ctx, config.DrainCompleteWebhookURL, pod)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("500"))
+	})
+
+	It("should not call the webhook when no URL is configured", func() {
+		config.DrainCompleteWebhookURL = ""
+
+		_, err := reconciler.handlePodDeletion(ctx, pod, config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(doer.requests).To(BeEmpty())
+	})
+})
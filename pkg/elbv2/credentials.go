@@ -0,0 +1,241 @@
+package elbv2
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// credentialsRefreshMargin is how long before expiry temporary credentials are renewed
+const credentialsRefreshMargin = 5 * time.Minute
+
+// Credentials sign AWS requests; SessionToken and Expires are set for temporary credentials
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+// CredentialsProvider returns the credentials to sign the next request with
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// EnvCredentials reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+type EnvCredentials struct{}
+
+func (EnvCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	credentials := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+	}
+	return credentials, nil
+}
+
+// WebIdentityCredentials exchanges a projected service account token for temporary
+// credentials with STS AssumeRoleWithWebIdentity, as EKS IAM roles for service accounts
+// do. The credentials are cached until shortly before they expire.
+type WebIdentityCredentials struct {
+	RoleARN     string
+	TokenFile   string
+	SessionName string
+	// Endpoint is the STS endpoint, e.g. https://sts.us-east-1.amazonaws.com/
+	Endpoint string
+	Client   *http.Client
+
+	mu     sync.Mutex
+	cached Credentials
+	now    func() time.Time
+}
+
+type assumeRoleWithWebIdentityResponse struct {
+	AccessKeyID     string    `xml:"AssumeRoleWithWebIdentityResult>Credentials>AccessKeyId"`
+	SecretAccessKey string    `xml:"AssumeRoleWithWebIdentityResult>Credentials>SecretAccessKey"`
+	SessionToken    string    `xml:"AssumeRoleWithWebIdentityResult>Credentials>SessionToken"`
+	Expiration      time.Time `xml:"AssumeRoleWithWebIdentityResult>Credentials>Expiration"`
+}
+
+func (w *WebIdentityCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.now == nil {
+		w.now = time.Now
+	}
+	if w.cached.AccessKeyID != "" && w.now().Add(credentialsRefreshMargin).Before(w.cached.Expires) {
+		return w.cached, nil
+	}
+
+	// The kubelet rotates the projected token, so it is read again for every exchange
+	token, err := os.ReadFile(w.TokenFile)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("Action", "AssumeRoleWithWebIdentity")
+	form.Set("Version", "2011-06-15")
+	form.Set("RoleArn", w.RoleARN)
+	form.Set("RoleSessionName", w.SessionName)
+	form.Set("WebIdentityToken", strings.TrimSpace(string(token)))
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr errorResponse
+		if xml.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+			return Credentials{}, fmt.Errorf("AssumeRoleWithWebIdentity failed: %s: %s", apiErr.Code, apiErr.Message)
+		}
+		return Credentials{}, fmt.Errorf("AssumeRoleWithWebIdentity returned status %d", resp.StatusCode)
+	}
+
+	var parsed assumeRoleWithWebIdentityResponse
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return Credentials{}, fmt.Errorf("invalid AssumeRoleWithWebIdentity response: %w", err)
+	}
+	w.cached = Credentials{
+		AccessKeyID:     parsed.AccessKeyID,
+		SecretAccessKey: parsed.SecretAccessKey,
+		SessionToken:    parsed.SessionToken,
+		Expires:         parsed.Expiration,
+	}
+	return w.cached, nil
+}
+
+// DefaultCredentials uses the IAM role for service accounts when EKS has injected
+// AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE, and the static environment credentials
+// otherwise
+func DefaultCredentials(region string) CredentialsProvider {
+	roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleARN == "" || tokenFile == "" {
+		return EnvCredentials{}
+	}
+
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = "vpa-graceful-drain-controller"
+	}
+	return &WebIdentityCredentials{
+		RoleARN:     roleARN,
+		TokenFile:   tokenFile,
+		SessionName: sessionName,
+		Endpoint:    fmt.Sprintf("https://sts.%s.amazonaws.com/", region),
+	}
+}
+
+// signRequest adds an AWS Signature Version 4 Authorization header to the request, whose
+// body must be passed in since it can't be read back
+func signRequest(req *http.Request, body []byte, credentials Credentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery sorts the query by key and value and escapes it the way SigV4 expects
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, sigV4Escape(key)+"="+sigV4Escape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func sigV4Escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
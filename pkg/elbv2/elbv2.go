@@ -0,0 +1,119 @@
+package elbv2
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+const (
+	apiVersion     = "2015-12-01"
+	service        = "elasticloadbalancing"
+	requestTimeout = 10 * time.Second
+)
+
+// Client calls the ELBv2 DescribeTargetHealth API over its Query protocol. It implements
+// finalizer.TargetHealthDescriber without pulling in the AWS SDK.
+type Client struct {
+	region      string
+	endpoint    string
+	credentials CredentialsProvider
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewClient builds a client for the region's ELBv2 endpoint. A nil client gets a short
+// default timeout.
+func NewClient(region string, credentials CredentialsProvider, client *http.Client) *Client {
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	return &Client{
+		region:      region,
+		endpoint:    fmt.Sprintf("https://elasticloadbalancing.%s.amazonaws.com/", region),
+		credentials: credentials,
+		client:      client,
+		now:         time.Now,
+	}
+}
+
+type describeTargetHealthResponse struct {
+	Descriptions []struct {
+		Target struct {
+			ID   string `xml:"Id"`
+			Port int32  `xml:"Port"`
+		} `xml:"Target"`
+		TargetHealth struct {
+			State string `xml:"State"`
+		} `xml:"TargetHealth"`
+	} `xml:"DescribeTargetHealthResult>TargetHealthDescriptions>member"`
+}
+
+type errorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+// DescribeTargetHealth lists the targets registered in the target group with their state
+func (c *Client) DescribeTargetHealth(ctx context.Context, targetGroupARN string) ([]finalizer.TargetHealth, error) {
+	credentials, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("Action", "DescribeTargetHealth")
+	form.Set("Version", apiVersion)
+	form.Set("TargetGroupArn", targetGroupARN)
+	body := form.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signRequest(req, []byte(body), credentials, c.region, service, c.now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr errorResponse
+		if xml.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+			return nil, fmt.Errorf("DescribeTargetHealth failed: %s: %s", apiErr.Code, apiErr.Message)
+		}
+		return nil, fmt.Errorf("DescribeTargetHealth returned status %d", resp.StatusCode)
+	}
+
+	var parsed describeTargetHealthResponse
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid DescribeTargetHealth response: %w", err)
+	}
+
+	targets := make([]finalizer.TargetHealth, 0, len(parsed.Descriptions))
+	for _, description := range parsed.Descriptions {
+		targets = append(targets, finalizer.TargetHealth{
+			ID:    description.Target.ID,
+			Port:  description.Target.Port,
+			State: description.TargetHealth.State,
+		})
+	}
+	return targets, nil
+}
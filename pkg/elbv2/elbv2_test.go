@@ -0,0 +1,214 @@
+package elbv2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/finalizer"
+)
+
+func TestELBv2(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ELBv2 Suite")
+}
+
+type staticCredentials Credentials
+
+func (s staticCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+var _ = Describe("signRequest", func() {
+	It("should match the AWS get-vanilla test vector", func() {
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		credentials := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+		signRequest(req, nil, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+		Expect(req.Header.Get("X-Amz-Date")).To(Equal("20150830T123600Z"))
+		Expect(req.Header.Get("Authorization")).To(Equal("AWS4-HMAC-SHA256 " +
+			"Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+			"SignedHeaders=host;x-amz-date, " +
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"))
+	})
+
+	It("should sign the session token of temporary credentials", func() {
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		signRequest(req, nil, Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"},
+			"us-east-1", "service", time.Now())
+
+		Expect(req.Header.Get("X-Amz-Security-Token")).To(Equal("token"))
+		Expect(req.Header.Get("Authorization")).To(ContainSubstring("SignedHeaders=host;x-amz-date;x-amz-security-token"))
+	})
+})
+
+var _ = Describe("Client", func() {
+	var (
+		ctx          context.Context
+		server       *httptest.Server
+		lastForm     url.Values
+		lastAuth     string
+		responseCode int
+		responseBody string
+		client       *Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		responseCode = http.StatusOK
+		responseBody = `<DescribeTargetHealthResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <DescribeTargetHealthResult>
+    <TargetHealthDescriptions>
+      <member>
+        <Target><Id>10.0.0.1</Id><Port>8080</Port></Target>
+        <TargetHealth><State>draining</State></TargetHealth>
+      </member>
+      <member>
+        <Target><Id>10.0.0.2</Id><Port>8080</Port></Target>
+        <TargetHealth><State>healthy</State></TargetHealth>
+      </member>
+    </TargetHealthDescriptions>
+  </DescribeTargetHealthResult>
+</DescribeTargetHealthResponse>`
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.ParseForm()).To(Succeed())
+			lastForm = req.PostForm
+			lastAuth = req.Header.Get("Authorization")
+			w.WriteHeader(responseCode)
+			w.Write([]byte(responseBody))
+		}))
+		DeferCleanup(server.Close)
+
+		client = NewClient("us-east-1", staticCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil)
+		client.endpoint = server.URL + "/"
+	})
+
+	It("should use the regional endpoint", func() {
+		Expect(NewClient("eu-west-1", EnvCredentials{}, nil).endpoint).
+			To(Equal("https://elasticloadbalancing.eu-west-1.amazonaws.com/"))
+	})
+
+	It("should describe the target group's targets", func() {
+		arn := "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web/abc"
+
+		targets, err := client.DescribeTargetHealth(ctx, arn)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets).To(Equal([]finalizer.TargetHealth{
+			{ID: "10.0.0.1", Port: 8080, State: "draining"},
+			{ID: "10.0.0.2", Port: 8080, State: "healthy"},
+		}))
+		Expect(lastForm.Get("Action")).To(Equal("DescribeTargetHealth"))
+		Expect(lastForm.Get("TargetGroupArn")).To(Equal(arn))
+		Expect(lastAuth).To(ContainSubstring("/us-east-1/elasticloadbalancing/aws4_request"))
+	})
+
+	It("should report API errors", func() {
+		responseCode = http.StatusBadRequest
+		responseBody = `<ErrorResponse><Error><Code>TargetGroupNotFound</Code><Message>not found</Message></Error></ErrorResponse>`
+
+		_, err := client.DescribeTargetHealth(ctx, "arn")
+		Expect(err).To(MatchError(ContainSubstring("TargetGroupNotFound")))
+	})
+})
+
+var _ = Describe("WebIdentityCredentials", func() {
+	var (
+		ctx       context.Context
+		server    *httptest.Server
+		exchanges int
+		lastForm  url.Values
+		provider  *WebIdentityCredentials
+		now       time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		exchanges = 0
+		now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			exchanges++
+			Expect(req.ParseForm()).To(Succeed())
+			lastForm = req.PostForm
+			w.Write([]byte(`<AssumeRoleWithWebIdentityResponse>
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASIA</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>2024-01-01T13:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+		}))
+		DeferCleanup(server.Close)
+
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("jwt\n"), 0o600)).To(Succeed())
+
+		provider = &WebIdentityCredentials{
+			RoleARN:     "arn:aws:iam::123456789012:role/drain",
+			TokenFile:   tokenFile,
+			SessionName: "test",
+			Endpoint:    server.URL + "/",
+			now:         func() time.Time { return now },
+		}
+	})
+
+	It("should exchange the token for temporary credentials", func() {
+		credentials, err := provider.Retrieve(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credentials.AccessKeyID).To(Equal("ASIA"))
+		Expect(credentials.SessionToken).To(Equal("session"))
+		Expect(lastForm.Get("RoleArn")).To(Equal("arn:aws:iam::123456789012:role/drain"))
+		Expect(lastForm.Get("WebIdentityToken")).To(Equal("jwt"))
+	})
+
+	It("should reuse the credentials until shortly before they expire", func() {
+		_, err := provider.Retrieve(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		now = now.Add(30 * time.Minute)
+		_, err = provider.Retrieve(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exchanges).To(Equal(1))
+
+		now = now.Add(26 * time.Minute)
+		_, err = provider.Retrieve(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exchanges).To(Equal(2))
+	})
+})
+
+var _ = Describe("DefaultCredentials", func() {
+	It("should use web identity when EKS injected a role", func() {
+		GinkgoT().Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/drain")
+		GinkgoT().Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "/var/run/secrets/eks.amazonaws.com/serviceaccount/token")
+
+		provider, ok := DefaultCredentials("us-east-1").(*WebIdentityCredentials)
+		Expect(ok).To(BeTrue())
+		Expect(provider.Endpoint).To(Equal("https://sts.us-east-1.amazonaws.com/"))
+	})
+
+	It("should fall back to the environment credentials", func() {
+		GinkgoT().Setenv("AWS_ROLE_ARN", "")
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "")
+
+		provider := DefaultCredentials("us-east-1")
+		Expect(provider).To(Equal(EnvCredentials{}))
+		_, err := provider.Retrieve(context.Background())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,33 @@
+package finalizer
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// scaleGraceByAge shortens the grace period of pods that were young when deleted, when
+// ageScaledGrace is enabled. The grace period grows linearly with the pod's age at deletion
+// from ageScaledGraceMinSeconds to the configured grace period, reached at
+// ageScaledGraceFullAgeSeconds. Pods that haven't started keep the configured grace period.
+func (d *DrainHandler) scaleGraceByAge(pod *corev1.Pod, window DrainWindow) DrainWindow {
+	if !d.config.GetAgeScaledGrace() || pod.DeletionTimestamp == nil || pod.Status.StartTime == nil {
+		return window
+	}
+
+	minGrace := d.config.GetAgeScaledGraceMin()
+	fullAge := d.config.GetAgeScaledGraceFullAge()
+	if fullAge <= 0 || minGrace >= window.GracePeriod {
+		return window
+	}
+
+	age := pod.DeletionTimestamp.Sub(pod.Status.StartTime.Time)
+	if age >= fullAge {
+		return window
+	}
+	age = max(age, 0)
+
+	scaled := minGrace + time.Duration(float64(window.GracePeriod-minGrace)*float64(age)/float64(fullAge))
+	window.GracePeriod = scaled.Truncate(time.Second)
+	return window
+}
@@ -0,0 +1,144 @@
+package finalizer
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen short-circuits every call until the cooldown has passed
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe through to decide whether to close again
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreaker stops calling a failing dependency after failureThreshold consecutive
+// failures within window, and probes it again once cooldown has passed. It is safe for
+// concurrent use and meant to be shared across reconciles.
+type CircuitBreaker struct {
+	failureThreshold int
+	window           time.Duration
+	cooldown         time.Duration
+	clock            Clock
+	onStateChange    func(CircuitState)
+
+	mu             sync.Mutex
+	state          CircuitState
+	failures       int
+	firstFailureAt time.Time
+	openedAt       time.Time
+	probing        bool
+}
+
+func NewCircuitBreaker(failureThreshold int, window, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		window:           window,
+		cooldown:         cooldown,
+		clock:            RealClock{},
+		state:            CircuitClosed,
+	}
+}
+
+// WithClock replaces the clock used for the failure window and cooldown
+func (b *CircuitBreaker) WithClock(clock Clock) *CircuitBreaker {
+	b.clock = clock
+	return b
+}
+
+// WithStateChangeHook registers fn to be called with the new state on every transition
+func (b *CircuitBreaker) WithStateChangeHook(fn func(CircuitState)) *CircuitBreaker {
+	b.onStateChange = fn
+	return b
+}
+
+// State returns the current state, moving an open circuit to half-open once the cooldown has passed
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkCooldown()
+	return b.state
+}
+
+// Allow reports whether a call may proceed. Callers that are allowed through must report
+// the outcome with RecordSuccess or RecordFailure.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkCooldown()
+	switch b.state {
+	case CircuitOpen:
+		return false
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess closes the circuit and resets the failure count
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.transition(CircuitClosed)
+}
+
+// RecordFailure counts a failure, opening the circuit when the threshold is reached
+// within the window or when a half-open probe fails
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	b.probing = false
+
+	if b.state == CircuitHalfOpen {
+		b.openedAt = now
+		b.transition(CircuitOpen)
+		return
+	}
+
+	if b.failures == 0 || now.Sub(b.firstFailureAt) > b.window {
+		b.failures = 0
+		b.firstFailureAt = now
+	}
+	b.failures++
+
+	if b.state == CircuitClosed && b.failures >= b.failureThreshold {
+		b.openedAt = now
+		b.transition(CircuitOpen)
+	}
+}
+
+func (b *CircuitBreaker) checkCooldown() {
+	if b.state == CircuitOpen && b.clock.Now().Sub(b.openedAt) >= b.cooldown {
+		b.transition(CircuitHalfOpen)
+	}
+}
+
+func (b *CircuitBreaker) transition(state CircuitState) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	if state != CircuitClosed {
+		b.failures = 0
+	}
+	if b.onStateChange != nil {
+		b.onStateChange(state)
+	}
+}
@@ -0,0 +1,107 @@
+package finalizer
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CircuitBreaker", func() {
+	var (
+		clock       *fakeClock
+		breaker     *CircuitBreaker
+		transitions []CircuitState
+	)
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		transitions = nil
+		breaker = NewCircuitBreaker(3, time.Minute, 30*time.Second).
+			WithClock(clock).
+			WithStateChangeHook(func(state CircuitState) {
+				transitions = append(transitions, state)
+			})
+	})
+
+	fail := func(times int) {
+		for i := 0; i < times; i++ {
+			Expect(breaker.Allow()).To(BeTrue())
+			breaker.RecordFailure()
+		}
+	}
+
+	It("should start closed", func() {
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+		Expect(breaker.Allow()).To(BeTrue())
+	})
+
+	It("should stay closed below the failure threshold", func() {
+		fail(2)
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+		Expect(transitions).To(BeEmpty())
+	})
+
+	It("should open after the threshold is reached within the window", func() {
+		fail(3)
+		Expect(breaker.State()).To(Equal(CircuitOpen))
+		Expect(breaker.Allow()).To(BeFalse())
+		Expect(transitions).To(Equal([]CircuitState{CircuitOpen}))
+	})
+
+	It("should not count failures outside the window together", func() {
+		fail(2)
+		clock.now = clock.now.Add(2 * time.Minute)
+		fail(1)
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+	})
+
+	It("should reset the failure count on success", func() {
+		fail(2)
+		breaker.RecordSuccess()
+		fail(2)
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+	})
+
+	Context("when open", func() {
+		BeforeEach(func() {
+			fail(3)
+		})
+
+		It("should stay open during the cooldown", func() {
+			clock.now = clock.now.Add(29 * time.Second)
+			Expect(breaker.Allow()).To(BeFalse())
+			Expect(breaker.State()).To(Equal(CircuitOpen))
+		})
+
+		It("should half-open after the cooldown and allow a single probe", func() {
+			clock.now = clock.now.Add(30 * time.Second)
+			Expect(breaker.State()).To(Equal(CircuitHalfOpen))
+			Expect(breaker.Allow()).To(BeTrue())
+			Expect(breaker.Allow()).To(BeFalse())
+		})
+
+		It("should close when the half-open probe succeeds", func() {
+			clock.now = clock.now.Add(30 * time.Second)
+			Expect(breaker.Allow()).To(BeTrue())
+			breaker.RecordSuccess()
+
+			Expect(breaker.State()).To(Equal(CircuitClosed))
+			Expect(breaker.Allow()).To(BeTrue())
+			Expect(transitions).To(Equal([]CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}))
+		})
+
+		It("should reopen when the half-open probe fails", func() {
+			clock.now = clock.now.Add(30 * time.Second)
+			Expect(breaker.Allow()).To(BeTrue())
+			breaker.RecordFailure()
+
+			Expect(breaker.State()).To(Equal(CircuitOpen))
+			Expect(breaker.Allow()).To(BeFalse())
+			Expect(transitions).To(Equal([]CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen}))
+
+			clock.now = clock.now.Add(30 * time.Second)
+			Expect(breaker.State()).To(Equal(CircuitHalfOpen))
+		})
+	})
+})
@@ -0,0 +1,15 @@
+package finalizer
+
+import "time"
+
+// Clock provides the current time so drain timing can be tested deterministically
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock reads the wall clock
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
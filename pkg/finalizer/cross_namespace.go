@@ -0,0 +1,58 @@
+package finalizer
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// crossNamespaceEndpoints returns, as namespace/name, the endpoints outside the pod's
+// namespace that list the pod, e.g. selectorless services maintained by a service mesh.
+// Service selectors can't reach across namespaces, so the endpoints are searched directly
+// in the configured namespaces or, when none are configured, cluster-wide.
+func (d *DrainHandler) crossNamespaceEndpoints(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	namespaces := d.config.GetCrossNamespaceEndpoints()
+	if len(namespaces) == 0 {
+		// The empty namespace lists across all namespaces
+		namespaces = []string{""}
+	}
+
+	var found []string
+	for _, namespace := range namespaces {
+		if namespace == pod.Namespace {
+			continue
+		}
+
+		var endpointsList corev1.EndpointsList
+		listCtx, cancelList := d.apiCallContext(ctx)
+		err := d.client.List(listCtx, &endpointsList, client.InNamespace(namespace))
+		cancelList()
+		if err != nil {
+			if apierrors.IsForbidden(err) {
+				log.FromContext(ctx).Info("WARNING: not allowed to list endpoints, skipping cross-namespace endpoint check",
+					"namespace", namespace, "error", err.Error())
+				continue
+			}
+			return nil, err
+		}
+
+		for i := range endpointsList.Items {
+			endpoints := &endpointsList.Items[i]
+			if endpoints.Namespace == pod.Namespace {
+				continue
+			}
+			if endpointsContain(endpoints, pod, false) {
+				found = append(found, endpoints.Namespace+"/"+endpoints.Name)
+			}
+		}
+	}
+
+	if len(found) > 0 {
+		log.FromContext(ctx).V(1).Info("Pod found in endpoints of other namespaces",
+			"pod", pod.Name, "endpoints", found)
+	}
+	return found, nil
+}
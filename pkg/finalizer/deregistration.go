@@ -0,0 +1,59 @@
+package finalizer
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DeregisteredAtAnnotation records when the pod was first seen outside all service
+// endpoints, so the post-deregistration timer survives controller restarts
+const DeregisteredAtAnnotation = "vpa-graceful-drain.cho.github.io/deregistered-at"
+
+// checkDeregistration runs the post-deregistration timer: once the pod has left every
+// service's endpoints, connections through the load balancer close within its idle timeout,
+// so the drain completes postDeregistrationSeconds later regardless of the grace period.
+// handled is false while the pod is still in endpoints, leaving the regular drain in charge.
+func (d *DrainHandler) checkDeregistration(ctx context.Context, pod *corev1.Pod) (completed, handled bool, err error) {
+	logger := log.FromContext(ctx)
+	now := d.clock.Now()
+
+	if value, ok := pod.Annotations[DeregisteredAtAnnotation]; ok {
+		deregisteredAt, parseErr := time.Parse(time.RFC3339, value)
+		if parseErr == nil {
+			sinceDeregistration := now.Sub(deregisteredAt)
+			if sinceDeregistration >= d.config.GetPostDeregistration() {
+				logger.Info("Post-deregistration period elapsed, graceful drain completed",
+					"pod", pod.Name, "sinceDeregistration", sinceDeregistration.String())
+				return true, true, nil
+			}
+			return false, true, nil
+		}
+		logger.Info("WARNING: ignoring invalid deregistration annotation",
+			"pod", pod.Name, "annotation", DeregisteredAtAnnotation, "value", value)
+	}
+
+	services, err := d.podEndpointServices(ctx, pod)
+	if err != nil {
+		return false, false, err
+	}
+	if len(services) > 0 {
+		return false, false, nil
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = map[string]string{}
+	}
+	podCopy.Annotations[DeregisteredAtAnnotation] = now.UTC().Format(time.RFC3339)
+	if err := d.client.Patch(ctx, podCopy, client.MergeFrom(pod)); err != nil {
+		return false, false, err
+	}
+
+	logger.Info("Pod left all service endpoints, starting post-deregistration timer",
+		"pod", pod.Name, "postDeregistration", d.config.GetPostDeregistration().String())
+	return false, true, nil
+}
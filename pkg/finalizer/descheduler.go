@@ -0,0 +1,42 @@
+package finalizer
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DeschedulerEvictionAnnotation marks a pod as evictable by the Kubernetes Descheduler
+const DeschedulerEvictionAnnotation = "descheduler.alpha.kubernetes.io/evict"
+
+// IsDeschedulerEviction reports whether the pod's deletion looks like an eviction by the
+// Descheduler: the pod carries the Descheduler's eviction annotation and was evicted
+// through the eviction API rather than deleted
+func IsDeschedulerEviction(pod *corev1.Pod) bool {
+	if _, ok := pod.Annotations[DeschedulerEvictionAnnotation]; !ok {
+		return false
+	}
+	return IsEviction(pod)
+}
+
+// shortenForDescheduler caps the grace period at the configured descheduler grace when
+// fastDrainOnDescheduler is enabled and the Descheduler evicted the pod, so its pacing
+// doesn't read the held eviction as a failure
+func (d *DrainHandler) shortenForDescheduler(ctx context.Context, pod *corev1.Pod, window DrainWindow) DrainWindow {
+	if !d.config.GetFastDrainOnDescheduler() || !IsDeschedulerEviction(pod) {
+		return window
+	}
+
+	deschedulerGrace := d.config.GetDeschedulerGrace()
+	if window.GracePeriod <= deschedulerGrace {
+		return window
+	}
+
+	log.FromContext(ctx).V(1).Info("Pod evicted by the descheduler, shortening grace period",
+		"pod", pod.Name,
+		"gracePeriod", window.GracePeriod.String(),
+		"deschedulerGrace", deschedulerGrace.String())
+	window.GracePeriod = deschedulerGrace
+	return window
+}
@@ -0,0 +1,81 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DrainGateAnnotation names the custom resource, in the pod's namespace, that reports
+// whether the pod's traffic has been drained externally
+const DrainGateAnnotation = "vpa-graceful-drain.cho.github.io/drain-gate"
+
+// DefaultDrainGateFieldPath is where the drain gate resource reports that traffic is drained
+const DefaultDrainGateFieldPath = "{.status.drained}"
+
+// ExternalDrainGate reports whether something outside the cluster's endpoints, e.g. a
+// traffic management system, has finished draining the pod. The drain doesn't complete
+// before the gate opens, but the drain timeout still applies.
+type ExternalDrainGate interface {
+	IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error)
+}
+
+// AlwaysDrained is the default gate, which never holds a drain
+type AlwaysDrained struct{}
+
+func (AlwaysDrained) IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	return true, nil
+}
+
+// AllDrainGates opens once every one of its gates has
+type AllDrainGates []ExternalDrainGate
+
+func (gates AllDrainGates) IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	for _, gate := range gates {
+		drained, err := gate.IsDrained(ctx, pod)
+		if err != nil || !drained {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// CustomResourceDrainGate reads the custom resource named by the pod's DrainGateAnnotation
+// and opens once the boolean at FieldPath is true. Pods without the annotation aren't gated.
+type CustomResourceDrainGate struct {
+	Reader client.Reader
+	GVK    schema.GroupVersionKind
+	// FieldPath is a JSONPath expression such as {.status.drained}
+	FieldPath string
+}
+
+func (g *CustomResourceDrainGate) IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	name, ok := pod.Annotations[DrainGateAnnotation]
+	if !ok || name == "" {
+		return true, nil
+	}
+
+	resource := &unstructured.Unstructured{}
+	resource.SetGroupVersionKind(g.GVK)
+	if err := g.Reader.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: name}, resource); err != nil {
+		return false, fmt.Errorf("failed to get drain gate %s %s: %w", g.GVK.Kind, name, err)
+	}
+
+	path := jsonpath.New("drainGate")
+	if err := path.Parse(g.FieldPath); err != nil {
+		return false, fmt.Errorf("invalid drain gate field path %q: %w", g.FieldPath, err)
+	}
+	results, err := path.FindResults(resource.Object)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		// The status isn't reported yet
+		return false, nil
+	}
+
+	drained, ok := results[0][0].Interface().(bool)
+	return ok && drained, nil
+}
@@ -0,0 +1,110 @@
+package finalizer
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("CustomResourceDrainGate", func() {
+	var (
+		ctx  context.Context
+		gvk  schema.GroupVersionKind
+		pod  *corev1.Pod
+		gate *CustomResourceDrainGate
+	)
+
+	newTrafficPolicy := func(status map[string]interface{}) *unstructured.Unstructured {
+		policy := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		policy.SetGroupVersionKind(gvk)
+		policy.SetNamespace("default")
+		policy.SetName("web-policy")
+		if status != nil {
+			policy.Object["status"] = status
+		}
+		return policy
+	}
+
+	newGate := func(objects ...client.Object) *CustomResourceDrainGate {
+		scheme := runtime.NewScheme()
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+
+		return &CustomResourceDrainGate{
+			Reader:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			GVK:       gvk,
+			FieldPath: DefaultDrainGateFieldPath,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		gvk = schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "TrafficPolicy"}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web-0",
+				Namespace:   "default",
+				Annotations: map[string]string{DrainGateAnnotation: "web-policy"},
+			},
+		}
+	})
+
+	It("should open once the resource reports the pod drained", func() {
+		gate = newGate(newTrafficPolicy(map[string]interface{}{"drained": true}))
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+
+	It("should stay closed while the resource reports traffic", func() {
+		gate = newGate(newTrafficPolicy(map[string]interface{}{"drained": false}))
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+	})
+
+	It("should stay closed while the resource has no status yet", func() {
+		gate = newGate(newTrafficPolicy(nil))
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+	})
+
+	It("should read the configured field path", func() {
+		gate = newGate(newTrafficPolicy(map[string]interface{}{
+			"conditions": map[string]interface{}{"trafficRemoved": true},
+		}))
+		gate.FieldPath = "{.status.conditions.trafficRemoved}"
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+
+	It("should report an error when the referenced resource is missing", func() {
+		gate = newGate()
+
+		_, err := gate.IsDrained(ctx, pod)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not gate pods without the annotation", func() {
+		gate = newGate()
+		pod.Annotations = nil
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+})
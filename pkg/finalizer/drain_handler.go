@@ -2,87 +2,609 @@ package finalizer
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/conntrack"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/grpchealth"
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
+)
+
+const (
+	// ForceCompleteAnnotation lets operators end a stuck drain via kubectl annotate
+	ForceCompleteAnnotation = "vpa-graceful-drain.cho.github.io/force-complete"
+	// SkipDrainAnnotation opts a pod out of draining: its finalizer is released as soon as it is deleted
+	SkipDrainAnnotation = "vpa-graceful-drain.cho.github.io/skip-drain"
+	// StatusAnnotation holds the JSON-encoded DrainStatus of a draining pod
+	StatusAnnotation = "vpa-graceful-drain.cho.github.io/status"
+	// TrafficContainersAnnotation lists, comma-separated, the containers whose ports carry
+	// traffic, overriding the trafficContainers setting for the pod
+	TrafficContainersAnnotation = "vpa-graceful-drain.cho.github.io/traffic-containers"
+)
+
+// Completion reasons returned by HandleGracefulDrain
+const (
+	CompletionReasonNotDeleting    = "not-deleting"
+	CompletionReasonHardTimeout    = "hard-timeout"
+	CompletionReasonForceCompleted = "force-completed"
+	CompletionReasonNotEviction    = "not-eviction"
+	CompletionReasonTimeout        = "timeout"
+	CompletionReasonPodCompleted   = "pod-completed"
+	CompletionReasonNotReady       = "not-ready"
+	CompletionReasonNoConnections  = "no-connections"
+	CompletionReasonNotStarted     = "not-started"
+	CompletionReasonSkipDrain      = "skip-drain"
+	// CompletionReasonForceDeleted means the pod was deleted with --grace-period=0
+	CompletionReasonForceDeleted = "force-deleted"
+	// CompletionReasonContainersTerminated means every container exited while the phase still read Running
+	CompletionReasonContainersTerminated = "containers-terminated"
+	// CompletionReasonNamespaceTerminating is set by the reconciler, not HandleGracefulDrain
+	CompletionReasonNamespaceTerminating = "namespace-terminating"
+	// CompletionReasonDisabled is set by the reconciler when the controller is switched off
+	CompletionReasonDisabled = "disabled"
+	// CompletionReasonUnmanaged is set by the reconciler when a running pod stops being managed
+	CompletionReasonUnmanaged = "unmanaged"
+	// CompletionReasonNamespacePaused is set by the reconciler when the pod's namespace is paused
+	CompletionReasonNamespacePaused = "namespace-paused"
+	// CompletionReasonDeregistered means the post-deregistration period ran out
+	CompletionReasonDeregistered = "deregistered"
+)
+
+const (
+	// ConnectionCheckModeEndpoints treats membership in a Service's endpoints as active traffic
+	ConnectionCheckModeEndpoints = "endpoints"
+	// ConnectionCheckModeConntrack counts established TCP connections via a ConnTracker
+	ConnectionCheckModeConntrack = "conntrack"
+	// ConnectionCheckModeGRPCHealth waits for the pod's gRPC health service to report NOT_SERVING
+	ConnectionCheckModeGRPCHealth = "grpc-health"
+)
+
+const (
+	// OnConnectionCheckErrorRetry fails the reconcile so the connection check is retried
+	OnConnectionCheckErrorRetry = "retry"
+	// OnConnectionCheckErrorAssumeDrained treats a failed check as no active connections
+	OnConnectionCheckErrorAssumeDrained = "assume-drained"
+	// OnConnectionCheckErrorAssumeServing treats a failed check as active connections, so the
+	// pod waits for the drain timeout
+	OnConnectionCheckErrorAssumeServing = "assume-serving"
 )
 
+// DrainStatus is the drain progress reported on the pod for observability
+type DrainStatus struct {
+	Phase              DrainPhase `json:"phase"`
+	ElapsedSeconds     int64      `json:"elapsedSeconds"`
+	GracePeriodSeconds int64      `json:"gracePeriodSeconds"`
+	DeadlineSeconds    int64      `json:"deadlineSeconds"`
+	// EndpointServices lists the services whose endpoints still contain the pod
+	EndpointServices []string `json:"endpointServices,omitempty"`
+}
+
+// DrainResult is the outcome of one HandleGracefulDrain evaluation
+type DrainResult struct {
+	// Completed reports whether the pod may be released
+	Completed bool
+	// Reason is why the drain completed; empty while it continues
+	Reason string
+	// RequeueAfter is when to evaluate the drain again; zero once it completed
+	RequeueAfter time.Duration
+	// Elapsed is the time since the pod's deletion
+	Elapsed time.Duration
+	// HadActiveConnections reports whether this evaluation found active connections
+	HadActiveConnections bool
+	// Phase is the drain phase computed by this evaluation
+	Phase DrainPhase
+}
+
+// DrainWindow is the grace period and drain timeout applied to a pod
+type DrainWindow struct {
+	GracePeriod  time.Duration
+	DrainTimeout time.Duration
+}
+
 type Config interface {
 	GetGracePeriod() time.Duration
 	GetDrainTimeout() time.Duration
+	GetHardDeadlineBuffer() time.Duration
+	GetOnlyManageEvictions() bool
+	GetTCPPortsOnly() bool
+	GetConnectionCheckMode() string
+	GetTreatMissingReadyAsReady() bool
+	GetOwnerKindOverrides() map[string]DrainWindow
+	GetAPICallTimeout() time.Duration
+	GetRespectDeletionGracePeriod() bool
+	GetTrafficWeightThreshold() float64
+	GetOnTimeoutWithConnections() string
+	GetTimeoutExtension() time.Duration
+	GetMaxTimeoutExtensions() int
+	GetEndpointSettle() time.Duration
+	GetFastDrainOnNodeCordon() bool
+	GetNodeCordonGrace() time.Duration
+	GetFastDrainOnDescheduler() bool
+	GetDeschedulerGrace() time.Duration
+	GetActiveTrafficAnnotations() []string
+	GetPostDeregistration() time.Duration
+	GetTrafficContainers() []string
+	GetMinimumServing() time.Duration
+	GetConsiderHostPort() bool
+	GetCrossNamespaceEndpointCheck() bool
+	GetCrossNamespaceEndpoints() []string
+	GetServingPhases() []string
+	GetConnectionPollInterval() time.Duration
+	GetKnativeAware() bool
+	GetKnativeSettle() time.Duration
+	GetOnConnectionCheckError() string
+	GetAgeScaledGrace() bool
+	GetAgeScaledGraceMin() time.Duration
+	GetAgeScaledGraceFullAge() time.Duration
+	GetUseEndpointSlices() bool
+	GetEndpointCheckConcurrency() int
+	GetWaitForVolumeDetach() bool
 }
 
 type DrainHandler struct {
-	client client.Client
-	config Config
+	client          client.Client
+	config          Config
+	connTracker     conntrack.ConnTracker
+	grpcHealth      grpchealth.HealthChecker
+	clock           Clock
+	endpointBreaker *CircuitBreaker
+	trafficWeights  TrafficWeightProvider
+	drainGate       ExternalDrainGate
+	// forbiddenWarning limits the missing-RBAC warning to once, see WithForbiddenWarningOnce
+	forbiddenWarning *sync.Once
+	// serviceIndex narrows service lookups with ServiceSelectorIndexField
+	serviceIndex bool
+	// skipEndpointCheck treats endpoints mode pods as having no connections
+	skipEndpointCheck bool
+	// endpointServices holds the services that still listed the pod at the last endpoints check
+	endpointServices []string
 }
 
 func NewDrainHandler(client client.Client, config Config) *DrainHandler {
 	return &DrainHandler{
-		client: client,
-		config: config,
+		client:           client,
+		config:           config,
+		clock:            RealClock{},
+		trafficWeights:   AnnotationTrafficWeight{},
+		drainGate:        AlwaysDrained{},
+		forbiddenWarning: &sync.Once{},
 	}
 }
 
-func (d *DrainHandler) HandleGracefulDrain(ctx context.Context, pod *corev1.Pod) (bool, error) {
+// WithClock replaces the clock used for all drain timing
+func (d *DrainHandler) WithClock(clock Clock) *DrainHandler {
+	d.clock = clock
+	return d
+}
+
+// WithConnTracker sets the tracker used in conntrack connection check mode
+func (d *DrainHandler) WithConnTracker(tracker conntrack.ConnTracker) *DrainHandler {
+	d.connTracker = tracker
+	return d
+}
+
+// WithoutEndpointCheck makes the endpoints connection check report no connections, so
+// drains complete after the grace period, for when the controller can't read endpoints
+func (d *DrainHandler) WithoutEndpointCheck() *DrainHandler {
+	d.skipEndpointCheck = true
+	return d
+}
+
+// WithGRPCHealthChecker sets the checker used in grpc-health connection check mode
+func (d *DrainHandler) WithGRPCHealthChecker(checker grpchealth.HealthChecker) *DrainHandler {
+	d.grpcHealth = checker
+	return d
+}
+
+// WithEndpointBreaker guards the endpoints check with a breaker shared across reconciles
+func (d *DrainHandler) WithEndpointBreaker(breaker *CircuitBreaker) *DrainHandler {
+	d.endpointBreaker = breaker
+	return d
+}
+
+// WithForbiddenWarningOnce shares the missing-RBAC warning across reconciles, so it is
+// logged once rather than by every handler
+func (d *DrainHandler) WithForbiddenWarningOnce(once *sync.Once) *DrainHandler {
+	d.forbiddenWarning = once
+	return d
+}
+
+// WithTrafficWeightProvider replaces the source of pod traffic weights
+func (d *DrainHandler) WithTrafficWeightProvider(provider TrafficWeightProvider) *DrainHandler {
+	d.trafficWeights = provider
+	return d
+}
+
+// WithDrainGate holds drains until the external gate reports the pod drained
+func (d *DrainHandler) WithDrainGate(gate ExternalDrainGate) *DrainHandler {
+	d.drainGate = gate
+	return d
+}
+
+// WithServiceSelectorIndex looks up a pod's services through ServiceSelectorIndexField,
+// which must be registered with the client's cache
+func (d *DrainHandler) WithServiceSelectorIndex() *DrainHandler {
+	d.serviceIndex = true
+	return d
+}
+
+// EndpointServices returns the services whose endpoints still contained the pod at the
+// last endpoints check made by this handler
+func (d *DrainHandler) EndpointServices() []string {
+	return d.endpointServices
+}
+
+// HandleGracefulDrain evaluates the pod's drain: whether it may be released and why, or
+// when to look at it again
+func (d *DrainHandler) HandleGracefulDrain(ctx context.Context, pod *corev1.Pod) (DrainResult, error) {
 	logger := log.FromContext(ctx)
 
 	if pod.DeletionTimestamp == nil {
 		logger.V(1).Info("Pod has no deletion timestamp, skipping drain")
-		return true, nil
+		return DrainResult{Completed: true, Reason: CompletionReasonNotDeleting, Phase: DrainPhasePending}, nil
 	}
 
-	gracePeriod := d.config.GetGracePeriod()
-	drainTimeout := d.config.GetDrainTimeout()
+	window := d.drainWindow(ctx, pod)
 
-	timeSinceDeletion := time.Since(pod.DeletionTimestamp.Time)
+	timeSinceDeletion := d.clock.Now().Sub(pod.DeletionTimestamp.Time)
+	hadConnections := false
 
-	if timeSinceDeletion < gracePeriod {
-		logger.Info("Graceful drain period not yet elapsed",
-			"elapsed", timeSinceDeletion.String(),
-			"gracePeriod", gracePeriod.String(),
-			"pod", pod.Name)
-		return false, nil
+	complete := func(reason string) (DrainResult, error) {
+		return DrainResult{
+			Completed:            true,
+			Reason:               reason,
+			Elapsed:              timeSinceDeletion,
+			HadActiveConnections: hadConnections,
+			Phase:                ComputeDrainPhase(true, timeSinceDeletion, window.GracePeriod, true, reason),
+		}, nil
+	}
+	wait := func() (DrainResult, error) {
+		return DrainResult{
+			RequeueAfter:         d.requeueAfter(window, timeSinceDeletion),
+			Elapsed:              timeSinceDeletion,
+			HadActiveConnections: hadConnections,
+			Phase:                ComputeDrainPhase(true, timeSinceDeletion, window.GracePeriod, false, ""),
+		}, nil
+	}
+	fail := func(err error) (DrainResult, error) {
+		return DrainResult{
+			Elapsed:              timeSinceDeletion,
+			HadActiveConnections: hadConnections,
+			Phase:                ComputeDrainPhase(true, timeSinceDeletion, window.GracePeriod, false, ""),
+		}, err
 	}
 
-	if timeSinceDeletion > drainTimeout {
-		logger.Info("Drain timeout exceeded, allowing pod deletion",
+	// Safety net: past the hard deadline nothing may hold the pod, whatever the drain state
+	hardDeadline := window.DrainTimeout + d.config.GetHardDeadlineBuffer()
+	if timeSinceDeletion > hardDeadline {
+		logger.Error(fmt.Errorf("drain exceeded hard deadline"), "Force-removing finalizer",
 			"elapsed", timeSinceDeletion.String(),
-			"drainTimeout", drainTimeout.String(),
+			"hardDeadline", hardDeadline.String(),
 			"pod", pod.Name)
-		return true, nil
+		metrics.HardTimeoutTotal.Inc()
+		return complete(CompletionReasonHardTimeout)
+	}
+
+	if IsForceCompleteRequested(pod) {
+		logger.Info("Force-complete annotation set, skipping graceful drain", "pod", pod.Name)
+		return complete(CompletionReasonForceCompleted)
+	}
+
+	// A zero deletion grace period is an explicit request to remove the pod right away
+	if IsForceDeleted(pod) {
+		logger.Info("Pod was force-deleted, skipping graceful drain", "pod", pod.Name)
+		return complete(CompletionReasonForceDeleted)
+	}
+
+	if IsSkipDrainRequested(pod) {
+		logger.Info("Pod opted out of draining, completing immediately", "pod", pod.Name)
+		return complete(CompletionReasonSkipDrain)
 	}
 
-	// If pod has completed successfully or failed, drain is complete
-	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
-		logger.Info("Pod has completed, graceful drain completed",
+	if d.config.GetOnlyManageEvictions() && !IsEviction(pod) {
+		logger.Info("Deletion does not look like an eviction, skipping graceful drain", "pod", pod.Name)
+		return complete(CompletionReasonNotEviction)
+	}
+
+	// A pod that never got a container running (e.g. stuck in ContainerCreating) can't
+	// have served any traffic, so there is nothing to wait for
+	if neverStarted(pod) {
+		logger.Info("Pod never started, skipping graceful drain",
 			"pod", pod.Name,
 			"phase", pod.Status.Phase)
-		return true, nil
+		return complete(CompletionReasonNotStarted)
 	}
 
-	isReady := d.isPodReady(pod)
-	if !isReady {
-		logger.Info("Pod is not ready, graceful drain completed", "pod", pod.Name)
-		return true, nil
+	gracePeriod := window.GracePeriod
+	drainTimeout := window.DrainTimeout
+
+	// The post-deregistration timer replaces the grace period and connection checks once
+	// the pod has left endpoints, up to the drain timeout
+	if d.config.GetPostDeregistration() > 0 && d.config.GetConnectionCheckMode() != ConnectionCheckModeConntrack &&
+		d.config.GetConnectionCheckMode() != ConnectionCheckModeGRPCHealth &&
+		timeSinceDeletion <= drainTimeout && !d.servesHostPort(pod) {
+		completed, handled, err := d.checkDeregistration(ctx, pod)
+		if err != nil {
+			logger.Error(err, "Failed to check endpoint deregistration")
+			return fail(err)
+		}
+		if completed {
+			detached, err := d.volumesDetached(ctx, pod)
+			if err != nil {
+				return fail(err)
+			}
+			if !detached {
+				return wait()
+			}
+			return complete(CompletionReasonDeregistered)
+		}
+		if handled {
+			return wait()
+		}
 	}
 
-	hasActiveConnections, err := d.checkActiveConnections(ctx, pod)
-	if err != nil {
-		logger.Error(err, "Failed to check active connections")
-		return false, err
+	if timeSinceDeletion > drainTimeout {
+		extended, err := d.extendTimeout(ctx, pod)
+		if err != nil {
+			logger.Error(err, "Failed to extend drain timeout")
+			return fail(err)
+		}
+		if extended {
+			hadConnections = true
+			return wait()
+		}
 	}
 
-	if !hasActiveConnections {
-		logger.Info("No active connections detected, graceful drain completed", "pod", pod.Name)
-		return true, nil
+	phase := pod.Status.Phase
+	ready := d.isPodReady(pod)
+	hasConnections := true
+
+	// Pod-specific checks only run once the grace period is over and before the timeout
+	// or a terminal phase decides the drain on their own
+	terminal := phase == corev1.PodSucceeded || phase == corev1.PodFailed
+	if timeSinceDeletion >= gracePeriod && timeSinceDeletion <= drainTimeout && !terminal {
+		// The phase lags behind during shutdown; once every container has exited nothing can serve
+		if allContainersTerminated(pod) {
+			if sidecar, serving := d.servingSidecar(pod); serving {
+				logger.V(1).Info("Containers terminated but a native sidecar is still running", "pod", pod.Name, "sidecar", sidecar)
+			} else {
+				detached, err := d.volumesDetached(ctx, pod)
+				if err != nil {
+					return fail(err)
+				}
+				if !detached {
+					return wait()
+				}
+				logger.Info("All containers have terminated, graceful drain completed", "pod", pod.Name)
+				return complete(CompletionReasonContainersTerminated)
+			}
+		}
+
+		// One-shot pods are driven by completion rather than traffic, so readiness and
+		// endpoints say nothing useful; wait for Succeeded/Failed (bounded by the timeout)
+		if pod.Spec.RestartPolicy == corev1.RestartPolicyNever {
+			logger.Info("Pod with restartPolicy Never still running, waiting for completion", "pod", pod.Name)
+			return wait()
+		}
+
+		drained, err := d.drainGate.IsDrained(ctx, pod)
+		if err != nil {
+			logger.Error(err, "Failed to check external drain gate")
+			return fail(err)
+		}
+		if !drained {
+			logger.V(1).Info("External drain gate has not opened yet, continuing drain", "pod", pod.Name)
+			return wait()
+		}
+
+		// Knative may keep routing to a pod that is no longer ready, so neither readiness
+		// nor the usual endpoints check is trusted until it is out of every Endpoints
+		if d.config.GetKnativeAware() && IsKnativePod(pod) {
+			routed, err := d.knativeRouted(ctx, pod)
+			if err != nil {
+				logger.Error(err, "Failed to check knative routing")
+				return fail(err)
+			}
+			if routed {
+				hadConnections = true
+				return wait()
+			}
+		}
+
+		// Native sidecars outlive the app containers during termination and may still proxy
+		// connections, so the pod keeps counting as serving while one is running
+		if !ready {
+			if sidecar, serving := d.servingSidecar(pod); serving {
+				logger.V(1).Info("Pod is not ready but a native sidecar is still running, checking connections",
+					"pod", pod.Name, "sidecar", sidecar)
+				ready = true
+			}
+		}
+
+		if ready {
+			hasConnections, err = d.checkActiveConnections(ctx, pod)
+			if err != nil {
+				switch d.config.GetOnConnectionCheckError() {
+				case OnConnectionCheckErrorAssumeDrained:
+					logger.Error(err, "Failed to check active connections, assuming the pod is drained")
+					hasConnections = false
+				case OnConnectionCheckErrorAssumeServing:
+					logger.Error(err, "Failed to check active connections, assuming the pod is still serving")
+					hasConnections = true
+				default:
+					logger.Error(err, "Failed to check active connections")
+					return fail(err)
+				}
+			}
+			hadConnections = hasConnections
+		}
+	}
+
+	completed, reason := EvaluateDrain(d.clock.Now(), pod.DeletionTimestamp.Time, gracePeriod, drainTimeout, phase, ready, hasConnections)
+	// The timeout bounds the wait for volumes like every other check
+	if completed && reason != CompletionReasonTimeout {
+		detached, err := d.volumesDetached(ctx, pod)
+		if err != nil {
+			return fail(err)
+		}
+		completed = detached
+	}
+	if completed {
+		logger.Info("Graceful drain completed",
+			"pod", pod.Name,
+			"reason", reason,
+			"elapsed", timeSinceDeletion.String(),
+			"gracePeriod", gracePeriod.String(),
+			"drainTimeout", drainTimeout.String())
+	} else {
+		logger.V(1).Info("Graceful drain not yet completed",
+			"pod", pod.Name,
+			"elapsed", timeSinceDeletion.String(),
+			"gracePeriod", gracePeriod.String())
+	}
+	if completed {
+		return complete(reason)
+	}
+	return wait()
+}
+
+// requeueAfter waits out the rest of the grace period in one step, since nothing completes
+// the drain before it ends, and polls connections at the configured interval after that
+func (d *DrainHandler) requeueAfter(window DrainWindow, elapsed time.Duration) time.Duration {
+	if elapsed >= window.GracePeriod {
+		return d.config.GetConnectionPollInterval()
+	}
+
+	remaining := time.Duration(int64(window.GracePeriod.Seconds())-int64(elapsed.Seconds())) * time.Second
+	if remaining < time.Second {
+		return time.Second
+	}
+	return remaining
+}
+
+// EvaluateDrain is the time and state based drain decision, free of API calls. A drain
+// waits out the grace period, then completes at the drain timeout, once the pod has
+// finished, once it is no longer ready, or once it has no active connections, in that order.
+// hasConnections is only consulted for a ready pod between the grace period and the timeout.
+func EvaluateDrain(now, deletionTime time.Time, gracePeriod, drainTimeout time.Duration, phase corev1.PodPhase, ready, hasConnections bool) (bool, string) {
+	elapsed := now.Sub(deletionTime)
+	switch {
+	case elapsed < gracePeriod:
+		return false, ""
+	case elapsed > drainTimeout:
+		return true, CompletionReasonTimeout
+	case phase == corev1.PodSucceeded || phase == corev1.PodFailed:
+		return true, CompletionReasonPodCompleted
+	case !ready:
+		return true, CompletionReasonNotReady
+	case !hasConnections:
+		return true, CompletionReasonNoConnections
+	default:
+		return false, ""
+	}
+}
+
+// DrainStatus reports the progress of an unfinished drain based on time since deletion
+func (d *DrainHandler) DrainStatus(ctx context.Context, pod *corev1.Pod) DrainStatus {
+	window := d.drainWindow(ctx, pod)
+	status := DrainStatus{
+		Phase:              DrainPhasePending,
+		GracePeriodSeconds: int64(window.GracePeriod.Seconds()),
+		DeadlineSeconds:    int64(window.DrainTimeout.Seconds()),
+	}
+
+	if pod.DeletionTimestamp == nil {
+		return status
+	}
+
+	elapsed := d.clock.Now().Sub(pod.DeletionTimestamp.Time)
+	status.ElapsedSeconds = int64(elapsed.Seconds())
+	status.Phase = ComputeDrainPhase(true, elapsed, window.GracePeriod, false, "")
+	return status
+}
+
+// neverStarted reports whether the pod never got a container running: it is Pending, or
+// every container is still waiting without having run before (e.g. ContainerCreating)
+func neverStarted(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodPending {
+		return true
+	}
+	if pod.Status.Phase != corev1.PodRunning || len(pod.Status.ContainerStatuses) == 0 {
+		return false
 	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting == nil || status.LastTerminationState.Terminated != nil {
+			return false
+		}
+	}
+	return true
+}
 
-	logger.Info("Pod still has active connections, continuing drain", "pod", pod.Name)
-	return false, nil
+// allContainersTerminated reports whether the pod reports a terminated state for every container
+func allContainersTerminated(pod *corev1.Pod) bool {
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// IsForceCompleteRequested reports whether an operator asked to skip the drain for this pod
+func IsForceCompleteRequested(pod *corev1.Pod) bool {
+	return pod.Annotations[ForceCompleteAnnotation] == "true"
+}
+
+// inServingPhase reports whether the pod's phase is one of the configured serving phases,
+// defaulting to Running alone
+func (d *DrainHandler) inServingPhase(pod *corev1.Pod) bool {
+	phases := d.config.GetServingPhases()
+	if len(phases) == 0 {
+		return pod.Status.Phase == corev1.PodRunning
+	}
+	return slices.Contains(phases, string(pod.Status.Phase))
+}
+
+// IsSkipDrainRequested reports whether the pod opted out of graceful draining
+func IsSkipDrainRequested(pod *corev1.Pod) bool {
+	return pod.Annotations[SkipDrainAnnotation] == "true"
+}
+
+// IsForceDeleted reports whether the pod was deleted with a zero grace period, as
+// `kubectl delete --grace-period=0 --force` does
+func IsForceDeleted(pod *corev1.Pod) bool {
+	return pod.DeletionGracePeriodSeconds != nil && *pod.DeletionGracePeriodSeconds == 0
+}
+
+// IsEviction reports whether the pod's deletion looks like an eviction (VPA updater,
+// node drain, preemption, taint manager or kubelet pressure) rather than a manual delete.
+// The API server marks disruptions with a DisruptionTarget condition; the kubelet marks
+// node-pressure evictions with the "Evicted" status reason. A plain `kubectl delete` sets neither.
+func IsEviction(pod *corev1.Pod) bool {
+	if pod.Status.Reason == "Evicted" {
+		return true
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.DisruptionTarget {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
 }
 
 func (d *DrainHandler) isPodReady(pod *corev1.Pod) bool {
@@ -91,32 +613,27 @@ func (d *DrainHandler) isPodReady(pod *corev1.Pod) bool {
 			return condition.Status == corev1.ConditionTrue
 		}
 	}
-	return false
+	// A pod deleted during startup may not report Ready yet but be about to serve
+	return d.config.GetTreatMissingReadyAsReady()
 }
 
 func (d *DrainHandler) checkActiveConnections(ctx context.Context, pod *corev1.Pod) (bool, error) {
 	logger := log.FromContext(ctx)
 
-	// If pod is not running (succeeded, failed, pending), no active connections
-	if pod.Status.Phase != corev1.PodRunning {
-		logger.V(1).Info("Pod is not running, no active connections", "pod", pod.Name, "phase", pod.Status.Phase)
+	// Only pods in a serving phase (by default just Running) can have active connections
+	if !d.inServingPhase(pod) {
+		logger.V(1).Info("Pod is not in a serving phase, no active connections", "pod", pod.Name, "phase", pod.Status.Phase)
 		return false, nil
 	}
 
-	if len(pod.Spec.Containers) == 0 {
-		return false, nil
+	// External tooling can vouch for live traffic that endpoints and ports don't show
+	if annotation, ok := d.activeTrafficAnnotation(pod); ok {
+		logger.V(1).Info("Pod is annotated as actively serving traffic", "pod", pod.Name, "annotation", annotation)
+		return true, nil
 	}
 
 	// Check if pod has any exposed ports that might have active connections
-	hasExposedPorts := false
-	for _, container := range pod.Spec.Containers {
-		if len(container.Ports) > 0 {
-			hasExposedPorts = true
-			break
-		}
-	}
-
-	if !hasExposedPorts {
+	if !d.hasExposedPorts(pod) {
 		logger.V(1).Info("Pod has no exposed ports, assuming no active connections", "pod", pod.Name)
 		return false, nil
 	}
@@ -125,20 +642,71 @@ func (d *DrainHandler) checkActiveConnections(ctx context.Context, pod *corev1.P
 	// it's likely the pod is not serving traffic
 	for _, condition := range pod.Status.Conditions {
 		if condition.Type == corev1.PodReady && condition.Status != corev1.ConditionTrue {
+			if _, serving := d.servingSidecar(pod); serving {
+				break
+			}
 			logger.V(1).Info("Pod is not ready, assuming no active connections", "pod", pod.Name)
 			return false, nil
 		}
 	}
 
+	// A pod receiving only a sliver of traffic (e.g. a canary) isn't worth waiting for
+	if threshold := d.config.GetTrafficWeightThreshold(); threshold > 0 {
+		weight, err := d.trafficWeights.TrafficWeight(ctx, pod)
+		if err != nil {
+			logger.Error(err, "Failed to get pod traffic weight, checking connections", "pod", pod.Name)
+		} else if weight < threshold {
+			logger.V(1).Info("Pod traffic weight is below the threshold, assuming no active connections",
+				"pod", pod.Name, "weight", weight, "threshold", threshold)
+			return false, nil
+		}
+	}
+
+	switch d.config.GetConnectionCheckMode() {
+	case ConnectionCheckModeConntrack:
+		return d.checkEstablishedConnections(ctx, pod)
+	case ConnectionCheckModeGRPCHealth:
+		return d.checkGRPCHealth(ctx, pod)
+	}
+
+	// hostPort traffic reaches the pod on the node IP and never shows up in endpoints,
+	// so their absence proves nothing; rely on the grace period alone
+	if d.servesHostPort(pod) {
+		logger.V(1).Info("Pod receives traffic through a hostPort, relying on the grace period", "pod", pod.Name)
+		return false, nil
+	}
+
+	if d.skipEndpointCheck {
+		logger.V(1).Info("Endpoint check is disabled, assuming no active connections", "pod", pod.Name)
+		return false, nil
+	}
+
+	// While the Endpoints API keeps failing, skip the check and fall back to
+	// grace-period-only behavior instead of holding every drain until timeout
+	if d.endpointBreaker != nil && !d.endpointBreaker.Allow() {
+		metrics.EndpointCheckShortCircuitedTotal.Inc()
+		logger.V(1).Info("Endpoint check circuit is open, assuming no active connections", "pod", pod.Name)
+		return false, nil
+	}
+
 	// Check if pod has any endpoints in service
-	hasActiveEndpoints, err := d.checkPodEndpoints(ctx, pod)
+	services, err := d.podEndpointServices(ctx, pod)
+	d.endpointServices = services
+	if d.endpointBreaker != nil {
+		if err != nil {
+			d.endpointBreaker.RecordFailure()
+		} else {
+			d.endpointBreaker.RecordSuccess()
+		}
+	}
 	if err != nil {
+		metrics.EndpointCheckFailuresTotal.Inc()
 		logger.Error(err, "Failed to check pod endpoints")
 		// If we can't determine endpoint status, assume there might be connections
 		return true, err
 	}
 
-	if !hasActiveEndpoints {
+	if len(services) == 0 {
 		logger.V(1).Info("Pod has no active endpoints, assuming no active connections", "pod", pod.Name)
 		return false, nil
 	}
@@ -146,64 +714,330 @@ func (d *DrainHandler) checkActiveConnections(ctx context.Context, pod *corev1.P
 	// If pod is ready and has active endpoints, assume it might have active connections
 	// In a production environment, you might want to implement more sophisticated
 	// connection checking (e.g., via metrics, custom health endpoints, etc.)
-	logger.V(1).Info("Pod appears to be actively serving traffic", "pod", pod.Name)
+	logger.V(1).Info("Pod appears to be actively serving traffic", "pod", pod.Name,
+		"stillInEndpointsOf", strings.Join(services, ", "))
 	return true, nil
 }
 
-// checkPodEndpoints checks if the pod is part of any service endpoints
-func (d *DrainHandler) checkPodEndpoints(ctx context.Context, pod *corev1.Pod) (bool, error) {
+// checkEstablishedConnections holds the drain until the pod has no established TCP connections
+func (d *DrainHandler) checkEstablishedConnections(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	if d.connTracker == nil {
+		return true, fmt.Errorf("connection check mode %q requires a connection tracker", ConnectionCheckModeConntrack)
+	}
+
+	established, err := d.connTracker.EstablishedConnections(ctx, pod)
+	if err != nil {
+		// If we can't count connections, assume there might be some
+		return true, err
+	}
+
+	logger.V(1).Info("Established connections reported", "pod", pod.Name, "established", established)
+	return established > 0, nil
+}
+
+// checkGRPCHealth holds the drain until the pod's gRPC health service reports NOT_SERVING
+func (d *DrainHandler) checkGRPCHealth(ctx context.Context, pod *corev1.Pod) (bool, error) {
 	logger := log.FromContext(ctx)
 
-	// List all services in the pod's namespace
-	var serviceList corev1.ServiceList
-	if err := d.client.List(ctx, &serviceList, client.InNamespace(pod.Namespace)); err != nil {
-		return false, err
+	if d.grpcHealth == nil {
+		return true, fmt.Errorf("connection check mode %q requires a gRPC health checker", ConnectionCheckModeGRPCHealth)
+	}
+
+	serving, err := d.grpcHealth.Serving(ctx, pod)
+	if err != nil {
+		// If we can't read the health, assume the pod is still serving
+		return true, err
+	}
+
+	logger.V(1).Info("gRPC health reported", "pod", pod.Name, "serving", serving)
+	return serving, nil
+}
+
+// activeTrafficAnnotation returns the first configured active-traffic annotation that is
+// set to a true value on the pod
+func (d *DrainHandler) activeTrafficAnnotation(pod *corev1.Pod) (string, bool) {
+	for _, key := range d.config.GetActiveTrafficAnnotations() {
+		if active, err := strconv.ParseBool(pod.Annotations[key]); err == nil && active {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// hasExposedPorts reports whether any container that runs for the pod's lifetime declares
+// a traffic port. Regular init containers have exited before the pod serves, so their
+// ports are ignored, while native sidecars (init containers with restartPolicy Always)
+// keep running alongside the app and count. Ephemeral containers can't declare ports.
+// When traffic containers are configured, only their ports are considered.
+func (d *DrainHandler) hasExposedPorts(pod *corev1.Pod) bool {
+	isTrafficContainer := d.trafficContainerFilter(pod)
+	for _, container := range pod.Spec.Containers {
+		if isTrafficContainer(container.Name) && d.declaresTrafficPort(container) {
+			return true
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		isSidecar := container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+		if isSidecar && isTrafficContainer(container.Name) && d.declaresTrafficPort(container) {
+			return true
+		}
+	}
+	return false
+}
+
+// servesHostPort reports whether considerHostPort is enabled and a traffic container
+// publishes a traffic port on the node through hostPort
+func (d *DrainHandler) servesHostPort(pod *corev1.Pod) bool {
+	if !d.config.GetConsiderHostPort() {
+		return false
+	}
+
+	isTrafficContainer := d.trafficContainerFilter(pod)
+	for _, container := range pod.Spec.Containers {
+		if !isTrafficContainer(container.Name) {
+			continue
+		}
+		for _, port := range container.Ports {
+			if port.HostPort != 0 && d.isTrafficPort(port) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// servingSidecar returns the first native sidecar that declares a traffic port and is
+// still reported running, e.g. a proxy that keeps serving after the app went unready
+func (d *DrainHandler) servingSidecar(pod *corev1.Pod) (string, bool) {
+	running := make(map[string]bool, len(pod.Status.InitContainerStatuses))
+	for _, status := range pod.Status.InitContainerStatuses {
+		running[status.Name] = status.State.Running != nil
+	}
+
+	isTrafficContainer := d.trafficContainerFilter(pod)
+	for _, container := range pod.Spec.InitContainers {
+		isSidecar := container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+		if isSidecar && running[container.Name] && isTrafficContainer(container.Name) && d.declaresTrafficPort(container) {
+			return container.Name, true
+		}
+	}
+	return "", false
+}
+
+// trafficContainerFilter matches the containers named by the pod's traffic-containers
+// annotation or, failing that, the trafficContainers setting. Matches all when neither is set.
+func (d *DrainHandler) trafficContainerFilter(pod *corev1.Pod) func(name string) bool {
+	names := d.config.GetTrafficContainers()
+	if value, ok := pod.Annotations[TrafficContainersAnnotation]; ok {
+		names = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return func(string) bool { return true }
+	}
+
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+	return func(name string) bool { return allowed[name] }
+}
+
+func (d *DrainHandler) declaresTrafficPort(container corev1.Container) bool {
+	for _, port := range container.Ports {
+		if d.isTrafficPort(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTrafficPort reports whether connections on the port should hold the drain.
+// UDP and SCTP are connectionless here, so with tcpPortsOnly only TCP ports count.
+func (d *DrainHandler) isTrafficPort(port corev1.ContainerPort) bool {
+	if !d.config.GetTCPPortsOnly() {
+		return true
+	}
+	// An empty protocol defaults to TCP
+	return port.Protocol == "" || port.Protocol == corev1.ProtocolTCP
+}
+
+// apiCallContext bounds a single API call so a slow API server can't stall the reconcile
+func (d *DrainHandler) apiCallContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d.config.GetAPICallTimeout())
+}
+
+// podEndpointServices returns the names of the services whose endpoints still contain the pod
+func (d *DrainHandler) podEndpointServices(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	// List the services in the pod's namespace that may select it
+	services, err := d.candidateServices(ctx, pod)
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			// Without RBAC for services we cannot see endpoints at all. Treat the pod as
+			// having no connections so drains fall back to grace-period-only behavior
+			// instead of every drain waiting for the full timeout.
+			d.forbiddenWarning.Do(func() {
+				logger.Info("WARNING: not allowed to list services, falling back to grace-period-only drain",
+					"namespace", pod.Namespace, "error", err.Error())
+			})
+			return nil, nil
+		}
+		return nil, err
 	}
 
 	podIP := pod.Status.PodIP
 	if podIP == "" {
 		logger.V(1).Info("Pod has no IP address", "pod", pod.Name)
-		return false, nil
+		return nil, nil
 	}
 
-	// Check each service to see if this pod is targeted
-	for _, service := range serviceList.Items {
+	// Only the services selecting the pod can route traffic to it
+	podLabels := labels.Set(pod.Labels)
+	var matching []corev1.Service
+	for _, service := range services {
 		if service.Spec.Selector == nil {
 			continue
 		}
+		if labels.Set(service.Spec.Selector).AsSelector().Matches(podLabels) {
+			matching = append(matching, service)
+		}
+	}
 
-		// Check if pod matches service selector
-		podLabels := labels.Set(pod.Labels)
-		serviceSelector := labels.Set(service.Spec.Selector)
-
-		if serviceSelector.AsSelector().Matches(podLabels) {
-			// Get endpoints for this service
-			var endpoints corev1.Endpoints
-			endpointsName := client.ObjectKey{
-				Namespace: service.Namespace,
-				Name:      service.Name,
+	var inEndpoints, notYetListed []string
+	if concurrency := d.config.GetEndpointCheckConcurrency(); concurrency > 1 && len(matching) > 1 {
+		inEndpoints, notYetListed, err = d.checkServicesConcurrently(ctx, pod, matching, concurrency)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for i := range matching {
+			listed, err := d.serviceListsPod(ctx, pod, &matching[i])
+			if err != nil {
+				return nil, err
+			}
+			if listed {
+				inEndpoints = append(inEndpoints, matching[i].Name)
+			} else {
+				notYetListed = append(notYetListed, matching[i].Name)
 			}
+		}
+	}
+
+	if len(inEndpoints) > 0 {
+		return inEndpoints, nil
+	}
+
+	if d.config.GetCrossNamespaceEndpointCheck() {
+		crossNamespace, err := d.crossNamespaceEndpoints(ctx, pod)
+		if err != nil {
+			return nil, err
+		}
+		if len(crossNamespace) > 0 {
+			return crossNamespace, nil
+		}
+	}
 
-			if err := d.client.Get(ctx, endpointsName, &endpoints); err != nil {
-				// If endpoints don't exist, service might not be active
-				continue
+	// The endpoints controller lags behind readiness; a pod that just became ready may be
+	// about to receive traffic even though its IP isn't listed yet
+	if len(notYetListed) > 0 && d.recentlyReady(pod) {
+		logger.V(1).Info("Pod matches a service but is not in its endpoints yet, waiting for endpoints to settle",
+			"pod", pod.Name, "services", notYetListed, "settle", d.config.GetEndpointSettle().String())
+		return notYetListed, nil
+	}
+
+	logger.V(1).Info("Pod not found in any service endpoints", "pod", pod.Name)
+	return nil, nil
+}
+
+// serviceListsPod reports whether the service's endpoints, or endpoint slices with
+// useEndpointSlices, route traffic to the pod. Only a timed-out API call is returned as an
+// error: it says nothing about the endpoints, so the caller takes the conservative path and
+// requeues. Any other failure, e.g. missing endpoints, counts as not listed.
+func (d *DrainHandler) serviceListsPod(ctx context.Context, pod *corev1.Pod, service *corev1.Service) (bool, error) {
+	logger := log.FromContext(ctx)
+	headless := service.Spec.ClusterIP == corev1.ClusterIPNone
+
+	if d.config.GetUseEndpointSlices() {
+		slices, err := d.serviceEndpointSlices(ctx, service)
+		if err != nil {
+			if stderrors.Is(err, context.DeadlineExceeded) {
+				return false, err
 			}
+			return false, nil
+		}
+		if !endpointSlicesContain(slices, pod, headless, service.Name) {
+			return false, nil
+		}
+		logger.V(1).Info("Pod found serving in service endpoint slices",
+			"pod", pod.Name,
+			"service", service.Name,
+			"podIP", pod.Status.PodIP)
+		return true, nil
+	}
 
-			// Check if this pod's IP is in the endpoints
-			for _, subset := range endpoints.Subsets {
-				for _, address := range subset.Addresses {
-					if address.IP == podIP {
-						logger.V(1).Info("Pod found in service endpoints",
-							"pod", pod.Name,
-							"service", service.Name,
-							"podIP", podIP)
-						return true, nil
-					}
-				}
+	var endpoints corev1.Endpoints
+	getCtx, cancelGet := d.apiCallContext(ctx)
+	err := d.client.Get(getCtx, client.ObjectKey{Namespace: service.Namespace, Name: service.Name}, &endpoints)
+	cancelGet()
+	if err != nil {
+		if stderrors.Is(err, context.DeadlineExceeded) {
+			return false, err
+		}
+		// If endpoints don't exist, service might not be active
+		return false, nil
+	}
+
+	if !endpointsContain(&endpoints, pod, headless) {
+		return false, nil
+	}
+	logger.V(1).Info("Pod found in service endpoints",
+		"pod", pod.Name,
+		"service", service.Name,
+		"podIP", pod.Status.PodIP)
+	return true, nil
+}
+
+// endpointsContain reports whether the pod is one of the endpoints' ready addresses.
+// Addresses are matched by IP or by their pod reference and, for headless services, by the
+// pod's hostname, which is how StatefulSet pods are listed. NotReadyAddresses never count:
+// the service doesn't route traffic to them.
+func endpointsContain(endpoints *corev1.Endpoints, pod *corev1.Pod, headless bool) bool {
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.IP != "" && address.IP == pod.Status.PodIP {
+				return true
+			}
+			if ref := address.TargetRef; ref != nil && ref.Kind == "Pod" &&
+				ref.Namespace == pod.Namespace && ref.Name == pod.Name {
+				return true
+			}
+			if headless && address.Hostname != "" && address.Hostname == pod.Spec.Hostname &&
+				pod.Spec.Subdomain == endpoints.Name {
+				return true
 			}
 		}
 	}
+	return false
+}
 
-	logger.V(1).Info("Pod not found in any service endpoints", "pod", pod.Name)
-	return false, nil
+// recentlyReady reports whether the pod turned Ready within the endpoint settle window
+func (d *DrainHandler) recentlyReady(pod *corev1.Pod) bool {
+	settle := d.config.GetEndpointSettle()
+	if settle <= 0 {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return d.clock.Now().Sub(condition.LastTransitionTime.Time) < settle
+		}
+	}
+	return false
 }
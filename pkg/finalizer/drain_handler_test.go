@@ -2,17 +2,27 @@ package finalizer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/cho/vpa-graceful-drain-controller/pkg/metrics"
 )
 
 func TestDrainHandler(t *testing.T) {
@@ -21,8 +31,43 @@ func TestDrainHandler(t *testing.T) {
 }
 
 type mockConfig struct {
-	gracePeriod  time.Duration
-	drainTimeout time.Duration
+	gracePeriod                time.Duration
+	drainTimeout               time.Duration
+	hardDeadlineBuffer         time.Duration
+	onlyManageEvictions        bool
+	tcpPortsOnly               bool
+	connectionCheckMode        string
+	treatMissingReadyAsReady   bool
+	ownerKindOverrides         map[string]DrainWindow
+	apiCallTimeout             time.Duration
+	respectDeletionGracePeriod bool
+	trafficWeightThreshold     float64
+	onTimeoutWithConnections   string
+	onConnectionCheckError     string
+	ageScaledGrace             bool
+	ageScaledGraceMin          time.Duration
+	ageScaledGraceFullAge      time.Duration
+	useEndpointSlices          bool
+	endpointCheckConcurrency   int
+	waitForVolumeDetach        bool
+	timeoutExtension           time.Duration
+	maxTimeoutExtensions       int
+	endpointSettle             time.Duration
+	fastDrainOnNodeCordon      bool
+	nodeCordonGrace            time.Duration
+	fastDrainOnDescheduler     bool
+	deschedulerGrace           time.Duration
+	activeTrafficAnnotations   []string
+	postDeregistration         time.Duration
+	trafficContainers          []string
+	minimumServing             time.Duration
+	considerHostPort           bool
+	crossNamespaceCheck        bool
+	crossNamespaces            []string
+	servingPhases              []string
+	connectionPollInterval     time.Duration
+	knativeAware               bool
+	knativeSettle              time.Duration
 }
 
 func (c *mockConfig) GetGracePeriod() time.Duration {
@@ -33,6 +78,190 @@ func (c *mockConfig) GetDrainTimeout() time.Duration {
 	return c.drainTimeout
 }
 
+func (c *mockConfig) GetHardDeadlineBuffer() time.Duration {
+	return c.hardDeadlineBuffer
+}
+
+func (c *mockConfig) GetOnlyManageEvictions() bool {
+	return c.onlyManageEvictions
+}
+
+func (c *mockConfig) GetTCPPortsOnly() bool {
+	return c.tcpPortsOnly
+}
+
+func (c *mockConfig) GetConnectionCheckMode() string {
+	return c.connectionCheckMode
+}
+
+func (c *mockConfig) GetTreatMissingReadyAsReady() bool {
+	return c.treatMissingReadyAsReady
+}
+
+func (c *mockConfig) GetOwnerKindOverrides() map[string]DrainWindow {
+	return c.ownerKindOverrides
+}
+
+func (c *mockConfig) GetAPICallTimeout() time.Duration {
+	return c.apiCallTimeout
+}
+
+func (c *mockConfig) GetRespectDeletionGracePeriod() bool {
+	return c.respectDeletionGracePeriod
+}
+
+func (c *mockConfig) GetTrafficWeightThreshold() float64 {
+	return c.trafficWeightThreshold
+}
+
+func (c *mockConfig) GetOnTimeoutWithConnections() string {
+	return c.onTimeoutWithConnections
+}
+
+func (c *mockConfig) GetTimeoutExtension() time.Duration {
+	return c.timeoutExtension
+}
+
+func (c *mockConfig) GetMaxTimeoutExtensions() int {
+	return c.maxTimeoutExtensions
+}
+
+func (c *mockConfig) GetEndpointSettle() time.Duration {
+	return c.endpointSettle
+}
+
+func (c *mockConfig) GetFastDrainOnNodeCordon() bool {
+	return c.fastDrainOnNodeCordon
+}
+
+func (c *mockConfig) GetNodeCordonGrace() time.Duration {
+	return c.nodeCordonGrace
+}
+
+func (c *mockConfig) GetFastDrainOnDescheduler() bool {
+	return c.fastDrainOnDescheduler
+}
+
+func (c *mockConfig) GetDeschedulerGrace() time.Duration {
+	return c.deschedulerGrace
+}
+
+func (c *mockConfig) GetActiveTrafficAnnotations() []string {
+	return c.activeTrafficAnnotations
+}
+
+func (c *mockConfig) GetPostDeregistration() time.Duration {
+	return c.postDeregistration
+}
+
+func (c *mockConfig) GetTrafficContainers() []string {
+	return c.trafficContainers
+}
+
+func (c *mockConfig) GetMinimumServing() time.Duration {
+	return c.minimumServing
+}
+
+func (c *mockConfig) GetConsiderHostPort() bool {
+	return c.considerHostPort
+}
+
+func (c *mockConfig) GetCrossNamespaceEndpointCheck() bool {
+	return c.crossNamespaceCheck
+}
+
+func (c *mockConfig) GetCrossNamespaceEndpoints() []string {
+	return c.crossNamespaces
+}
+
+func (c *mockConfig) GetServingPhases() []string {
+	return c.servingPhases
+}
+
+func (c *mockConfig) GetConnectionPollInterval() time.Duration {
+	return c.connectionPollInterval
+}
+
+func (c *mockConfig) GetKnativeAware() bool {
+	return c.knativeAware
+}
+
+func (c *mockConfig) GetKnativeSettle() time.Duration {
+	return c.knativeSettle
+}
+
+func (c *mockConfig) GetOnConnectionCheckError() string {
+	return c.onConnectionCheckError
+}
+
+func (c *mockConfig) GetAgeScaledGrace() bool {
+	return c.ageScaledGrace
+}
+
+func (c *mockConfig) GetAgeScaledGraceMin() time.Duration {
+	return c.ageScaledGraceMin
+}
+
+func (c *mockConfig) GetAgeScaledGraceFullAge() time.Duration {
+	return c.ageScaledGraceFullAge
+}
+
+func (c *mockConfig) GetUseEndpointSlices() bool {
+	return c.useEndpointSlices
+}
+
+func (c *mockConfig) GetEndpointCheckConcurrency() int {
+	return c.endpointCheckConcurrency
+}
+
+func (c *mockConfig) GetWaitForVolumeDetach() bool {
+	return c.waitForVolumeDetach
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type mockConnTracker struct {
+	established int
+	err         error
+}
+
+func (m *mockConnTracker) EstablishedConnections(ctx context.Context, pod *corev1.Pod) (int, error) {
+	return m.established, m.err
+}
+
+type mockGRPCHealth struct {
+	serving bool
+	err     error
+}
+
+func (m *mockGRPCHealth) Serving(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	return m.serving, m.err
+}
+
+type mockDrainGate struct {
+	drained bool
+	err     error
+}
+
+func (m *mockDrainGate) IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	return m.drained, m.err
+}
+
+type mockTrafficWeight struct {
+	weight float64
+	err    error
+}
+
+func (m *mockTrafficWeight) TrafficWeight(ctx context.Context, pod *corev1.Pod) (float64, error) {
+	return m.weight, m.err
+}
+
 var _ = Describe("DrainHandler", func() {
 	var (
 		ctx            context.Context
@@ -53,8 +282,11 @@ var _ = Describe("DrainHandler", func() {
 		corev1.AddToScheme(scheme)
 		
 		config = &mockConfig{
-			gracePeriod:  30 * time.Second,
-			drainTimeout: 300 * time.Second,
+			gracePeriod:        30 * time.Second,
+			drainTimeout:       300 * time.Second,
+			hardDeadlineBuffer: 60 * time.Second,
+			tcpPortsOnly:       true,
+			apiCallTimeout:     5 * time.Second,
 		}
 		
 		now = time.Now()
@@ -89,9 +321,9 @@ var _ = Describe("DrainHandler", func() {
 					},
 				}
 
-				completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+				result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(completed).To(BeTrue())
+				Expect(result.Completed).To(BeTrue())
 			})
 		})
 
@@ -110,73 +342,189 @@ var _ = Describe("DrainHandler", func() {
 						},
 					}
 
-					completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(completed).To(BeFalse())
+					Expect(result.Completed).To(BeFalse())
 				})
 			})
 
-			Context("and drain timeout has been exceeded", func() {
-				It("should return true and allow deletion", func() {
-					deletionTime := metav1.NewTime(now.Add(-400 * time.Second)) // 400 seconds ago (> 300s timeout)
+			Context("and force-complete annotation is set", func() {
+				It("should return true even within the grace period", func() {
+					deletionTime := metav1.NewTime(now.Add(-5 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+							Annotations: map[string]string{
+								ForceCompleteAnnotation: "true",
+							},
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							Conditions: []corev1.PodCondition{
+								{
+									Type:   corev1.PodReady,
+									Status: corev1.ConditionTrue,
+								},
+							},
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(result.Reason).To(Equal(CompletionReasonForceCompleted))
+				})
+
+				It("should ignore values other than 'true'", func() {
+					deletionTime := metav1.NewTime(now.Add(-5 * time.Second))
 					pod := &corev1.Pod{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:              "test-pod",
 							Namespace:         "default",
 							DeletionTimestamp: &deletionTime,
+							Annotations: map[string]string{
+								ForceCompleteAnnotation: "false",
+							},
 						},
 						Status: corev1.PodStatus{
 							Phase: corev1.PodRunning,
 						},
 					}
 
-					completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(completed).To(BeTrue())
+					Expect(result.Completed).To(BeFalse())
 				})
 			})
 
-			Context("and pod has completed successfully", func() {
-				It("should return true for Succeeded phase", func() {
-					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+			Context("and the pod was force-deleted", func() {
+				It("should return true within the grace period", func() {
+					deletionTime := metav1.NewTime(now.Add(-1 * time.Second))
+					var zero int64
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:                       "test-pod",
+							Namespace:                  "default",
+							DeletionTimestamp:          &deletionTime,
+							DeletionGracePeriodSeconds: &zero,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							Conditions: []corev1.PodCondition{
+								{
+									Type:   corev1.PodReady,
+									Status: corev1.ConditionTrue,
+								},
+							},
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(result.Reason).To(Equal(CompletionReasonForceDeleted))
+				})
+
+				It("should keep draining a pod deleted with a non-zero grace period", func() {
+					deletionTime := metav1.NewTime(now.Add(-1 * time.Second))
+					gracePeriod := int64(30)
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:                       "test-pod",
+							Namespace:                  "default",
+							DeletionTimestamp:          &deletionTime,
+							DeletionGracePeriodSeconds: &gracePeriod,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeFalse())
+				})
+			})
+
+			Context("and skip-drain annotation is set", func() {
+				It("should return true within the grace period", func() {
+					deletionTime := metav1.NewTime(now.Add(-1 * time.Second))
 					pod := &corev1.Pod{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:              "test-pod",
 							Namespace:         "default",
 							DeletionTimestamp: &deletionTime,
+							Annotations: map[string]string{
+								SkipDrainAnnotation: "true",
+							},
 						},
 						Status: corev1.PodStatus{
-							Phase: corev1.PodSucceeded,
+							Phase: corev1.PodRunning,
+							Conditions: []corev1.PodCondition{
+								{
+									Type:   corev1.PodReady,
+									Status: corev1.ConditionTrue,
+								},
+							},
 						},
 					}
 
-					completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(completed).To(BeTrue())
+					Expect(result.Completed).To(BeTrue())
+					Expect(result.Reason).To(Equal(CompletionReasonSkipDrain))
 				})
 
-				It("should return true for Failed phase", func() {
-					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+				It("should ignore values other than 'true'", func() {
+					deletionTime := metav1.NewTime(now.Add(-1 * time.Second))
 					pod := &corev1.Pod{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:              "test-pod",
 							Namespace:         "default",
 							DeletionTimestamp: &deletionTime,
+							Annotations: map[string]string{
+								SkipDrainAnnotation: "yes",
+							},
 						},
 						Status: corev1.PodStatus{
-							Phase: corev1.PodFailed,
+							Phase: corev1.PodRunning,
 						},
 					}
 
-					completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(completed).To(BeTrue())
+					Expect(result.Completed).To(BeFalse())
 				})
 			})
 
-			Context("and pod is not ready", func() {
-				It("should return true when pod ready condition is false", func() {
-					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+			Context("and onlyManageEvictions is enabled", func() {
+				var deletionTime metav1.Time
+
+				BeforeEach(func() {
+					config.onlyManageEvictions = true
+					deletionTime = metav1.NewTime(now.Add(-5 * time.Second))
+				})
+
+				It("should complete immediately for a manual delete", func() {
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+				})
+
+				It("should hold an evicted pod for the grace period", func() {
 					pod := &corev1.Pod{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:              "test-pod",
@@ -187,20 +535,20 @@ var _ = Describe("DrainHandler", func() {
 							Phase: corev1.PodRunning,
 							Conditions: []corev1.PodCondition{
 								{
-									Type:   corev1.PodReady,
-									Status: corev1.ConditionFalse,
+									Type:   corev1.DisruptionTarget,
+									Status: corev1.ConditionTrue,
+									Reason: "EvictionByEvictionAPI",
 								},
 							},
 						},
 					}
 
-					completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(completed).To(BeTrue())
+					Expect(result.Completed).To(BeFalse())
 				})
 
-				It("should return true when pod has no ready condition", func() {
-					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+				It("should hold a pod evicted by the kubelet", func() {
 					pod := &corev1.Pod{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:              "test-pod",
@@ -208,250 +556,2784 @@ var _ = Describe("DrainHandler", func() {
 							DeletionTimestamp: &deletionTime,
 						},
 						Status: corev1.PodStatus{
-							Phase:      corev1.PodRunning,
-							Conditions: []corev1.PodCondition{},
+							Phase:  corev1.PodRunning,
+							Reason: "Evicted",
 						},
 					}
 
-					completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 					Expect(err).ToNot(HaveOccurred())
-					Expect(completed).To(BeTrue())
+					Expect(result.Completed).To(BeFalse())
 				})
 			})
-		})
-	})
 
-	Describe("isPodReady", func() {
-		BeforeEach(func() {
-			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
-			drainHandler = NewDrainHandler(fakeClient, config)
-		})
+			Context("and respectDeletionGracePeriod is enabled", func() {
+				newDeletedPod := func(deletionGracePeriodSeconds int64) *corev1.Pod {
+					deletionTime := metav1.NewTime(now.Add(-2 * time.Second))
+					return &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:                       "test-pod",
+							Namespace:                  "default",
+							DeletionTimestamp:          &deletionTime,
+							DeletionGracePeriodSeconds: &deletionGracePeriodSeconds,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+						},
+					}
+				}
 
-		It("should return true when pod ready condition is true", func() {
-			pod := &corev1.Pod{
-				Status: corev1.PodStatus{
-					Conditions: []corev1.PodCondition{
-						{
-							Type:   corev1.PodReady,
-							Status: corev1.ConditionTrue,
+				BeforeEach(func() {
+					config.respectDeletionGracePeriod = true
+				})
+
+				It("should release a force-deleted pod immediately", func() {
+					result, err := drainHandler.HandleGracefulDrain(ctx, newDeletedPod(0))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(result.Reason).To(Equal(CompletionReasonForceDeleted))
+				})
+
+				It("should keep the configured windows when the deletion grace period is longer", func() {
+					pod := newDeletedPod(600)
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeFalse())
+					Expect(drainHandler.DrainStatus(ctx, pod).DeadlineSeconds).To(Equal(int64(300)))
+				})
+
+				It("should cap the timeout to a shorter deletion grace period", func() {
+					Expect(drainHandler.DrainStatus(ctx, newDeletedPod(5)).DeadlineSeconds).To(Equal(int64(5)))
+				})
+
+				It("should not cap when the option is disabled", func() {
+					config.respectDeletionGracePeriod = false
+					pod := newDeletedPod(5)
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeFalse())
+					Expect(drainHandler.DrainStatus(ctx, pod).DeadlineSeconds).To(Equal(int64(300)))
+				})
+			})
+
+			Context("and drain timeout has been exceeded", func() {
+				It("should return true and allow deletion", func() {
+					deletionTime := metav1.NewTime(now.Add(-400 * time.Second)) // 400 seconds ago (> 300s timeout)
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
 						},
-					},
-				},
-			}
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+						},
+					}
 
-			isReady := drainHandler.isPodReady(pod)
-			Expect(isReady).To(BeTrue())
-		})
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+				})
 
-		It("should return false when pod ready condition is false", func() {
-			pod := &corev1.Pod{
-				Status: corev1.PodStatus{
-					Conditions: []corev1.PodCondition{
-						{
-							Type:   corev1.PodReady,
-							Status: corev1.ConditionFalse,
+				It("should report the timeout reason before the hard deadline", func() {
+					deletionTime := metav1.NewTime(now.Add(-330 * time.Second)) // past timeout, within the 60s buffer
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
 						},
-					},
-				},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+				})
+			})
+
+			Context("and hard deadline has been exceeded", func() {
+				It("should force-complete and count a hard timeout", func() {
+					deletionTime := metav1.NewTime(now.Add(-361 * time.Second)) // past 300s timeout + 60s buffer
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+						},
+					}
+
+					before := testutil.ToFloat64(metrics.HardTimeoutTotal)
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(testutil.ToFloat64(metrics.HardTimeoutTotal)).To(Equal(before + 1))
+				})
+
+				It("should not count a hard timeout just inside the buffer", func() {
+					deletionTime := metav1.NewTime(now.Add(-359 * time.Second)) // past timeout, inside buffer
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+						},
+					}
+
+					before := testutil.ToFloat64(metrics.HardTimeoutTotal)
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(testutil.ToFloat64(metrics.HardTimeoutTotal)).To(Equal(before))
+				})
+			})
+
+			Context("and pod has completed successfully", func() {
+				It("should return true for Succeeded phase", func() {
+					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodSucceeded,
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+				})
+
+				It("should return true for Failed phase", func() {
+					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodFailed,
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+				})
+			})
+
+			Context("and every container has terminated", func() {
+				It("should complete even though the phase is still Running", func() {
+					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase:      corev1.PodRunning,
+							Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+							ContainerStatuses: []corev1.ContainerStatus{
+								{Name: "app", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+								{Name: "logger", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137}}},
+							},
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(result.Reason).To(Equal(CompletionReasonContainersTerminated))
+				})
+			})
+
+			Context("and pod never started", func() {
+				It("should complete immediately for a Pending pod within the grace period", func() {
+					deletionTime := metav1.NewTime(now.Add(-5 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodPending,
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(result.Reason).To(Equal(CompletionReasonNotStarted))
+				})
+
+				It("should complete immediately while containers are still being created", func() {
+					deletionTime := metav1.NewTime(now.Add(-5 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							ContainerStatuses: []corev1.ContainerStatus{
+								{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+							},
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+					Expect(result.Reason).To(Equal(CompletionReasonNotStarted))
+				})
+
+				It("should keep draining a crash-looping pod that has run before", func() {
+					deletionTime := metav1.NewTime(now.Add(-5 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							ContainerStatuses: []corev1.ContainerStatus{
+								{
+									Name:                 "app",
+									State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+									LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+								},
+							},
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeFalse())
+				})
+			})
+
+			Context("and pod has restartPolicy Never", func() {
+				It("should keep waiting without checking endpoints while running", func() {
+					fakeClient = fake.NewClientBuilder().
+						WithScheme(scheme).
+						WithInterceptorFuncs(interceptor.Funcs{
+							List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+								Fail("endpoints must not be checked for restartPolicy Never pods")
+								return nil
+							},
+						}).
+						Build()
+					drainHandler = NewDrainHandler(fakeClient, config)
+
+					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-job-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:  "worker",
+									Image: "busybox",
+									Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+								},
+							},
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							PodIP: "10.0.0.1",
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeFalse())
+				})
+
+				It("should complete once the pod has succeeded", func() {
+					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-job-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodSucceeded,
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+				})
+			})
+
+			Context("and pod is not ready", func() {
+				It("should return true when pod ready condition is false", func() {
+					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							Conditions: []corev1.PodCondition{
+								{
+									Type:   corev1.PodReady,
+									Status: corev1.ConditionFalse,
+								},
+							},
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+				})
+
+				It("should return true when pod has no ready condition", func() {
+					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+						},
+						Status: corev1.PodStatus{
+							Phase:      corev1.PodRunning,
+							Conditions: []corev1.PodCondition{},
+						},
+					}
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeTrue())
+				})
+
+				It("should keep waiting on a serving pod with no ready condition when treatMissingReadyAsReady is set", func() {
+					config.treatMissingReadyAsReady = true
+					deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "test-pod",
+							Namespace:         "default",
+							DeletionTimestamp: &deletionTime,
+							Labels:            map[string]string{"app": "test"},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "app",
+									Image: "app",
+									Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+								},
+							},
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							PodIP: "10.0.0.1",
+						},
+					}
+					service := &corev1.Service{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "test-service",
+							Namespace: "default",
+						},
+						Spec: corev1.ServiceSpec{
+							Selector: map[string]string{"app": "test"},
+						},
+					}
+					endpoints := &corev1.Endpoints{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "test-service",
+							Namespace: "default",
+						},
+						Subsets: []corev1.EndpointSubset{
+							{
+								Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+							},
+						},
+					}
+					fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, endpoints).Build()
+					drainHandler = NewDrainHandler(fakeClient, config)
+
+					result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Completed).To(BeFalse())
+				})
+			})
+		})
+	})
+
+	Describe("HandleGracefulDrain with an injected clock", func() {
+		var (
+			clock        *fakeClock
+			deletionTime metav1.Time
+			pod          *corev1.Pod
+		)
+
+		BeforeEach(func() {
+			deletionTime = metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			clock = &fakeClock{now: deletionTime.Time}
+
+			// A ready pod listed in service endpoints, so only timing decides the outcome
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+					Labels:            map[string]string{"app": "test"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "app",
+							Image: "app",
+							Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					PodIP: "10.0.0.1",
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-service",
+					Namespace: "default",
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": "test"},
+				},
+			}
+			endpoints := &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-service",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+					},
+				},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, endpoints).Build()
+			drainHandler = NewDrainHandler(fakeClient, config).WithClock(clock)
+		})
+
+		It("should leave the grace period exactly at its boundary", func() {
+			clock.now = deletionTime.Add(30*time.Second - time.Nanosecond)
+			Expect(drainHandler.DrainStatus(ctx, pod).Phase).To(Equal(DrainPhaseGracePeriod))
+
+			clock.now = deletionTime.Add(30 * time.Second)
+			Expect(drainHandler.DrainStatus(ctx, pod).Phase).To(Equal(DrainPhaseDraining))
+
+			// A non-ready pod is released as soon as the grace period ends, not before
+			pod.Status.Conditions[0].Status = corev1.ConditionFalse
+			clock.now = deletionTime.Add(30*time.Second - time.Nanosecond)
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+
+			clock.now = deletionTime.Add(30 * time.Second)
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonNotReady))
+		})
+
+		It("should only time out once the drain timeout is exceeded", func() {
+			clock.now = deletionTime.Add(300 * time.Second)
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+
+			clock.now = deletionTime.Add(300*time.Second + time.Nanosecond)
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+		})
+
+		It("should report when to requeue and what it found", func() {
+			config.connectionPollInterval = 10 * time.Second
+
+			clock.now = deletionTime.Add(12 * time.Second)
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(DrainResult{
+				RequeueAfter: 18 * time.Second,
+				Elapsed:      12 * time.Second,
+				Phase:        DrainPhaseGracePeriod,
+			}))
+
+			clock.now = deletionTime.Add(45 * time.Second)
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(DrainResult{
+				RequeueAfter:         10 * time.Second,
+				Elapsed:              45 * time.Second,
+				HadActiveConnections: true,
+				Phase:                DrainPhaseDraining,
+			}))
+
+			pod.Status.Conditions[0].Status = corev1.ConditionFalse
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(DrainResult{
+				Completed: true,
+				Reason:    CompletionReasonNotReady,
+				Elapsed:   45 * time.Second,
+				Phase:     DrainPhaseCompleted,
+			}))
+		})
+
+		It("should only hit the hard deadline once timeout plus buffer is exceeded", func() {
+			clock.now = deletionTime.Add(360 * time.Second)
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+
+			clock.now = deletionTime.Add(360*time.Second + time.Nanosecond)
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Reason).To(Equal(CompletionReasonHardTimeout))
+		})
+	})
+
+	Describe("timeout with active connections", func() {
+		var (
+			pod          *corev1.Pod
+			clock        *fakeClock
+			deletionTime metav1.Time
+			tracker      *mockConnTracker
+		)
+
+		BeforeEach(func() {
+			deletionTime = metav1.NewTime(now.Truncate(time.Second).Add(-310 * time.Second))
+			clock = &fakeClock{now: deletionTime.Add(310 * time.Second)}
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+					Finalizers:        []string{"example.com/other"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			}
+
+			config.connectionCheckMode = ConnectionCheckModeConntrack
+			config.timeoutExtension = 60 * time.Second
+			config.maxTimeoutExtensions = 1
+			tracker = &mockConnTracker{established: 2}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+			drainHandler = NewDrainHandler(fakeClient, config).WithClock(clock).WithConnTracker(tracker)
+		})
+
+		It("should complete at the timeout in force-complete mode", func() {
+			config.onTimeoutWithConnections = OnTimeoutForceComplete
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+		})
+
+		Context("in extend mode", func() {
+			BeforeEach(func() {
+				config.onTimeoutWithConnections = OnTimeoutExtend
+			})
+
+			It("should extend the timeout once and record it on the pod", func() {
+				result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Completed).To(BeFalse())
+
+				Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+				Expect(pod.Annotations).To(HaveKeyWithValue(TimeoutExtensionsAnnotation, "1"))
+				Expect(drainHandler.DrainStatus(ctx, pod).DeadlineSeconds).To(Equal(int64(360)))
+
+				// Still inside the extension
+				result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Completed).To(BeFalse())
+
+				// Past the extension, with no extensions left
+				clock.now = deletionTime.Add(370 * time.Second)
+				result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Completed).To(BeTrue())
+				Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+			})
+
+			It("should not extend the timeout without active connections", func() {
+				tracker.established = 0
+
+				result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Completed).To(BeTrue())
+				Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+			})
+		})
+	})
+
+	Describe("connection check errors", func() {
+		var (
+			pod          *corev1.Pod
+			deletionTime metav1.Time
+		)
+
+		BeforeEach(func() {
+			deletionTime = metav1.NewTime(now.Truncate(time.Second).Add(-60 * time.Second))
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+					Finalizers:        []string{"example.com/other"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			}
+
+			config.connectionCheckMode = ConnectionCheckModeConntrack
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+			drainHandler = NewDrainHandler(fakeClient, config).
+				WithClock(&fakeClock{now: deletionTime.Add(60 * time.Second)}).
+				WithConnTracker(&mockConnTracker{err: errors.New("agent unreachable")})
+		})
+
+		It("should return the error in retry mode", func() {
+			config.onConnectionCheckError = OnConnectionCheckErrorRetry
+
+			_, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should complete the drain in assume-drained mode", func() {
+			config.onConnectionCheckError = OnConnectionCheckErrorAssumeDrained
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonNoConnections))
+		})
+
+		It("should keep waiting in assume-serving mode", func() {
+			config.onConnectionCheckError = OnConnectionCheckErrorAssumeServing
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+			Expect(result.HadActiveConnections).To(BeTrue())
+		})
+	})
+
+	Describe("DrainStatus", func() {
+		BeforeEach(func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+		})
+
+		It("should report grace-period phase before the grace period elapses", func() {
+			deletionTime := metav1.NewTime(now.Add(-10 * time.Second))
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: &deletionTime,
+				},
+			}
+
+			status := drainHandler.DrainStatus(ctx, pod)
+			Expect(status.Phase).To(Equal(DrainPhaseGracePeriod))
+			Expect(status.ElapsedSeconds).To(BeNumerically(">=", 10))
+			Expect(status.DeadlineSeconds).To(Equal(int64(300)))
+		})
+
+		It("should report waiting-connections phase after the grace period elapses", func() {
+			deletionTime := metav1.NewTime(now.Add(-60 * time.Second))
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: &deletionTime,
+				},
+			}
+
+			status := drainHandler.DrainStatus(ctx, pod)
+			Expect(status.Phase).To(Equal(DrainPhaseDraining))
+		})
+	})
+
+	Describe("owner kind overrides", func() {
+		var deletionTime metav1.Time
+
+		BeforeEach(func() {
+			Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+			config.ownerKindOverrides = map[string]DrainWindow{
+				"StatefulSet": {GracePeriod: 120 * time.Second, DrainTimeout: 1200 * time.Second},
+			}
+			// Past the default 30s grace period but inside the StatefulSet one
+			deletionTime = metav1.NewTime(now.Add(-60 * time.Second))
+		})
+
+		newOwnedPod := func(kind, name string) *corev1.Pod {
+			controller := true
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &controller},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+		}
+
+		It("should hold a StatefulSet-owned pod for the longer grace period", func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, newOwnedPod("StatefulSet", "db"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+
+			status := drainHandler.DrainStatus(ctx, newOwnedPod("StatefulSet", "db"))
+			Expect(status.Phase).To(Equal(DrainPhaseGracePeriod))
+			Expect(status.DeadlineSeconds).To(Equal(int64(1200)))
+		})
+
+		It("should use the default windows for Deployment-owned pods", func() {
+			controller := true
+			replicaSet := &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web-abc123",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &controller},
+					},
+				},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(replicaSet).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, newOwnedPod("ReplicaSet", "web-abc123"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+		})
+
+		It("should resolve a ReplicaSet-owned pod to its Deployment", func() {
+			controller := true
+			replicaSet := &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web-abc123",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &controller},
+					},
+				},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(replicaSet).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			Expect(drainHandler.resolveOwnerKind(ctx, newOwnedPod("ReplicaSet", "web-abc123"))).To(Equal("Deployment"))
+		})
+
+		It("should fall back to ReplicaSet when the ReplicaSet cannot be read", func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			Expect(drainHandler.resolveOwnerKind(ctx, newOwnedPod("ReplicaSet", "missing"))).To(Equal("ReplicaSet"))
+		})
+	})
+
+	Describe("fast drain on cordoned nodes", func() {
+		var pod *corev1.Pod
+
+		newNode := func(unschedulable bool) *corev1.Node {
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			}
+		}
+
+		BeforeEach(func() {
+			config.fastDrainOnNodeCordon = true
+			config.nodeCordonGrace = 5 * time.Second
+
+			// Within the default 30s grace period but past the cordon grace
+			deletionTime := metav1.NewTime(now.Add(-10 * time.Second))
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+				},
+				Spec: corev1.PodSpec{NodeName: "node-1"},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+		})
+
+		It("should shorten the grace period on a cordoned node", func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(newNode(true)).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(5)))
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+		})
+
+		It("should keep the grace period on a schedulable node", func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(newNode(false)).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(30)))
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+		})
+
+		It("should keep the grace period when fastDrainOnNodeCordon is disabled", func() {
+			config.fastDrainOnNodeCordon = false
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(newNode(true)).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+		})
+
+		It("should keep the grace period when the node cannot be found", func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(30)))
+		})
+	})
+
+	Describe("fast drain on descheduler evictions", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			config.fastDrainOnDescheduler = true
+			config.deschedulerGrace = 5 * time.Second
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			// Within the default 30s grace period but past the descheduler grace
+			deletionTime := metav1.NewTime(now.Add(-10 * time.Second))
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+					Annotations:       map[string]string{DeschedulerEvictionAnnotation: "true"},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+						{
+							Type:   corev1.DisruptionTarget,
+							Status: corev1.ConditionTrue,
+							Reason: "EvictionByEvictionAPI",
+						},
+					},
+				},
+			}
+		})
+
+		It("should shorten the grace period for a descheduler eviction", func() {
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(5)))
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+		})
+
+		It("should keep the grace period when the pod was deleted rather than evicted", func() {
+			pod.Status.Conditions = pod.Status.Conditions[:1]
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(30)))
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+		})
+
+		It("should keep the grace period for evictions by others", func() {
+			pod.Annotations = nil
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+		})
+
+		It("should keep the grace period when fastDrainOnDescheduler is disabled", func() {
+			config.fastDrainOnDescheduler = false
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(30)))
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+		})
+	})
+
+	Describe("minimum serving time", func() {
+		var (
+			pod          *corev1.Pod
+			deletionTime metav1.Time
+		)
+
+		BeforeEach(func() {
+			config.minimumServing = 2 * time.Minute
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			// Past the default 30s grace period
+			deletionTime = metav1.NewTime(now.Add(-40 * time.Second))
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+				},
+			}
+		})
+
+		It("should hold a pod that became ready shortly before its deletion", func() {
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(deletionTime.Add(-20 * time.Second)),
+			}}
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(100)))
+		})
+
+		It("should not hold a pod that has been ready for long enough", func() {
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(deletionTime.Add(-time.Hour)),
+			}}
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+		})
+
+		It("should not extend the grace period past the drain timeout", func() {
+			config.minimumServing = time.Hour
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: deletionTime,
+			}}
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(300)))
+		})
+	})
+
+	Describe("age-scaled grace period", func() {
+		var (
+			pod          *corev1.Pod
+			deletionTime metav1.Time
+		)
+
+		BeforeEach(func() {
+			config.gracePeriod = 60 * time.Second
+			config.ageScaledGrace = true
+			config.ageScaledGraceMin = 5 * time.Second
+			config.ageScaledGraceFullAge = time.Hour
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			deletionTime = metav1.NewTime(now.Truncate(time.Second))
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+				},
+			}
+		})
+
+		DescribeTable("should scale the grace period with the pod's age at deletion",
+			func(age time.Duration, expectedGrace int64) {
+				startTime := metav1.NewTime(deletionTime.Add(-age))
+				pod.Status.StartTime = &startTime
+
+				Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(expectedGrace))
+			},
+			Entry("just started", time.Duration(0), int64(5)),
+			Entry("10 seconds old", 10*time.Second, int64(5)),
+			Entry("15 minutes old", 15*time.Minute, int64(18)),
+			Entry("30 minutes old", 30*time.Minute, int64(32)),
+			Entry("an hour old", time.Hour, int64(60)),
+			Entry("a day old", 24*time.Hour, int64(60)),
+		)
+
+		It("should keep the configured grace period for a pod that hasn't started", func() {
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(60)))
+		})
+
+		It("should keep the configured grace period when disabled", func() {
+			config.ageScaledGrace = false
+			startTime := metav1.NewTime(deletionTime.Add(-10 * time.Second))
+			pod.Status.StartTime = &startTime
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(60)))
+		})
+
+		It("should complete a young pod's drain after its shortened grace period", func() {
+			startTime := metav1.NewTime(deletionTime.Add(-10 * time.Second))
+			pod.Status.StartTime = &startTime
+			drainHandler.WithClock(&fakeClock{now: deletionTime.Add(6 * time.Second)})
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+		})
+	})
+
+	Describe("preStop-aware grace period", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			// Past the default 30s grace period
+			deletionTime := metav1.NewTime(now.Add(-40 * time.Second))
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+					Annotations:       map[string]string{},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+		})
+
+		It("should extend the grace period to the annotated preStop duration", func() {
+			pod.Annotations[PreStopSecondsAnnotation] = "60"
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(60)))
+		})
+
+		It("should keep the configured grace period when the annotation is shorter", func() {
+			pod.Annotations[PreStopSecondsAnnotation] = "10"
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(30)))
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+		})
+
+		It("should not extend the grace period past the drain timeout", func() {
+			pod.Annotations[PreStopSecondsAnnotation] = "600"
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(300)))
+		})
+
+		It("should ignore an invalid annotation", func() {
+			pod.Annotations[PreStopSecondsAnnotation] = "soon"
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(30)))
+		})
+
+		It("should prefer the annotation over the preStop hook", func() {
+			pod.Annotations[PreStopSecondsAnnotation] = "45"
+			pod.Spec.Containers = []corev1.Container{
+				{
+					Name:  "app",
+					Image: "app",
+					Lifecycle: &corev1.Lifecycle{
+						PreStop: &corev1.LifecycleHandler{
+							Exec: &corev1.ExecAction{Command: []string{"sleep", "90"}},
+						},
+					},
+				},
+			}
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(45)))
+		})
+
+		It("should read sleep-based preStop hooks", func() {
+			pod.Spec.Containers = []corev1.Container{
+				{
+					Name:  "app",
+					Image: "app",
+					Lifecycle: &corev1.Lifecycle{
+						PreStop: &corev1.LifecycleHandler{
+							Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "sleep 50"}},
+						},
+					},
+				},
+				{
+					Name:  "sidecar",
+					Image: "sidecar",
+					Lifecycle: &corev1.Lifecycle{
+						PreStop: &corev1.LifecycleHandler{
+							Sleep: &corev1.SleepAction{Seconds: 40},
+						},
+					},
+				},
+			}
+
+			Expect(drainHandler.DrainStatus(ctx, pod).GracePeriodSeconds).To(Equal(int64(50)))
+		})
+	})
+
+	Describe("EstimateDrain", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+					Labels:    map[string]string{"app": "test"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "app",
+							Image: "app",
+							Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					PodIP: "10.0.0.1",
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+		})
+
+		It("should project the timeout as the latest completion for a pod in service endpoints", func() {
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-service",
+					Namespace: "default",
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": "test"},
+				},
+			}
+			endpoints := &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-service",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+					},
+				},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, endpoints).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			deletionTime := metav1.NewTime(now.Add(-10 * time.Second))
+			pod.DeletionTimestamp = &deletionTime
+
+			estimate, err := drainHandler.EstimateDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(estimate.GracePeriod).To(Equal(30 * time.Second))
+			Expect(estimate.HasActiveEndpoints).To(BeTrue())
+			Expect(estimate.ProjectedCompletion).To(Equal(deletionTime.Add(30 * time.Second)))
+			Expect(estimate.LatestCompletion).To(Equal(deletionTime.Add(300 * time.Second)))
+		})
+
+		It("should project completion at the end of the grace period for a pod outside endpoints", func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			estimate, err := drainHandler.EstimateDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(estimate.HasActiveEndpoints).To(BeFalse())
+			Expect(estimate.ProjectedCompletion).To(BeTemporally("~", time.Now().Add(30*time.Second), time.Second))
+			Expect(estimate.LatestCompletion).To(Equal(estimate.ProjectedCompletion))
+		})
+	})
+
+	Describe("EvaluateDrain", func() {
+		deletionTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		DescribeTable("decision table",
+			func(elapsed time.Duration, phase corev1.PodPhase, ready, hasConnections, expectCompleted bool, expectReason string) {
+				completed, reason := EvaluateDrain(deletionTime.Add(elapsed), deletionTime, 30*time.Second, 300*time.Second, phase, ready, hasConnections)
+				Expect(completed).To(Equal(expectCompleted))
+				Expect(reason).To(Equal(expectReason))
+			},
+			Entry("within the grace period with connections", 10*time.Second, corev1.PodRunning, true, true, false, ""),
+			Entry("within the grace period without connections", 10*time.Second, corev1.PodRunning, true, false, false, ""),
+			Entry("within the grace period when not ready", 10*time.Second, corev1.PodRunning, false, false, false, ""),
+			Entry("within the grace period after succeeding", 10*time.Second, corev1.PodSucceeded, false, false, false, ""),
+			Entry("at the end of the grace period with connections", 30*time.Second, corev1.PodRunning, true, true, false, ""),
+			Entry("at the end of the grace period without connections", 30*time.Second, corev1.PodRunning, true, false, true, CompletionReasonNoConnections),
+			Entry("after the grace period with connections", time.Minute, corev1.PodRunning, true, true, false, ""),
+			Entry("after the grace period without connections", time.Minute, corev1.PodRunning, true, false, true, CompletionReasonNoConnections),
+			Entry("after the grace period when not ready", time.Minute, corev1.PodRunning, false, true, true, CompletionReasonNotReady),
+			Entry("after the grace period once succeeded", time.Minute, corev1.PodSucceeded, true, true, true, CompletionReasonPodCompleted),
+			Entry("after the grace period once failed", time.Minute, corev1.PodFailed, false, false, true, CompletionReasonPodCompleted),
+			Entry("at the drain timeout with connections", 300*time.Second, corev1.PodRunning, true, true, false, ""),
+			Entry("past the drain timeout with connections", 301*time.Second, corev1.PodRunning, true, true, true, CompletionReasonTimeout),
+			Entry("past the drain timeout once succeeded", 301*time.Second, corev1.PodSucceeded, true, false, true, CompletionReasonTimeout),
+			Entry("past the drain timeout when not ready", 301*time.Second, corev1.PodRunning, false, false, true, CompletionReasonTimeout),
+		)
+
+		It("should let a grace period longer than the timeout win", func() {
+			completed, reason := EvaluateDrain(deletionTime.Add(time.Minute), deletionTime, 2*time.Minute, 30*time.Second, corev1.PodRunning, true, true)
+			Expect(completed).To(BeFalse())
+			Expect(reason).To(BeEmpty())
+		})
+	})
+
+	Describe("IsEviction", func() {
+		It("should not treat a DisruptionTarget condition with status False as an eviction", func() {
+			pod := &corev1.Pod{
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.DisruptionTarget,
+							Status: corev1.ConditionFalse,
+						},
+					},
+				},
+			}
+
+			Expect(IsEviction(pod)).To(BeFalse())
+		})
+	})
+
+	Describe("isPodReady", func() {
+		BeforeEach(func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+		})
+
+		It("should return true when pod ready condition is true", func() {
+			pod := &corev1.Pod{
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+
+			isReady := drainHandler.isPodReady(pod)
+			Expect(isReady).To(BeTrue())
+		})
+
+		It("should return false when pod ready condition is false", func() {
+			pod := &corev1.Pod{
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionFalse,
+						},
+					},
+				},
+			}
+
+			isReady := drainHandler.isPodReady(pod)
+			Expect(isReady).To(BeFalse())
+		})
+
+		It("should return false when pod has no ready condition", func() {
+			pod := &corev1.Pod{
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{},
+				},
+			}
+
+			isReady := drainHandler.isPodReady(pod)
+			Expect(isReady).To(BeFalse())
+		})
+
+		It("should return true when pod has no ready condition and treatMissingReadyAsReady is set", func() {
+			config.treatMissingReadyAsReady = true
+			pod := &corev1.Pod{
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{},
+				},
+			}
+
+			isReady := drainHandler.isPodReady(pod)
+			Expect(isReady).To(BeTrue())
+		})
+
+		It("should return false when pod has other conditions but no ready condition", func() {
+			pod := &corev1.Pod{
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodScheduled,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+
+			isReady := drainHandler.isPodReady(pod)
+			Expect(isReady).To(BeFalse())
+		})
+	})
+
+	Describe("checkActiveConnections", func() {
+		BeforeEach(func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+		})
+
+		Context("when pod is not running", func() {
+			It("should return false for Pending phase", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodPending,
+					},
+				}
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+
+			It("should return false for Succeeded phase", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodSucceeded,
+					},
+				}
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+
+			It("should return false for Failed phase", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodFailed,
+					},
+				}
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+		})
+
+		Context("when Pending is a serving phase", func() {
+			It("should check the endpoints of a Pending pod", func() {
+				config.servingPhases = []string{"Running", "Pending"}
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Labels:    map[string]string{"app": "web"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "app",
+								Image: "nginx",
+								Ports: []corev1.ContainerPort{
+									{
+										ContainerPort: 80,
+										Protocol:      corev1.ProtocolTCP,
+									},
+								},
+							},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodPending,
+						PodIP: "10.0.0.1",
+					},
+				}
+				service := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+				}
+				endpoints := &corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Subsets: []corev1.EndpointSubset{{
+						Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+					}},
+				}
+				fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, endpoints).Build()
+				drainHandler = NewDrainHandler(fakeClient, config)
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeTrue())
+
+				config.servingPhases = []string{"Running"}
+				hasConnections, err = drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+		})
+
+		Context("when pod is running", func() {
+			It("should return false when pod has no containers", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+
+			It("should return false when pod has no exposed ports", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "app",
+								Image: "nginx",
+								Ports: []corev1.ContainerPort{},
+							},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				}
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+
+			It("should return false when pod is not ready despite having exposed ports", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "app",
+								Image: "nginx",
+								Ports: []corev1.ContainerPort{
+									{
+										ContainerPort: 80,
+										Protocol:      corev1.ProtocolTCP,
+									},
+								},
+							},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						Conditions: []corev1.PodCondition{
+							{
+								Type:   corev1.PodReady,
+								Status: corev1.ConditionFalse,
+							},
+						},
+					},
+				}
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+		})
+
+		Context("when filtering ports by protocol", func() {
+			newServingPod := func(protocol corev1.Protocol) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Labels:    map[string]string{"app": "test"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "app",
+								Image: "app",
+								Ports: []corev1.ContainerPort{
+									{
+										ContainerPort: 53,
+										Protocol:      protocol,
+									},
+								},
+							},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						PodIP: "10.0.0.1",
+						Conditions: []corev1.PodCondition{
+							{
+								Type:   corev1.PodReady,
+								Status: corev1.ConditionTrue,
+							},
+						},
+					},
+				}
+			}
+
+			BeforeEach(func() {
+				service := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-service",
+						Namespace: "default",
+					},
+					Spec: corev1.ServiceSpec{
+						Selector: map[string]string{"app": "test"},
+					},
+				}
+				endpoints := &corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-service",
+						Namespace: "default",
+					},
+					Subsets: []corev1.EndpointSubset{
+						{
+							Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+						},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, endpoints).Build()
+				drainHandler = NewDrainHandler(fakeClient, config)
+			})
+
+			It("should report no connections for a pod exposing only a UDP port", func() {
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, newServingPod(corev1.ProtocolUDP))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+
+			It("should report connections for a pod exposing a TCP port", func() {
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, newServingPod(corev1.ProtocolTCP))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeTrue())
+			})
+
+			It("should count UDP ports when tcpPortsOnly is disabled", func() {
+				config.tcpPortsOnly = false
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, newServingPod(corev1.ProtocolUDP))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeTrue())
+			})
+
+			It("should treat a pod whose only port is on an init container as portless", func() {
+				pod := newServingPod(corev1.ProtocolTCP)
+				pod.Spec.InitContainers = []corev1.Container{
+					{Name: "migrate", Image: "migrate", Ports: pod.Spec.Containers[0].Ports},
+				}
+				pod.Spec.Containers[0].Ports = nil
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+
+			It("should count ports on native sidecar containers", func() {
+				always := corev1.ContainerRestartPolicyAlways
+				pod := newServingPod(corev1.ProtocolTCP)
+				pod.Spec.InitContainers = []corev1.Container{
+					{Name: "proxy", Image: "proxy", RestartPolicy: &always, Ports: pod.Spec.Containers[0].Ports},
+				}
+				pod.Spec.Containers[0].Ports = nil
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeTrue())
+			})
+		})
+
+		Context("when filtering ports by container", func() {
+			var pod *corev1.Pod
+
+			BeforeEach(func() {
+				pod = &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Labels:    map[string]string{"app": "test"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "app", Image: "app"},
+							{Name: "fluentd", Image: "fluentd", Ports: []corev1.ContainerPort{{ContainerPort: 24224}}},
+						},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						PodIP:      "10.0.0.1",
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				}
+				service := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+					Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "test"}},
+				}
+				endpoints := &corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+					Subsets: []corev1.EndpointSubset{
+						{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, endpoints).Build()
+				drainHandler = NewDrainHandler(fakeClient, config)
+			})
+
+			It("should count sidecar ports when no traffic containers are configured", func() {
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeTrue())
+			})
+
+			It("should ignore ports outside the configured traffic containers", func() {
+				config.trafficContainers = []string{"app"}
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeFalse())
+			})
+
+			It("should prefer the pod's traffic-containers annotation over the setting", func() {
+				config.trafficContainers = []string{"app"}
+				pod.Annotations = map[string]string{TrafficContainersAnnotation: "app, fluentd"}
+
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hasConnections).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("checkActiveConnections in conntrack mode", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			config.connectionCheckMode = ConnectionCheckModeConntrack
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+				Spec: corev1.PodSpec{
+					NodeName: "node-1",
+					Containers: []corev1.Container{
+						{
+							Name:  "app",
+							Image: "app",
+							Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					PodIP: "10.0.0.1",
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+		})
+
+		It("should report connections while the count is above zero", func() {
+			drainHandler.WithConnTracker(&mockConnTracker{established: 2})
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+		})
+
+		It("should report no connections once the count hits zero", func() {
+			drainHandler.WithConnTracker(&mockConnTracker{established: 0})
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeFalse())
+		})
+
+		It("should assume connections when the tracker fails", func() {
+			drainHandler.WithConnTracker(&mockConnTracker{err: errors.New("agent unreachable")})
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).To(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+		})
+
+		It("should return an error when no tracker is set", func() {
+			_, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("checkActiveConnections in grpc-health mode", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			config.connectionCheckMode = ConnectionCheckModeGRPCHealth
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "app",
+							Image: "app",
+							Ports: []corev1.ContainerPort{{ContainerPort: 9090}},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					PodIP: "10.0.0.1",
+					Conditions: []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+		})
+
+		It("should report connections while the pod is serving", func() {
+			drainHandler.WithGRPCHealthChecker(&mockGRPCHealth{serving: true})
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+		})
+
+		It("should report no connections once the pod is not serving", func() {
+			drainHandler.WithGRPCHealthChecker(&mockGRPCHealth{serving: false})
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeFalse())
+		})
+
+		It("should assume the pod is serving when the check fails", func() {
+			drainHandler.WithGRPCHealthChecker(&mockGRPCHealth{err: errors.New("connection refused")})
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).To(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+		})
+
+		It("should return an error when no checker is set", func() {
+			_, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("HandleGracefulDrain with a native sidecar", func() {
+		var (
+			pod     *corev1.Pod
+			tracker *mockConnTracker
+		)
+
+		BeforeEach(func() {
+			always := corev1.ContainerRestartPolicyAlways
+			deletionTime := metav1.NewTime(now.Add(-time.Minute))
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+				},
+				Spec: corev1.PodSpec{
+					NodeName: "node-1",
+					InitContainers: []corev1.Container{
+						{Name: "proxy", Image: "proxy", RestartPolicy: &always, Ports: []corev1.ContainerPort{{ContainerPort: 15001}}},
+					},
+					Containers: []corev1.Container{{Name: "app", Image: "app"}},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					PodIP:      "10.0.0.1",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{Name: "proxy", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+					},
+				},
+			}
+
+			config.connectionCheckMode = ConnectionCheckModeConntrack
+			tracker = &mockConnTracker{established: 3}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config).WithConnTracker(tracker)
+		})
+
+		It("should keep draining an unready pod while its sidecar holds connections", func() {
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+
+			tracker.established = 0
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonNoConnections))
+		})
+
+		It("should complete as not ready once the sidecar has stopped", func() {
+			pod.Status.InitContainerStatuses[0].State = corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{ExitCode: 0},
+			}
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonNotReady))
+		})
+	})
+
+	Describe("HandleGracefulDrain with an external drain gate", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+
+			// Past the grace period and not ready, which would otherwise complete the drain
+			deletionTime := metav1.NewTime(now.Add(-time.Minute))
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+		})
+
+		It("should hold the drain until the gate opens", func() {
+			gate := &mockDrainGate{}
+			drainHandler = NewDrainHandler(fakeClient, config).WithDrainGate(gate)
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+
+			gate.drained = true
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonNotReady))
+		})
+
+		It("should surface gate errors", func() {
+			drainHandler = NewDrainHandler(fakeClient, config).WithDrainGate(&mockDrainGate{err: errors.New("gate unavailable")})
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).To(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+		})
+
+		It("should still complete at the drain timeout", func() {
+			deletionTime := metav1.NewTime(now.Add(-301 * time.Second))
+			pod.DeletionTimestamp = &deletionTime
+			drainHandler = NewDrainHandler(fakeClient, config).WithDrainGate(&mockDrainGate{})
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+		})
+	})
+
+	Describe("post-deregistration timer", func() {
+		var (
+			pod          *corev1.Pod
+			endpoints    *corev1.Endpoints
+			clock        *fakeClock
+			deletionTime metav1.Time
+		)
+
+		BeforeEach(func() {
+			deletionTime = metav1.NewTime(now.Truncate(time.Second).Add(-5 * time.Second))
+			clock = &fakeClock{now: deletionTime.Add(5 * time.Second)}
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					Labels:            map[string]string{"app": "test-app"},
+					DeletionTimestamp: &deletionTime,
+					Finalizers:        []string{"example.com/other"},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					PodIP:      "10.0.0.1",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			}
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+				Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "test-app"}},
+			}
+			endpoints = &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "default"},
+				Subsets: []corev1.EndpointSubset{
+					{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+				},
+			}
+
+			config.postDeregistration = 10 * time.Second
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod, service, endpoints).Build()
+			drainHandler = NewDrainHandler(fakeClient, config).WithClock(clock)
+		})
+
+		It("should complete once the period has passed since the pod left endpoints, ahead of the grace period", func() {
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+			Expect(pod.Annotations).ToNot(HaveKey(DeregisteredAtAnnotation))
+
+			endpoints.Subsets = nil
+			Expect(fakeClient.Update(ctx, endpoints)).To(Succeed())
+			clock.now = deletionTime.Add(8 * time.Second)
+
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+			Expect(pod.Annotations).To(HaveKeyWithValue(DeregisteredAtAnnotation, clock.now.UTC().Format(time.RFC3339)))
+
+			// Still inside the post-deregistration period
+			clock.now = deletionTime.Add(15 * time.Second)
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+
+			// Past the period but still inside the 30s grace period
+			clock.now = deletionTime.Add(18 * time.Second)
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonDeregistered))
+		})
+
+		It("should resume from the recorded deregistration time after a restart", func() {
+			pod.Annotations = map[string]string{
+				DeregisteredAtAnnotation: deletionTime.Add(-20 * time.Second).UTC().Format(time.RFC3339),
+			}
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonDeregistered))
+		})
+
+		It("should leave the drain timeout in charge while the pod stays in endpoints", func() {
+			clock.now = deletionTime.Add(301 * time.Second)
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+		})
+	})
+
+	Describe("HandleGracefulDrain with a hostPort pod", func() {
+		var (
+			pod          *corev1.Pod
+			clock        *fakeClock
+			deletionTime metav1.Time
+		)
+
+		BeforeEach(func() {
+			deletionTime = metav1.NewTime(now.Truncate(time.Second).Add(-5 * time.Second))
+			clock = &fakeClock{now: deletionTime.Add(5 * time.Second)}
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					Namespace:         "default",
+					DeletionTimestamp: &deletionTime,
+					// Left the endpoints well before the deletion
+					Annotations: map[string]string{
+						DeregisteredAtAnnotation: deletionTime.Add(-time.Minute).UTC().Format(time.RFC3339),
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "ingress", Image: "ingress", Ports: []corev1.ContainerPort{{ContainerPort: 80, HostPort: 80}}},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					PodIP:      "10.0.0.1",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			}
+
+			config.postDeregistration = 10 * time.Second
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config).WithClock(clock)
+		})
+
+		It("should complete on endpoint absence without considerHostPort", func() {
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonDeregistered))
+		})
+
+		It("should rely on the grace period alone with considerHostPort", func() {
+			config.considerHostPort = true
+
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+
+			clock.now = deletionTime.Add(31 * time.Second)
+			result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Expect(result.Reason).To(Equal(CompletionReasonNoConnections))
+		})
+	})
+
+	Describe("checkActiveConnections with active-traffic annotations", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			config.activeTrafficAnnotations = []string{"traffic.mycompany.io/active"}
+			// No services, so the pod is in no endpoints
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					PodIP:      "10.0.0.1",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			}
+		})
+
+		It("should keep waiting while the annotation is true even outside endpoints", func() {
+			pod.Annotations["traffic.mycompany.io/active"] = "true"
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+		})
+
+		It("should fall back to the endpoints check when the annotation is false", func() {
+			pod.Annotations["traffic.mycompany.io/active"] = "false"
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeFalse())
+		})
+
+		It("should ignore annotations that are not configured", func() {
+			pod.Annotations["traffic.other.io/active"] = "true"
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeFalse())
+		})
+	})
+
+	Describe("checkActiveConnections with traffic weights", func() {
+		var (
+			pod     *corev1.Pod
+			weights *mockTrafficWeight
+		)
+
+		BeforeEach(func() {
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			}
+
+			config.connectionCheckMode = ConnectionCheckModeConntrack
+			config.trafficWeightThreshold = 0.05
+			weights = &mockTrafficWeight{weight: 1.0}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			drainHandler = NewDrainHandler(fakeClient, config).
+				WithConnTracker(&mockConnTracker{established: 3}).
+				WithTrafficWeightProvider(weights)
+		})
+
+		It("should treat a pod below the threshold as drained", func() {
+			weights.weight = 0.01
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeFalse())
+		})
+
+		It("should check connections for a pod at or above the threshold", func() {
+			weights.weight = 0.05
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+		})
+
+		It("should check connections when the weight cannot be determined", func() {
+			weights.weight = 0.01
+			weights.err = errors.New("mesh unavailable")
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+		})
+
+		It("should ignore weights when no threshold is configured", func() {
+			config.trafficWeightThreshold = 0
+			weights.weight = 0.01
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+		})
+
+		It("should read the traffic-weight annotation by default", func() {
+			drainHandler = NewDrainHandler(fakeClient, config).WithConnTracker(&mockConnTracker{established: 3})
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeTrue())
+
+			pod.Annotations = map[string]string{TrafficWeightAnnotation: "0.01"}
+			hasConnections, err = drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeFalse())
+		})
+	})
+
+	Describe("AnnotationTrafficWeight", func() {
+		It("should default to full traffic without the annotation", func() {
+			weight, err := AnnotationTrafficWeight{}.TrafficWeight(ctx, &corev1.Pod{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(weight).To(Equal(1.0))
+		})
+
+		It("should reject values outside 0 to 1", func() {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{TrafficWeightAnnotation: "5"},
+			}}
+			_, err := AnnotationTrafficWeight{}.TrafficWeight(ctx, pod)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("checkActiveConnections with an endpoint breaker", func() {
+		var (
+			pod       *corev1.Pod
+			breaker   *CircuitBreaker
+			listCalls int
+		)
+
+		BeforeEach(func() {
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					PodIP:      "10.0.0.1",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			}
+
+			listCalls = 0
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						listCalls++
+						return apierrors.NewServiceUnavailable("api unavailable")
+					},
+				}).
+				Build()
+			breaker = NewCircuitBreaker(2, time.Minute, 30*time.Second).WithClock(&fakeClock{now: now})
+			drainHandler = NewDrainHandler(fakeClient, config).WithEndpointBreaker(breaker)
+		})
+
+		It("should skip the endpoint check once the circuit opens", func() {
+			for i := 0; i < 2; i++ {
+				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				Expect(err).To(HaveOccurred())
+				Expect(hasConnections).To(BeTrue())
+			}
+			Expect(breaker.State()).To(Equal(CircuitOpen))
+
+			hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasConnections).To(BeFalse())
+			Expect(listCalls).To(Equal(2))
+		})
+	})
+
+	Describe("podEndpointServices with the service selector index", func() {
+		var (
+			pod            *corev1.Pod
+			objects        []client.Object
+			servicesListed int
+			endpointGets   int
+		)
+
+		newHandler := func(indexed bool) *DrainHandler {
+			builder := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						if err := c.List(ctx, list, opts...); err != nil {
+							return err
+						}
+						if serviceList, ok := list.(*corev1.ServiceList); ok {
+							servicesListed += len(serviceList.Items)
+						}
+						return nil
+					},
+					Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						if _, ok := obj.(*corev1.Endpoints); ok {
+							endpointGets++
+						}
+						return c.Get(ctx, key, obj, opts...)
+					},
+				})
+			if indexed {
+				builder = builder.WithIndex(&corev1.Service{}, ServiceSelectorIndexField, ServiceSelectorPairs)
+			}
+
+			handler := NewDrainHandler(builder.Build(), config)
+			if indexed {
+				handler.WithServiceSelectorIndex()
+			}
+			return handler
+		}
+
+		BeforeEach(func() {
+			servicesListed, endpointGets = 0, 0
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web-0",
+					Namespace: "default",
+					Labels:    map[string]string{"app": "web", "tier": "frontend"},
+				},
+				Status: corev1.PodStatus{PodIP: "10.0.0.1"},
+			}
+
+			// One service selects the pod among many that don't
+			objects = []client.Object{
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web", "tier": "frontend"}},
+				},
+				&corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+				},
+				// Shares a label pair with the pod but its full selector doesn't match
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+					Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web", "tier": "backend"}},
+				},
+			}
+			for i := 0; i < 50; i++ {
+				name := fmt.Sprintf("other-%d", i)
+				objects = append(objects, &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": name}},
+				})
+			}
+		})
+
+		It("should find the same services while listing far fewer of them", func() {
+			services, err := newHandler(false).podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(ConsistOf("web"))
+			Expect(servicesListed).To(Equal(52))
+			Expect(endpointGets).To(Equal(1))
+
+			servicesListed, endpointGets = 0, 0
+			services, err = newHandler(true).podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(ConsistOf("web"))
+			// "web" is returned for both of the pod's label pairs and "backend" for app=web
+			Expect(servicesListed).To(Equal(3))
+			Expect(endpointGets).To(Equal(1))
+		})
+
+		It("should index services by each selector pair", func() {
+			Expect(ServiceSelectorPairs(objects[0])).To(ConsistOf("app=web", "tier=frontend"))
+			Expect(ServiceSelectorPairs(&corev1.Service{})).To(BeEmpty())
+		})
+	})
+
+	Describe("podEndpointServices with a headless service", func() {
+		var pod *corev1.Pod
+
+		newHandler := func(subset corev1.EndpointSubset) *DrainHandler {
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: corev1.ClusterIPNone,
+					Selector:  map[string]string{"app": "web"},
+				},
+			}
+			endpoints := &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Subsets:    []corev1.EndpointSubset{subset},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, endpoints).Build()
+			return NewDrainHandler(fakeClient, config)
+		}
+
+		BeforeEach(func() {
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web-0",
+					Namespace: "default",
+					Labels:    map[string]string{"app": "web"},
+				},
+				Spec: corev1.PodSpec{
+					Hostname:  "web-0",
+					Subdomain: "web",
+				},
+				Status: corev1.PodStatus{PodIP: "10.0.0.1"},
 			}
+		})
+
+		It("should not count a pod listed in NotReadyAddresses", func() {
+			drainHandler = newHandler(corev1.EndpointSubset{
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1", Hostname: "web-0"}},
+			})
+
+			services, err := drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(BeEmpty())
+		})
+
+		It("should match a ready address by the pod's hostname", func() {
+			// The address still carries the IP from before the pod was recreated
+			drainHandler = newHandler(corev1.EndpointSubset{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.9", Hostname: "web-0"}},
+			})
+
+			services, err := drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(Equal([]string{"web"}))
+		})
+
+		It("should match a ready address by its pod reference", func() {
+			drainHandler = newHandler(corev1.EndpointSubset{
+				Addresses: []corev1.EndpointAddress{{
+					IP:        "10.0.0.9",
+					TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-0"},
+				}},
+			})
+
+			services, err := drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(Equal([]string{"web"}))
+		})
 
-			isReady := drainHandler.isPodReady(pod)
-			Expect(isReady).To(BeFalse())
+		It("should not match another pod's hostname", func() {
+			drainHandler = newHandler(corev1.EndpointSubset{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.2", Hostname: "web-1"}},
+			})
+
+			services, err := drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(BeEmpty())
 		})
+	})
 
-		It("should return false when pod has no ready condition", func() {
-			pod := &corev1.Pod{
-				Status: corev1.PodStatus{
-					Conditions: []corev1.PodCondition{},
+	Describe("podEndpointServices across namespaces", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "api-1",
+					Namespace: "team-a",
+					Labels:    map[string]string{"app": "api"},
 				},
+				Status: corev1.PodStatus{PodIP: "10.0.0.1"},
+			}
+			// A selectorless service in another namespace whose endpoints a mesh maintains
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "api-export", Namespace: "team-b"},
+			}
+			endpoints := &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "api-export", Namespace: "team-b"},
+				Subsets: []corev1.EndpointSubset{{
+					Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+				}},
 			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, endpoints).Build()
+			drainHandler = NewDrainHandler(fakeClient, config)
+		})
 
-			isReady := drainHandler.isPodReady(pod)
-			Expect(isReady).To(BeFalse())
+		It("should ignore other namespaces by default", func() {
+			services, err := drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(BeEmpty())
 		})
 
-		It("should return false when pod has other conditions but no ready condition", func() {
-			pod := &corev1.Pod{
-				Status: corev1.PodStatus{
-					Conditions: []corev1.PodCondition{
-						{
-							Type:   corev1.PodScheduled,
-							Status: corev1.ConditionTrue,
-						},
-					},
-				},
-			}
+		It("should find the pod in another namespace's endpoints when enabled", func() {
+			config.crossNamespaceCheck = true
 
-			isReady := drainHandler.isPodReady(pod)
-			Expect(isReady).To(BeFalse())
+			services, err := drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(Equal([]string{"team-b/api-export"}))
+		})
+
+		It("should only search the configured namespaces", func() {
+			config.crossNamespaceCheck = true
+			config.crossNamespaces = []string{"team-c"}
+
+			services, err := drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(BeEmpty())
+
+			config.crossNamespaces = []string{"team-b", "team-c"}
+			services, err = drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(services).To(Equal([]string{"team-b/api-export"}))
 		})
 	})
 
-	Describe("checkActiveConnections", func() {
+	Describe("podEndpointServices", func() {
 		BeforeEach(func() {
 			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
 			drainHandler = NewDrainHandler(fakeClient, config)
 		})
 
-		Context("when pod is not running", func() {
-			It("should return false for Pending phase", func() {
+		Context("when pod has no IP address", func() {
+			It("should return no services", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
 						Namespace: "default",
 					},
 					Status: corev1.PodStatus{
-						Phase: corev1.PodPending,
+						PodIP: "",
 					},
 				}
 
-				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				services, err := drainHandler.podEndpointServices(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasConnections).To(BeFalse())
+				Expect(services).To(BeEmpty())
 			})
+		})
 
-			It("should return false for Succeeded phase", func() {
-				pod := &corev1.Pod{
+		Context("when listing services fails", func() {
+			var pod *corev1.Pod
+
+			BeforeEach(func() {
+				pod = &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
 						Namespace: "default",
+						Labels: map[string]string{
+							"app": "test-app",
+						},
 					},
 					Status: corev1.PodStatus{
-						Phase: corev1.PodSucceeded,
+						PodIP: "10.0.0.1",
 					},
 				}
-
-				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(hasConnections).To(BeFalse())
 			})
 
-			It("should return false for Failed phase", func() {
-				pod := &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
-						Namespace: "default",
-					},
-					Status: corev1.PodStatus{
-						Phase: corev1.PodFailed,
-					},
-				}
+			It("should fall back to no active endpoints when RBAC forbids the list", func() {
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithInterceptorFuncs(interceptor.Funcs{
+						List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+							return apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "", errors.New("rbac denied"))
+						},
+					}).
+					Build()
+				drainHandler = NewDrainHandler(fakeClient, config)
 
-				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+				services, err := drainHandler.podEndpointServices(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasConnections).To(BeFalse())
+				Expect(services).To(BeEmpty())
 			})
-		})
-
-		Context("when pod is running", func() {
-			It("should return false when pod has no containers", func() {
-				pod := &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
-						Namespace: "default",
-					},
-					Spec: corev1.PodSpec{
-						Containers: []corev1.Container{},
-					},
-					Status: corev1.PodStatus{
-						Phase: corev1.PodRunning,
-					},
-				}
 
-				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
+			It("should warn about the forbidden list once per shared Once", func() {
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithInterceptorFuncs(interceptor.Funcs{
+						List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+							return apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "", errors.New("rbac denied"))
+						},
+					}).
+					Build()
+				var shared sync.Once
+				_, err := NewDrainHandler(fakeClient, config).WithForbiddenWarningOnce(&shared).podEndpointServices(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasConnections).To(BeFalse())
+
+				// A handler with its own Once still has its warning to give
+				own := NewDrainHandler(fakeClient, config)
+				Expect(own.forbiddenWarning).ToNot(BeIdenticalTo(&shared))
+
+				warnedAgain := false
+				shared.Do(func() { warnedAgain = true })
+				Expect(warnedAgain).To(BeFalse())
+				own.forbiddenWarning.Do(func() { warnedAgain = true })
+				Expect(warnedAgain).To(BeTrue())
 			})
 
-			It("should return false when pod has no exposed ports", func() {
-				pod := &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
-						Namespace: "default",
-					},
-					Spec: corev1.PodSpec{
-						Containers: []corev1.Container{
-							{
-								Name:  "app",
-								Image: "nginx",
-								Ports: []corev1.ContainerPort{},
-							},
+			It("should give up on a list that outlives the API call timeout", func() {
+				config.apiCallTimeout = 50 * time.Millisecond
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithInterceptorFuncs(interceptor.Funcs{
+						List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+							select {
+							case <-ctx.Done():
+								return ctx.Err()
+							case <-time.After(5 * time.Second):
+								return c.List(ctx, list, opts...)
+							}
 						},
-					},
-					Status: corev1.PodStatus{
-						Phase: corev1.PodRunning,
-					},
-				}
+					}).
+					Build()
+				drainHandler = NewDrainHandler(fakeClient, config)
 
-				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(hasConnections).To(BeFalse())
+				start := time.Now()
+				_, err := drainHandler.podEndpointServices(ctx, pod)
+				Expect(err).To(MatchError(context.DeadlineExceeded))
+				Expect(time.Since(start)).To(BeNumerically("<", time.Second))
 			})
 
-			It("should return false when pod is not ready despite having exposed ports", func() {
-				pod := &corev1.Pod{
+			It("should treat a timed-out endpoints lookup as possibly serving", func() {
+				config.apiCallTimeout = 50 * time.Millisecond
+				service := &corev1.Service{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
+						Name:      "test-service",
 						Namespace: "default",
 					},
-					Spec: corev1.PodSpec{
-						Containers: []corev1.Container{
-							{
-								Name:  "app",
-								Image: "nginx",
-								Ports: []corev1.ContainerPort{
-									{
-										ContainerPort: 80,
-										Protocol:      corev1.ProtocolTCP,
-									},
-								},
-							},
-						},
+					Spec: corev1.ServiceSpec{
+						Selector: map[string]string{"app": "test-app"},
 					},
-					Status: corev1.PodStatus{
-						Phase: corev1.PodRunning,
-						Conditions: []corev1.PodCondition{
-							{
-								Type:   corev1.PodReady,
-								Status: corev1.ConditionFalse,
-							},
+				}
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(service).
+					WithInterceptorFuncs(interceptor.Funcs{
+						Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+							select {
+							case <-ctx.Done():
+								return ctx.Err()
+							case <-time.After(5 * time.Second):
+								return c.Get(ctx, key, obj, opts...)
+							}
 						},
-					},
+					}).
+					Build()
+				drainHandler = NewDrainHandler(fakeClient, config)
+
+				pod.Status.Phase = corev1.PodRunning
+				pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+				pod.Spec.Containers = []corev1.Container{
+					{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
 				}
 
 				hasConnections, err := drainHandler.checkActiveConnections(ctx, pod)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(hasConnections).To(BeFalse())
+				Expect(err).To(MatchError(context.DeadlineExceeded))
+				Expect(hasConnections).To(BeTrue())
 			})
-		})
-	})
-
-	Describe("checkPodEndpoints", func() {
-		BeforeEach(func() {
-			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
-			drainHandler = NewDrainHandler(fakeClient, config)
-		})
 
-		Context("when pod has no IP address", func() {
-			It("should return false", func() {
-				pod := &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "test-pod",
-						Namespace: "default",
-					},
-					Status: corev1.PodStatus{
-						PodIP: "",
-					},
-				}
+			It("should return the error for other failures", func() {
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithInterceptorFuncs(interceptor.Funcs{
+						List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+							return apierrors.NewServiceUnavailable("api unavailable")
+						},
+					}).
+					Build()
+				drainHandler = NewDrainHandler(fakeClient, config)
 
-				hasEndpoints, err := drainHandler.checkPodEndpoints(ctx, pod)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(hasEndpoints).To(BeFalse())
+				_, err := drainHandler.podEndpointServices(ctx, pod)
+				Expect(err).To(HaveOccurred())
 			})
 		})
 
 		Context("when pod has IP address", func() {
-			It("should return false when no services exist", func() {
+			It("should return no services when no services exist", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
@@ -465,12 +3347,12 @@ var _ = Describe("DrainHandler", func() {
 					},
 				}
 
-				hasEndpoints, err := drainHandler.checkPodEndpoints(ctx, pod)
+				services, err := drainHandler.podEndpointServices(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasEndpoints).To(BeFalse())
+				Expect(services).To(BeEmpty())
 			})
 
-			It("should return false when service exists but pod IP is not in endpoints", func() {
+			It("should return no services when service exists but pod IP is not in endpoints", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
@@ -518,12 +3400,71 @@ var _ = Describe("DrainHandler", func() {
 					Build()
 				drainHandler = NewDrainHandler(fakeClient, config)
 
-				hasEndpoints, err := drainHandler.checkPodEndpoints(ctx, pod)
+				services, err := drainHandler.podEndpointServices(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(services).To(BeEmpty())
+			})
+
+			It("should hold a freshly ready pod that matches a service but is not in its endpoints yet", func() {
+				config.endpointSettle = 5 * time.Second
+				clock := &fakeClock{now: now}
+				readySince := func(ago time.Duration) *corev1.Pod {
+					return &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "test-pod",
+							Namespace: "default",
+							Labels: map[string]string{
+								"app": "test-app",
+							},
+						},
+						Status: corev1.PodStatus{
+							PodIP: "10.0.0.1",
+							Conditions: []corev1.PodCondition{
+								{
+									Type:               corev1.PodReady,
+									Status:             corev1.ConditionTrue,
+									LastTransitionTime: metav1.NewTime(now.Add(-ago)),
+								},
+							},
+						},
+					}
+				}
+
+				service := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-service",
+						Namespace: "default",
+					},
+					Spec: corev1.ServiceSpec{
+						Selector: map[string]string{
+							"app": "test-app",
+						},
+					},
+				}
+				endpoints := &corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-service",
+						Namespace: "default",
+					},
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(service, endpoints).
+					Build()
+				drainHandler = NewDrainHandler(fakeClient, config).WithClock(clock)
+
+				services, err := drainHandler.podEndpointServices(ctx, readySince(2*time.Second))
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasEndpoints).To(BeFalse())
+				Expect(services).To(Equal([]string{"test-service"}))
+
+				// Once the settle window has passed, absence from endpoints is trusted
+				services, err = drainHandler.podEndpointServices(ctx, readySince(10*time.Second))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(services).To(BeEmpty())
 			})
 
-			It("should return true when service exists and pod IP is in endpoints", func() {
+			It("should return the service when its endpoints contain the pod IP", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
@@ -571,12 +3512,12 @@ var _ = Describe("DrainHandler", func() {
 					Build()
 				drainHandler = NewDrainHandler(fakeClient, config)
 
-				hasEndpoints, err := drainHandler.checkPodEndpoints(ctx, pod)
+				services, err := drainHandler.podEndpointServices(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasEndpoints).To(BeTrue())
+				Expect(services).To(Equal([]string{"test-service"}))
 			})
 
-			It("should return false when service has no selector", func() {
+			It("should return no services when service has no selector", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
@@ -606,12 +3547,12 @@ var _ = Describe("DrainHandler", func() {
 					Build()
 				drainHandler = NewDrainHandler(fakeClient, config)
 
-				hasEndpoints, err := drainHandler.checkPodEndpoints(ctx, pod)
+				services, err := drainHandler.podEndpointServices(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasEndpoints).To(BeFalse())
+				Expect(services).To(BeEmpty())
 			})
 
-			It("should return false when pod labels don't match service selector", func() {
+			It("should return no services when pod labels don't match service selector", func() {
 				pod := &corev1.Pod{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test-pod",
@@ -643,9 +3584,9 @@ var _ = Describe("DrainHandler", func() {
 					Build()
 				drainHandler = NewDrainHandler(fakeClient, config)
 
-				hasEndpoints, err := drainHandler.checkPodEndpoints(ctx, pod)
+				services, err := drainHandler.podEndpointServices(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasEndpoints).To(BeFalse())
+				Expect(services).To(BeEmpty())
 			})
 
 			It("should continue checking when endpoints don't exist for a service", func() {
@@ -681,9 +3622,49 @@ var _ = Describe("DrainHandler", func() {
 					Build()
 				drainHandler = NewDrainHandler(fakeClient, config)
 
-				hasEndpoints, err := drainHandler.checkPodEndpoints(ctx, pod)
+				services, err := drainHandler.podEndpointServices(ctx, pod)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(services).To(BeEmpty())
+			})
+
+			It("should name the services whose endpoints still contain the pod", func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pod",
+						Namespace: "default",
+						Labels: map[string]string{
+							"app": "test-app",
+						},
+					},
+					Status: corev1.PodStatus{
+						PodIP: "10.0.0.1",
+					},
+				}
+
+				objects := []client.Object{}
+				for name, ip := range map[string]string{"svc-a": "10.0.0.1", "svc-b": "10.0.0.2", "svc-c": "10.0.0.1"} {
+					objects = append(objects,
+						&corev1.Service{
+							ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+							Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "test-app"}},
+						},
+						&corev1.Endpoints{
+							ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+							Subsets: []corev1.EndpointSubset{
+								{Addresses: []corev1.EndpointAddress{{IP: ip}}},
+							},
+						})
+				}
+
+				fakeClient = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(objects...).
+					Build()
+				drainHandler = NewDrainHandler(fakeClient, config)
+
+				services, err := drainHandler.podEndpointServices(ctx, pod)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hasEndpoints).To(BeFalse())
+				Expect(services).To(ConsistOf("svc-a", "svc-c"))
 			})
 		})
 	})
@@ -732,9 +3713,9 @@ var _ = Describe("DrainHandler", func() {
 			}
 
 			// No service exists, so no active connections
-			completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(completed).To(BeTrue())
+			Expect(result.Completed).To(BeTrue())
 		})
 
 		It("should wait when pod has active connections", func() {
@@ -809,9 +3790,9 @@ var _ = Describe("DrainHandler", func() {
 			drainHandler = NewDrainHandler(fakeClient, config)
 
 			// Pod has active connections, should continue waiting
-			completed, err := drainHandler.HandleGracefulDrain(ctx, pod)
+			result, err := drainHandler.HandleGracefulDrain(ctx, pod)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(completed).To(BeFalse())
+			Expect(result.Completed).To(BeFalse())
 		})
 	})
 })
\ No newline at end of file
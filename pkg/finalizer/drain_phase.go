@@ -0,0 +1,70 @@
+package finalizer
+
+import "time"
+
+// DrainPhase is where a pod is in its drain. A drain moves through the phases in order:
+// Pending → GracePeriod → Draining → Completed or TimedOut.
+type DrainPhase string
+
+const (
+	// DrainPhasePending is a managed pod that hasn't been deleted yet
+	DrainPhasePending DrainPhase = "pending"
+	// DrainPhaseGracePeriod is a deleted pod within its grace period, when nothing completes the drain
+	DrainPhaseGracePeriod DrainPhase = "grace-period"
+	// DrainPhaseDraining is a pod past its grace period whose connections are still checked.
+	// It keeps the value written by earlier versions so annotated pods survive an upgrade.
+	DrainPhaseDraining DrainPhase = "waiting-connections"
+	// DrainPhaseCompleted is a drain that finished before its timeout
+	DrainPhaseCompleted DrainPhase = "completed"
+	// DrainPhaseTimedOut is a drain ended by the drain timeout or the hard deadline
+	DrainPhaseTimedOut DrainPhase = "timed-out"
+)
+
+// drainPhaseOrder ranks the phases; a drain never moves to a lower rank
+var drainPhaseOrder = map[DrainPhase]int{
+	DrainPhasePending:     0,
+	DrainPhaseGracePeriod: 1,
+	DrainPhaseDraining:    2,
+	DrainPhaseCompleted:   3,
+	DrainPhaseTimedOut:    3,
+}
+
+// ComputeDrainPhase derives a drain's phase from its inputs: whether the pod is being
+// deleted, the time since its deletion, its grace period and, once decided, how the
+// drain completed
+func ComputeDrainPhase(deleting bool, elapsed, gracePeriod time.Duration, completed bool, reason string) DrainPhase {
+	switch {
+	case !deleting:
+		return DrainPhasePending
+	case completed && (reason == CompletionReasonTimeout || reason == CompletionReasonHardTimeout):
+		return DrainPhaseTimedOut
+	case completed:
+		return DrainPhaseCompleted
+	case elapsed < gracePeriod:
+		return DrainPhaseGracePeriod
+	default:
+		return DrainPhaseDraining
+	}
+}
+
+// IsTerminal reports whether the drain has ended
+func (p DrainPhase) IsTerminal() bool {
+	return p == DrainPhaseCompleted || p == DrainPhaseTimedOut
+}
+
+// Transition returns the phase a drain in p moves to when its inputs compute next, and
+// whether that is a change. Phases only move forward: a drain doesn't return to its
+// grace period when the grace period is extended, and a terminal phase is final. An
+// unknown or empty phase is treated as Pending.
+func (p DrainPhase) Transition(next DrainPhase) (DrainPhase, bool) {
+	if _, known := drainPhaseOrder[next]; !known {
+		return p, false
+	}
+	if p.IsTerminal() || next == p {
+		return p, false
+	}
+	if drainPhaseOrder[next] < drainPhaseOrder[p] {
+		return p, false
+	}
+	return next, true
+}
@@ -0,0 +1,67 @@
+package finalizer
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DrainPhase", func() {
+	const grace = 30 * time.Second
+
+	DescribeTable("ComputeDrainPhase",
+		func(deleting bool, elapsed time.Duration, completed bool, reason string, expected DrainPhase) {
+			Expect(ComputeDrainPhase(deleting, elapsed, grace, completed, reason)).To(Equal(expected))
+		},
+		Entry("not deleted", false, time.Duration(0), false, "", DrainPhasePending),
+		Entry("not deleted, released", false, time.Duration(0), true, CompletionReasonNotDeleting, DrainPhasePending),
+		Entry("within the grace period", true, 10*time.Second, false, "", DrainPhaseGracePeriod),
+		Entry("at the end of the grace period", true, grace, false, "", DrainPhaseDraining),
+		Entry("past the grace period", true, time.Minute, false, "", DrainPhaseDraining),
+		Entry("completed without connections", true, time.Minute, true, CompletionReasonNoConnections, DrainPhaseCompleted),
+		Entry("completed within the grace period", true, time.Second, true, CompletionReasonForceCompleted, DrainPhaseCompleted),
+		Entry("timed out", true, 5*time.Minute, true, CompletionReasonTimeout, DrainPhaseTimedOut),
+		Entry("hit the hard deadline", true, 10*time.Minute, true, CompletionReasonHardTimeout, DrainPhaseTimedOut),
+	)
+
+	DescribeTable("Transition",
+		func(from, next, expected DrainPhase, changed bool) {
+			phase, ok := from.Transition(next)
+			Expect(phase).To(Equal(expected))
+			Expect(ok).To(Equal(changed))
+		},
+		// From Pending
+		Entry("pending stays pending", DrainPhasePending, DrainPhasePending, DrainPhasePending, false),
+		Entry("pending to grace period", DrainPhasePending, DrainPhaseGracePeriod, DrainPhaseGracePeriod, true),
+		Entry("pending to draining", DrainPhasePending, DrainPhaseDraining, DrainPhaseDraining, true),
+		Entry("pending to completed", DrainPhasePending, DrainPhaseCompleted, DrainPhaseCompleted, true),
+		Entry("pending to timed out", DrainPhasePending, DrainPhaseTimedOut, DrainPhaseTimedOut, true),
+		// From GracePeriod
+		Entry("grace period back to pending", DrainPhaseGracePeriod, DrainPhasePending, DrainPhaseGracePeriod, false),
+		Entry("grace period stays", DrainPhaseGracePeriod, DrainPhaseGracePeriod, DrainPhaseGracePeriod, false),
+		Entry("grace period to draining", DrainPhaseGracePeriod, DrainPhaseDraining, DrainPhaseDraining, true),
+		Entry("grace period to completed", DrainPhaseGracePeriod, DrainPhaseCompleted, DrainPhaseCompleted, true),
+		Entry("grace period to timed out", DrainPhaseGracePeriod, DrainPhaseTimedOut, DrainPhaseTimedOut, true),
+		// From Draining
+		Entry("draining back to pending", DrainPhaseDraining, DrainPhasePending, DrainPhaseDraining, false),
+		Entry("draining back to grace period", DrainPhaseDraining, DrainPhaseGracePeriod, DrainPhaseDraining, false),
+		Entry("draining stays", DrainPhaseDraining, DrainPhaseDraining, DrainPhaseDraining, false),
+		Entry("draining to completed", DrainPhaseDraining, DrainPhaseCompleted, DrainPhaseCompleted, true),
+		Entry("draining to timed out", DrainPhaseDraining, DrainPhaseTimedOut, DrainPhaseTimedOut, true),
+		// From the terminal phases
+		Entry("completed back to pending", DrainPhaseCompleted, DrainPhasePending, DrainPhaseCompleted, false),
+		Entry("completed back to grace period", DrainPhaseCompleted, DrainPhaseGracePeriod, DrainPhaseCompleted, false),
+		Entry("completed back to draining", DrainPhaseCompleted, DrainPhaseDraining, DrainPhaseCompleted, false),
+		Entry("completed stays", DrainPhaseCompleted, DrainPhaseCompleted, DrainPhaseCompleted, false),
+		Entry("completed to timed out", DrainPhaseCompleted, DrainPhaseTimedOut, DrainPhaseCompleted, false),
+		Entry("timed out back to pending", DrainPhaseTimedOut, DrainPhasePending, DrainPhaseTimedOut, false),
+		Entry("timed out back to grace period", DrainPhaseTimedOut, DrainPhaseGracePeriod, DrainPhaseTimedOut, false),
+		Entry("timed out back to draining", DrainPhaseTimedOut, DrainPhaseDraining, DrainPhaseTimedOut, false),
+		Entry("timed out to completed", DrainPhaseTimedOut, DrainPhaseCompleted, DrainPhaseTimedOut, false),
+		Entry("timed out stays", DrainPhaseTimedOut, DrainPhaseTimedOut, DrainPhaseTimedOut, false),
+		// Unknown phases
+		Entry("empty to grace period", DrainPhase(""), DrainPhaseGracePeriod, DrainPhaseGracePeriod, true),
+		Entry("unknown next phase", DrainPhaseGracePeriod, DrainPhase("bogus"), DrainPhaseGracePeriod, false),
+	)
+})
@@ -0,0 +1,53 @@
+package finalizer
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// checkServicesConcurrently checks the services' endpoints for the pod on up to
+// concurrency workers. Every service is checked, even after one is found listing the pod,
+// so inEndpoints names all of them as the sequential check does. Without a match, the
+// errors of all checks are returned joined.
+func (d *DrainHandler) checkServicesConcurrently(ctx context.Context, pod *corev1.Pod, services []corev1.Service, concurrency int) (inEndpoints, notYetListed []string, err error) {
+	// Each worker only writes the entries of the services it took, so no locking is needed
+	listed := make([]bool, len(services))
+	errs := make([]error, len(services))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(services)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				listed[i], errs[i] = d.serviceListsPod(ctx, pod, &services[i])
+			}
+		}()
+	}
+
+	for i := range services {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range services {
+		switch {
+		case listed[i]:
+			inEndpoints = append(inEndpoints, services[i].Name)
+		case errs[i] == nil:
+			notYetListed = append(notYetListed, services[i].Name)
+		}
+	}
+	if len(inEndpoints) > 0 {
+		return inEndpoints, nil, nil
+	}
+	if err := stderrors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+	return nil, notYetListed, nil
+}
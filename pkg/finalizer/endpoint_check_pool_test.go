@@ -0,0 +1,200 @@
+package finalizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newEndpointCheckObjects returns a pod and count services selecting it; the pod is only
+// listed in the endpoints of the services named in listedIn
+func newEndpointCheckObjects(count int, listedIn ...string) (*corev1.Pod, []client.Object) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test-app"},
+		},
+		Status: corev1.PodStatus{PodIP: "10.0.0.1"},
+	}
+
+	listed := map[string]bool{}
+	for _, name := range listedIn {
+		listed[name] = true
+	}
+
+	var objects []client.Object
+	for i := range count {
+		name := fmt.Sprintf("svc-%02d", i)
+		objects = append(objects, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "test-app"}},
+		})
+		address := corev1.EndpointAddress{IP: "10.0.0.2"}
+		if listed[name] {
+			address.IP = pod.Status.PodIP
+		}
+		objects = append(objects, &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{address}}},
+		})
+	}
+	return pod, objects
+}
+
+var _ = Describe("concurrent endpoint checks", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		config *mockConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		config = &mockConfig{
+			gracePeriod:              30 * time.Second,
+			drainTimeout:             300 * time.Second,
+			tcpPortsOnly:             true,
+			apiCallTimeout:           5 * time.Second,
+			endpointCheckConcurrency: 2,
+		}
+	})
+
+	It("should report every service that lists the pod", func() {
+		config.endpointCheckConcurrency = 4
+		pod, objects := newEndpointCheckObjects(10, "svc-01", "svc-04", "svc-08")
+		var gets atomic.Int32
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objects...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					gets.Add(1)
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).
+			Build()
+		drainHandler := NewDrainHandler(fakeClient, config)
+
+		services, err := drainHandler.podEndpointServices(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(services).To(Equal([]string{"svc-01", "svc-04", "svc-08"}))
+		Expect(gets.Load()).To(BeNumerically(">=", 10))
+	})
+
+	It("should report every service when none lists the pod", func() {
+		config.endpointSettle = time.Minute
+		pod, objects := newEndpointCheckObjects(5)
+		pod.Status.Conditions = []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+		}
+		drainHandler := NewDrainHandler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(), config)
+
+		services, err := drainHandler.podEndpointServices(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(services).To(Equal([]string{"svc-00", "svc-01", "svc-02", "svc-03", "svc-04"}))
+	})
+
+	It("should find a match among many services", func() {
+		pod, objects := newEndpointCheckObjects(20, "svc-13")
+		drainHandler := NewDrainHandler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(), config)
+
+		services, err := drainHandler.podEndpointServices(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(services).To(Equal([]string{"svc-13"}))
+	})
+
+	It("should join the errors of timed-out checks when no service lists the pod", func() {
+		config.apiCallTimeout = 50 * time.Millisecond
+		pod, objects := newEndpointCheckObjects(4)
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objects...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if key.Name == "svc-01" || key.Name == "svc-03" {
+						<-ctx.Done()
+						return ctx.Err()
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).
+			Build()
+		drainHandler := NewDrainHandler(fakeClient, config)
+
+		_, err := drainHandler.podEndpointServices(ctx, pod)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		var joined interface{ Unwrap() []error }
+		Expect(errors.As(err, &joined)).To(BeTrue())
+		Expect(joined.Unwrap()).To(HaveLen(2))
+	})
+
+	It("should prefer a match over a timed-out check", func() {
+		config.apiCallTimeout = 50 * time.Millisecond
+		pod, objects := newEndpointCheckObjects(4, "svc-03")
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objects...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if key.Name == "svc-00" {
+						<-ctx.Done()
+						return ctx.Err()
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).
+			Build()
+		drainHandler := NewDrainHandler(fakeClient, config)
+
+		services, err := drainHandler.podEndpointServices(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(services).To(Equal([]string{"svc-03"}))
+	})
+})
+
+// BenchmarkPodEndpointServices compares checking 32 services one after another with a
+// worker pool, each endpoints Get taking a millisecond as a round trip would
+func BenchmarkPodEndpointServices(b *testing.B) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		b.Fatal(err)
+	}
+	pod, objects := newEndpointCheckObjects(32, "svc-31")
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				time.Sleep(time.Millisecond)
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).
+		Build()
+
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			config := &mockConfig{apiCallTimeout: 5 * time.Second, endpointCheckConcurrency: concurrency}
+			drainHandler := NewDrainHandler(fakeClient, config)
+			for b.Loop() {
+				if _, err := drainHandler.podEndpointServices(context.Background(), pod); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// serviceEndpointSlices returns the EndpointSlices of the service
+func (d *DrainHandler) serviceEndpointSlices(ctx context.Context, service *corev1.Service) ([]discoveryv1.EndpointSlice, error) {
+	var sliceList discoveryv1.EndpointSliceList
+	listCtx, cancelList := d.apiCallContext(ctx)
+	defer cancelList()
+	if err := d.client.List(listCtx, &sliceList, client.InNamespace(service.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: service.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list endpoint slices of service %s: %w", service.Name, err)
+	}
+	return sliceList.Items, nil
+}
+
+// endpointSlicesContain reports whether the pod is a serving endpoint of the slices. Pods are
+// matched like in endpointsContain. A terminating pod often stays in its slice with
+// serving=false or terminating=true; it no longer receives new traffic, so it doesn't count.
+func endpointSlicesContain(slices []discoveryv1.EndpointSlice, pod *corev1.Pod, headless bool, serviceName string) bool {
+	for i := range slices {
+		for _, endpoint := range slices[i].Endpoints {
+			if !endpointSliceMatches(&endpoint, pod, headless, serviceName) {
+				continue
+			}
+			if endpointServing(endpoint.Conditions) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func endpointSliceMatches(endpoint *discoveryv1.Endpoint, pod *corev1.Pod, headless bool, serviceName string) bool {
+	for _, address := range endpoint.Addresses {
+		if address != "" && address == pod.Status.PodIP {
+			return true
+		}
+	}
+	if ref := endpoint.TargetRef; ref != nil && ref.Kind == "Pod" &&
+		ref.Namespace == pod.Namespace && ref.Name == pod.Name {
+		return true
+	}
+	return headless && endpoint.Hostname != nil && *endpoint.Hostname != "" &&
+		*endpoint.Hostname == pod.Spec.Hostname && pod.Spec.Subdomain == serviceName
+}
+
+// endpointServing interprets the endpoint conditions the way kube-proxy does: unset ready
+// means ready, unset serving falls back to ready and unset terminating means not terminating
+func endpointServing(conditions discoveryv1.EndpointConditions) bool {
+	if conditions.Terminating != nil && *conditions.Terminating {
+		return false
+	}
+	if conditions.Serving != nil {
+		return *conditions.Serving
+	}
+	return conditions.Ready == nil || *conditions.Ready
+}
@@ -0,0 +1,151 @@
+package finalizer
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+var _ = Describe("EndpointSlice connection check", func() {
+	var (
+		ctx          context.Context
+		config       *mockConfig
+		pod          *corev1.Pod
+		service      *corev1.Service
+		deletionTime metav1.Time
+	)
+
+	newSlice := func(conditions discoveryv1.EndpointConditions) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-abc12",
+				Namespace: "default",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "web"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: conditions},
+			},
+		}
+	}
+
+	newHandler := func(objects ...client.Object) *DrainHandler {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(discoveryv1.AddToScheme(scheme)).To(Succeed())
+		objects = append(objects, pod, service)
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		return NewDrainHandler(fakeClient, config).WithClock(&fakeClock{now: deletionTime.Add(40 * time.Second)})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		config = &mockConfig{
+			gracePeriod:        30 * time.Second,
+			drainTimeout:       300 * time.Second,
+			hardDeadlineBuffer: 60 * time.Second,
+			tcpPortsOnly:       true,
+			servingPhases:      []string{string(corev1.PodRunning)},
+			useEndpointSlices:  true,
+		}
+
+		deletionTime = metav1.NewTime(time.Now().Truncate(time.Second))
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "web-0",
+				Namespace:         "default",
+				Labels:            map[string]string{"app": "web"},
+				DeletionTimestamp: &deletionTime,
+				Finalizers:        []string{"example.com/other"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "app", Image: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				PodIP:      "10.0.0.1",
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+		}
+	})
+
+	DescribeTable("should interpret the endpoint conditions",
+		func(conditions discoveryv1.EndpointConditions, expectedServing bool) {
+			drainHandler := newHandler(newSlice(conditions))
+
+			services, err := drainHandler.podEndpointServices(ctx, pod)
+			Expect(err).ToNot(HaveOccurred())
+			if expectedServing {
+				Expect(services).To(Equal([]string{"web"}))
+			} else {
+				Expect(services).To(BeEmpty())
+			}
+		},
+		Entry("ready", discoveryv1.EndpointConditions{Ready: boolPtr(true), Serving: boolPtr(true)}, true),
+		Entry("no conditions reported", discoveryv1.EndpointConditions{}, true),
+		Entry("not ready without serving", discoveryv1.EndpointConditions{Ready: boolPtr(false)}, false),
+		Entry("not serving", discoveryv1.EndpointConditions{Ready: boolPtr(false), Serving: boolPtr(false)}, false),
+		Entry("terminating but still serving",
+			discoveryv1.EndpointConditions{Ready: boolPtr(false), Serving: boolPtr(true), Terminating: boolPtr(true)}, false),
+		Entry("terminating and not serving",
+			discoveryv1.EndpointConditions{Ready: boolPtr(false), Serving: boolPtr(false), Terminating: boolPtr(true)}, false),
+	)
+
+	It("should ignore slices of other services", func() {
+		slice := newSlice(discoveryv1.EndpointConditions{Ready: boolPtr(true)})
+		slice.Labels[discoveryv1.LabelServiceName] = "api"
+		drainHandler := newHandler(slice)
+
+		services, err := drainHandler.podEndpointServices(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(services).To(BeEmpty())
+	})
+
+	It("should match an endpoint by its pod reference", func() {
+		slice := newSlice(discoveryv1.EndpointConditions{Ready: boolPtr(true)})
+		slice.Endpoints[0].Addresses = []string{"10.0.0.99"}
+		slice.Endpoints[0].TargetRef = &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-0"}
+		drainHandler := newHandler(slice)
+
+		services, err := drainHandler.podEndpointServices(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(services).To(Equal([]string{"web"}))
+	})
+
+	It("should complete the drain of a pod kept in its slice as terminating", func() {
+		drainHandler := newHandler(newSlice(discoveryv1.EndpointConditions{
+			Ready: boolPtr(false), Serving: boolPtr(false), Terminating: boolPtr(true),
+		}))
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+		Expect(result.Reason).To(Equal(CompletionReasonNoConnections))
+	})
+
+	It("should keep waiting while the pod is still serving in its slice", func() {
+		drainHandler := newHandler(newSlice(discoveryv1.EndpointConditions{Ready: boolPtr(true), Serving: boolPtr(true)}))
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+	})
+})
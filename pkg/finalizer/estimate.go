@@ -0,0 +1,48 @@
+package finalizer
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DrainEstimate projects how a drain of the pod would play out under the current config
+type DrainEstimate struct {
+	GracePeriod  time.Duration
+	DrainTimeout time.Duration
+	// HasActiveEndpoints reports whether the pod currently looks like it is serving traffic
+	HasActiveEndpoints bool
+	// ProjectedCompletion assumes connections clear by the end of the grace period
+	ProjectedCompletion time.Time
+	// LatestCompletion is when the drain timeout would release a pod that keeps serving
+	LatestCompletion time.Time
+}
+
+// EstimateDrain predicts the drain of the pod without modifying anything. Pods that are
+// not being deleted are estimated as if deletion started now.
+func (d *DrainHandler) EstimateDrain(ctx context.Context, pod *corev1.Pod) (DrainEstimate, error) {
+	window := d.drainWindow(ctx, pod)
+
+	start := d.clock.Now()
+	if pod.DeletionTimestamp != nil {
+		start = pod.DeletionTimestamp.Time
+	}
+
+	hasActiveEndpoints, err := d.checkActiveConnections(ctx, pod)
+	if err != nil {
+		return DrainEstimate{}, err
+	}
+
+	estimate := DrainEstimate{
+		GracePeriod:         window.GracePeriod,
+		DrainTimeout:        window.DrainTimeout,
+		HasActiveEndpoints:  hasActiveEndpoints,
+		ProjectedCompletion: start.Add(window.GracePeriod),
+		LatestCompletion:    start.Add(window.GracePeriod),
+	}
+	if hasActiveEndpoints {
+		estimate.LatestCompletion = start.Add(window.DrainTimeout)
+	}
+	return estimate, nil
+}
@@ -0,0 +1,102 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// KnativeRevisionLabel is set by Knative Serving on the pods of a revision
+const KnativeRevisionLabel = "serving.knative.dev/revision"
+
+// KnativeUnroutedAtAnnotation records when a Knative pod was first seen outside every
+// Endpoints object of its namespace, which starts its settle window
+const KnativeUnroutedAtAnnotation = "vpa-graceful-drain.cho.github.io/knative-unrouted-at"
+
+// IsKnativePod reports whether the pod belongs to a Knative Serving revision
+func IsKnativePod(pod *corev1.Pod) bool {
+	if _, ok := pod.Labels[KnativeRevisionLabel]; ok {
+		return true
+	}
+	_, ok := pod.Annotations[KnativeRevisionLabel]
+	return ok
+}
+
+// knativeRouted reports whether a Knative pod may still receive requests. Knative routes
+// to pods directly or through the activator using Endpoints it manages itself, often for
+// services without a selector, so every Endpoints object in the namespace is searched and
+// not-ready addresses count too. Once the pod is absent from all of them it still counts
+// as routed until knativeSettleSeconds have passed, for the activator to catch up.
+func (d *DrainHandler) knativeRouted(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	logger := log.FromContext(ctx)
+	now := d.clock.Now()
+
+	var endpointsList corev1.EndpointsList
+	listCtx, cancel := d.apiCallContext(ctx)
+	err := d.client.List(listCtx, &endpointsList, client.InNamespace(pod.Namespace))
+	cancel()
+	if err != nil {
+		return true, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	if name, listed := endpointsListingPod(endpointsList.Items, pod); listed {
+		logger.V(1).Info("Knative pod is still listed in endpoints", "pod", pod.Name, "endpoints", name)
+		if _, ok := pod.Annotations[KnativeUnroutedAtAnnotation]; ok {
+			// Routed again, so the settle window starts over once it leaves
+			podCopy := pod.DeepCopy()
+			delete(podCopy.Annotations, KnativeUnroutedAtAnnotation)
+			if err := d.client.Patch(ctx, podCopy, client.MergeFrom(pod)); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+
+	if value, ok := pod.Annotations[KnativeUnroutedAtAnnotation]; ok {
+		unroutedAt, parseErr := time.Parse(time.RFC3339, value)
+		if parseErr == nil {
+			return now.Sub(unroutedAt) < d.config.GetKnativeSettle(), nil
+		}
+		logger.Info("WARNING: ignoring invalid knative unrouted annotation",
+			"pod", pod.Name, "annotation", KnativeUnroutedAtAnnotation, "value", value)
+	}
+	if d.config.GetKnativeSettle() <= 0 {
+		return false, nil
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = map[string]string{}
+	}
+	podCopy.Annotations[KnativeUnroutedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	if err := d.client.Patch(ctx, podCopy, client.MergeFrom(pod)); err != nil {
+		return true, err
+	}
+	logger.Info("Knative pod left all endpoints, waiting for routing to settle",
+		"pod", pod.Name, "settle", d.config.GetKnativeSettle().String())
+	return true, nil
+}
+
+// endpointsListingPod returns the name of an Endpoints object listing the pod as a ready
+// or not-ready address
+func endpointsListingPod(endpointsList []corev1.Endpoints, pod *corev1.Pod) (string, bool) {
+	for i := range endpointsList {
+		for _, subset := range endpointsList[i].Subsets {
+			for _, addresses := range [][]corev1.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+				for _, address := range addresses {
+					if address.IP != "" && address.IP == pod.Status.PodIP {
+						return endpointsList[i].Name, true
+					}
+					if ref := address.TargetRef; ref != nil && ref.Kind == "Pod" && ref.Name == pod.Name {
+						return endpointsList[i].Name, true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,137 @@
+package finalizer
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("HandleGracefulDrain with a Knative pod", func() {
+	var (
+		ctx          context.Context
+		config       *mockConfig
+		clock        *fakeClock
+		fakeClient   client.Client
+		drainHandler *DrainHandler
+		pod          *corev1.Pod
+		endpoints    *corev1.Endpoints
+		deletionTime metav1.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		deletionTime = metav1.NewTime(time.Now().Truncate(time.Second).Add(-time.Minute))
+		// Past the grace period
+		clock = &fakeClock{now: deletionTime.Add(40 * time.Second)}
+		config = &mockConfig{
+			gracePeriod:            30 * time.Second,
+			drainTimeout:           300 * time.Second,
+			hardDeadlineBuffer:     60 * time.Second,
+			tcpPortsOnly:           true,
+			apiCallTimeout:         5 * time.Second,
+			connectionPollInterval: 10 * time.Second,
+			knativeAware:           true,
+			knativeSettle:          30 * time.Second,
+		}
+		// Knative has already marked the pod not ready, but the activator still routes to it
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "hello-00001-deployment-abc",
+				Namespace:         "default",
+				Labels:            map[string]string{KnativeRevisionLabel: "hello-00001"},
+				DeletionTimestamp: &deletionTime,
+				Finalizers:        []string{"example.com/other"},
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				PodIP:      "10.0.0.1",
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+			},
+		}
+		// Managed by Knative for a service without a selector
+		endpoints = &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "hello-00001", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{
+				{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod, endpoints).Build()
+		drainHandler = NewDrainHandler(fakeClient, config).WithClock(clock)
+	})
+
+	It("should wait until the pod is out of every endpoints and the settle window has passed", func() {
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+		Expect(result.HadActiveConnections).To(BeTrue())
+
+		endpoints.Subsets = nil
+		Expect(fakeClient.Update(ctx, endpoints)).To(Succeed())
+		result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+		Expect(pod.Annotations).To(HaveKeyWithValue(KnativeUnroutedAtAnnotation, clock.now.UTC().Format(time.RFC3339)))
+
+		clock.now = clock.now.Add(20 * time.Second)
+		result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+
+		clock.now = clock.now.Add(10 * time.Second)
+		result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+		Expect(result.Reason).To(Equal(CompletionReasonNotReady))
+	})
+
+	It("should start the settle window over when the pod is routed again", func() {
+		pod.Annotations = map[string]string{
+			KnativeUnroutedAtAnnotation: clock.now.Add(-time.Minute).UTC().Format(time.RFC3339),
+		}
+		Expect(fakeClient.Update(ctx, pod)).To(Succeed())
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+		Expect(pod.Annotations).ToNot(HaveKey(KnativeUnroutedAtAnnotation))
+	})
+
+	It("should still complete at the drain timeout", func() {
+		clock.now = deletionTime.Add(301 * time.Second)
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+		Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+	})
+
+	It("should drain a Knative pod like any other when knativeAware is off", func() {
+		config.knativeAware = false
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+		Expect(result.Reason).To(Equal(CompletionReasonNotReady))
+	})
+
+	It("should not change the drain of pods outside Knative", func() {
+		delete(pod.Labels, KnativeRevisionLabel)
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+		Expect(result.Reason).To(Equal(CompletionReasonNotReady))
+	})
+})
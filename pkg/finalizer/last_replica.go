@@ -0,0 +1,85 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// LastReadyReplicaGate holds the drain of a pod that is the only Ready pod of its
+// ReplicaSet or StatefulSet, so releasing it doesn't leave the workload with no available
+// replica while its replacement is still starting. Siblings that are being deleted
+// themselves don't count. Pods owned by other kinds, or by nothing, aren't gated.
+type LastReadyReplicaGate struct {
+	Reader client.Reader
+}
+
+func (g *LastReadyReplicaGate) IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	owner := workloadOwner(pod)
+	if owner == nil {
+		return true, nil
+	}
+	// A pod that isn't Ready no longer adds to the workload's availability
+	if !podReady(pod) {
+		return true, nil
+	}
+
+	siblings, err := workloadSiblings(ctx, g.Reader, pod, owner)
+	if err != nil {
+		return false, err
+	}
+	for _, sibling := range siblings {
+		if podReady(sibling) {
+			return true, nil
+		}
+	}
+
+	log.FromContext(ctx).V(1).Info("Pod is the last ready replica of its workload, holding the drain",
+		"pod", pod.Name, "owner", owner.Kind+"/"+owner.Name)
+	return false, nil
+}
+
+// workloadOwner returns the pod's ReplicaSet or StatefulSet controller, or nil when the pod
+// is owned by another kind or by nothing
+func workloadOwner(pod *corev1.Pod) *metav1.OwnerReference {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || (owner.Kind != "ReplicaSet" && owner.Kind != "StatefulSet") {
+		return nil
+	}
+	return owner
+}
+
+// workloadSiblings returns the other pods of the owner that aren't being deleted themselves
+func workloadSiblings(ctx context.Context, reader client.Reader, pod *corev1.Pod, owner *metav1.OwnerReference) ([]*corev1.Pod, error) {
+	var podList corev1.PodList
+	if err := reader.List(ctx, &podList, client.InNamespace(pod.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list sibling pods: %w", err)
+	}
+
+	var siblings []*corev1.Pod
+	for i := range podList.Items {
+		sibling := &podList.Items[i]
+		if sibling.UID == pod.UID || sibling.DeletionTimestamp != nil {
+			continue
+		}
+		if siblingOwner := metav1.GetControllerOf(sibling); siblingOwner == nil || siblingOwner.UID != owner.UID {
+			continue
+		}
+		siblings = append(siblings, sibling)
+	}
+	return siblings, nil
+}
+
+// podReady reports whether the pod's Ready condition is true
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
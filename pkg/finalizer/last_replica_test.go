@@ -0,0 +1,114 @@
+package finalizer
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newReplicaPod returns a pod controlled by an ownerKind with ownerUID, or a bare pod
+// without an ownerKind
+func newReplicaPod(name, ownerKind, ownerUID string, ready bool) *corev1.Pod {
+	controller := true
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(name),
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: ownerKind, Name: "web", UID: types.UID(ownerUID), Controller: &controller},
+		}
+	}
+	return pod
+}
+
+// newGateReader returns a fake client serving the objects a drain gate reads
+func newGateReader(objects ...client.Object) client.Reader {
+	scheme := runtime.NewScheme()
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+var _ = Describe("LastReadyReplicaGate", func() {
+	var ctx context.Context
+
+	newGate := func(objects ...client.Object) *LastReadyReplicaGate {
+		return &LastReadyReplicaGate{Reader: newGateReader(objects...)}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should hold the only ready pod of a ReplicaSet", func() {
+		pod := newReplicaPod("web-0", "ReplicaSet", "rs", true)
+		gate := newGate(pod, newReplicaPod("web-1", "ReplicaSet", "rs", false))
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+	})
+
+	It("should open once another pod of the same owner is ready", func() {
+		pod := newReplicaPod("web-0", "StatefulSet", "sts", true)
+		gate := newGate(pod, newReplicaPod("web-1", "StatefulSet", "sts", true))
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+
+	It("should not count ready pods of another owner or pods being deleted", func() {
+		pod := newReplicaPod("web-0", "ReplicaSet", "rs", true)
+		otherOwner := newReplicaPod("api-0", "ReplicaSet", "other", true)
+		deleting := newReplicaPod("web-1", "ReplicaSet", "rs", true)
+		now := metav1.Now()
+		deleting.DeletionTimestamp = &now
+		deleting.Finalizers = []string{"example.com/other"}
+		gate := newGate(pod, otherOwner, deleting)
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+	})
+
+	It("should not hold a pod that is not ready itself", func() {
+		pod := newReplicaPod("web-0", "ReplicaSet", "rs", false)
+		gate := newGate(pod)
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+
+	It("should not gate pods of other owner kinds", func() {
+		pod := newReplicaPod("web-0", "DaemonSet", "ds", true)
+		gate := newGate(pod)
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+
+		bare := newReplicaPod("bare", "", "", true)
+		drained, err = gate.IsDrained(ctx, bare)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+})
@@ -0,0 +1,43 @@
+package finalizer
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// extendToMinimumServing keeps the grace period running until the pod has been Ready for
+// minimumServingSeconds, so a pod evicted right after it came up doesn't hand its traffic
+// to replicas that are still warming up. Bounded by the drain timeout.
+func (d *DrainHandler) extendToMinimumServing(pod *corev1.Pod, window DrainWindow) DrainWindow {
+	minimumServing := d.config.GetMinimumServing()
+	if minimumServing <= 0 || pod.DeletionTimestamp == nil {
+		return window
+	}
+
+	readySince, ok := readySince(pod)
+	if !ok {
+		return window
+	}
+
+	required := readySince.Add(minimumServing).Sub(pod.DeletionTimestamp.Time)
+	if required <= window.GracePeriod {
+		return window
+	}
+
+	window.GracePeriod = min(required, window.DrainTimeout)
+	return window
+}
+
+// readySince returns when the pod last became Ready, if it is Ready
+func readySince(pod *corev1.Pod) (time.Time, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			if condition.Status != corev1.ConditionTrue || condition.LastTransitionTime.IsZero() {
+				return time.Time{}, false
+			}
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,36 @@
+package finalizer
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// shortenOnCordonedNode caps the grace period at the configured node-cordon grace when
+// fastDrainOnNodeCordon is enabled and the pod's node is unschedulable, so a node drain
+// isn't serialized behind the full grace period of every pod on it
+func (d *DrainHandler) shortenOnCordonedNode(ctx context.Context, pod *corev1.Pod, window DrainWindow) DrainWindow {
+	if !d.config.GetFastDrainOnNodeCordon() || pod.Spec.NodeName == "" {
+		return window
+	}
+
+	cordonGrace := d.config.GetNodeCordonGrace()
+	if window.GracePeriod <= cordonGrace {
+		return window
+	}
+
+	var node corev1.Node
+	if err := d.client.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, &node); err != nil {
+		log.FromContext(ctx).V(1).Info("Failed to get pod's node, keeping the configured grace period",
+			"pod", pod.Name, "node", pod.Spec.NodeName, "error", err.Error())
+		return window
+	}
+	if !node.Spec.Unschedulable {
+		return window
+	}
+
+	window.GracePeriod = cordonGrace
+	return window
+}
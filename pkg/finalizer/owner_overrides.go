@@ -0,0 +1,99 @@
+package finalizer
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// drainWindow returns the grace period and timeout for the pod, applying the
+// override configured for its top-level owner kind, if any, granted timeout extensions,
+// a cordoned node, a Descheduler eviction and the pod's preStop hook
+func (d *DrainHandler) drainWindow(ctx context.Context, pod *corev1.Pod) DrainWindow {
+	window := DrainWindow{
+		GracePeriod:  d.config.GetGracePeriod(),
+		DrainTimeout: d.config.GetDrainTimeout(),
+	}
+
+	if overrides := d.config.GetOwnerKindOverrides(); len(overrides) > 0 {
+		if override, ok := overrides[d.resolveOwnerKind(ctx, pod)]; ok {
+			window = override
+		}
+	}
+
+	window = d.scaleGraceByAge(pod, window)
+	window = d.extendForGrantedExtensions(pod, window)
+	window = d.shortenOnCordonedNode(ctx, pod, window)
+	window = d.shortenForDescheduler(ctx, pod, window)
+	window = d.extendToPreStop(ctx, pod, window)
+	window = d.extendToMinimumServing(pod, window)
+	return d.capToDeletionGracePeriod(ctx, pod, window)
+}
+
+// capToDeletionGracePeriod keeps the drain within the grace period the deleter asked for
+// (e.g. `kubectl delete --grace-period=5`) when respectDeletionGracePeriod is enabled
+func (d *DrainHandler) capToDeletionGracePeriod(ctx context.Context, pod *corev1.Pod, window DrainWindow) DrainWindow {
+	if !d.config.GetRespectDeletionGracePeriod() || pod.DeletionGracePeriodSeconds == nil {
+		return window
+	}
+
+	deletionGracePeriod := time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second
+	if deletionGracePeriod >= window.DrainTimeout {
+		return window
+	}
+
+	log.FromContext(ctx).Info("WARNING: capping drain timeout to the pod's deletion grace period",
+		"pod", pod.Name,
+		"drainTimeout", window.DrainTimeout.String(),
+		"deletionGracePeriod", deletionGracePeriod.String())
+
+	window.DrainTimeout = deletionGracePeriod
+	if window.GracePeriod > deletionGracePeriod {
+		window.GracePeriod = deletionGracePeriod
+	}
+	return window
+}
+
+func (d *DrainHandler) resolveOwnerKind(ctx context.Context, pod *corev1.Pod) string {
+	return ResolveOwnerKind(ctx, d.client, pod)
+}
+
+// ResolveOwnerKind returns the kind of the pod's top-level controller, following
+// ReplicaSet ownership up to its Deployment. Returns "" for unowned pods.
+func ResolveOwnerKind(ctx context.Context, reader client.Reader, pod *corev1.Pod) string {
+	owner := controllerOwner(pod)
+	if owner == nil {
+		return ""
+	}
+	if owner.Kind != "ReplicaSet" {
+		return owner.Kind
+	}
+
+	var replicaSet appsv1.ReplicaSet
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: owner.Name}, &replicaSet); err != nil {
+		log.FromContext(ctx).V(1).Info("Failed to get owning ReplicaSet, using ReplicaSet as owner kind",
+			"pod", pod.Name, "replicaSet", owner.Name, "error", err.Error())
+		return owner.Kind
+	}
+
+	if replicaSetOwner := controllerOwner(&replicaSet); replicaSetOwner != nil {
+		return replicaSetOwner.Kind
+	}
+	return owner.Kind
+}
+
+// controllerOwner prefers the managing controller reference and falls back to the first owner
+func controllerOwner(obj metav1.Object) *metav1.OwnerReference {
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		return owner
+	}
+	if owners := obj.GetOwnerReferences(); len(owners) > 0 {
+		return &owners[0]
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package finalizer
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PreStopSecondsAnnotation tells the controller how long the pod's preStop hook runs,
+// for hooks whose duration can't be read from the spec (e.g. httpGet or scripts)
+const PreStopSecondsAnnotation = "vpa-graceful-drain.cho.github.io/prestop-seconds"
+
+// extendToPreStop keeps the grace period from ending before the pod's preStop hook has
+// finished, so the drain doesn't complete while the kubelet is still running it
+func (d *DrainHandler) extendToPreStop(ctx context.Context, pod *corev1.Pod, window DrainWindow) DrainWindow {
+	preStop := preStopDuration(ctx, pod)
+	if preStop <= window.GracePeriod {
+		return window
+	}
+
+	window.GracePeriod = preStop
+	if window.GracePeriod > window.DrainTimeout {
+		window.GracePeriod = window.DrainTimeout
+	}
+	return window
+}
+
+// preStopDuration returns the annotated preStop duration or, failing that, the longest
+// sleep found in the containers' preStop hooks. Returns 0 when neither is known.
+func preStopDuration(ctx context.Context, pod *corev1.Pod) time.Duration {
+	if value, ok := pod.Annotations[PreStopSecondsAnnotation]; ok {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		log.FromContext(ctx).Info("WARNING: ignoring invalid preStop seconds annotation",
+			"pod", pod.Name, "annotation", PreStopSecondsAnnotation, "value", value)
+	}
+
+	var longest time.Duration
+	for _, container := range pod.Spec.Containers {
+		if container.Lifecycle == nil || container.Lifecycle.PreStop == nil {
+			continue
+		}
+		if sleep := preStopSleep(container.Lifecycle.PreStop); sleep > longest {
+			longest = sleep
+		}
+	}
+	return longest
+}
+
+// preStopSleep reads the sleep duration of a sleep action or of an exec hook that runs
+// `sleep N`, directly or through a shell. Anything else is not machine-readable.
+func preStopSleep(handler *corev1.LifecycleHandler) time.Duration {
+	if handler.Sleep != nil {
+		return time.Duration(handler.Sleep.Seconds) * time.Second
+	}
+	if handler.Exec == nil {
+		return 0
+	}
+
+	fields := handler.Exec.Command
+	if len(fields) > 0 && strings.HasSuffix(fields[0], "sh") && len(fields) >= 3 && fields[1] == "-c" {
+		fields = strings.Fields(fields[2])
+	}
+	if len(fields) != 2 || (fields[0] != "sleep" && !strings.HasSuffix(fields[0], "/sleep")) {
+		return 0
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "s"), 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
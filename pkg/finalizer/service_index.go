@@ -0,0 +1,69 @@
+package finalizer
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ServiceSelectorIndexField indexes services by each key=value pair of their selector,
+// so the services that may select a pod can be looked up by the pod's labels
+const ServiceSelectorIndexField = "spec.selector.pairs"
+
+// ServiceSelectorPairs is the index function for ServiceSelectorIndexField
+func ServiceSelectorPairs(object client.Object) []string {
+	service, ok := object.(*corev1.Service)
+	if !ok {
+		return nil
+	}
+
+	pairs := make([]string, 0, len(service.Spec.Selector))
+	for key, value := range service.Spec.Selector {
+		pairs = append(pairs, key+"="+value)
+	}
+	return pairs
+}
+
+// candidateServices returns the services in the pod's namespace that may select it. With
+// the selector index only services sharing a label pair with the pod are fetched; the
+// caller still has to match the full selector.
+func (d *DrainHandler) candidateServices(ctx context.Context, pod *corev1.Pod) ([]corev1.Service, error) {
+	if !d.serviceIndex {
+		var serviceList corev1.ServiceList
+		listCtx, cancel := d.apiCallContext(ctx)
+		defer cancel()
+		if err := d.client.List(listCtx, &serviceList, client.InNamespace(pod.Namespace)); err != nil {
+			return nil, err
+		}
+		return serviceList.Items, nil
+	}
+
+	pairs := make([]string, 0, len(pod.Labels))
+	for key, value := range pod.Labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+
+	seen := map[string]bool{}
+	var services []corev1.Service
+	for _, pair := range pairs {
+		var serviceList corev1.ServiceList
+		listCtx, cancel := d.apiCallContext(ctx)
+		err := d.client.List(listCtx, &serviceList,
+			client.InNamespace(pod.Namespace), client.MatchingFields{ServiceSelectorIndexField: pair})
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, service := range serviceList.Items {
+			if !seen[service.Name] {
+				seen[service.Name] = true
+				services = append(services, service)
+			}
+		}
+	}
+	return services, nil
+}
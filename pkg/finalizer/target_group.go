@@ -0,0 +1,102 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// TargetGroupARNAnnotation lists, comma-separated, the AWS target groups that route to the
+// pod's IP. It is read from the pod and from the services selecting it.
+const TargetGroupARNAnnotation = "vpa-graceful-drain.cho.github.io/target-group-arn"
+
+// TargetStateUnused is the state of a target that is no longer registered, i.e. whose
+// deregistration delay has run out
+const TargetStateUnused = "unused"
+
+// TargetHealth is one target registered in a target group
+type TargetHealth struct {
+	// ID is the target's IP address for IP target groups
+	ID    string
+	Port  int32
+	State string
+}
+
+// TargetHealthDescriber lists the targets of an AWS target group, as returned by the
+// ELBv2 DescribeTargetHealth API. Implementations wrap the AWS SDK client.
+type TargetHealthDescriber interface {
+	DescribeTargetHealth(ctx context.Context, targetGroupARN string) ([]TargetHealth, error)
+}
+
+// TargetGroupDrainGate holds the drain while any target group named by
+// TargetGroupARNAnnotation still has the pod's IP in a state other than unused, i.e.
+// while the load balancer may still send it connections. Pods without an annotated
+// target group aren't gated.
+type TargetGroupDrainGate struct {
+	Reader  client.Reader
+	Targets TargetHealthDescriber
+}
+
+func (g *TargetGroupDrainGate) IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	if pod.Status.PodIP == "" {
+		return true, nil
+	}
+
+	arns, err := g.targetGroupARNs(ctx, pod)
+	if err != nil {
+		return false, err
+	}
+
+	for _, arn := range arns {
+		targets, err := g.Targets.DescribeTargetHealth(ctx, arn)
+		if err != nil {
+			return false, fmt.Errorf("failed to describe target health of %s: %w", arn, err)
+		}
+		for _, target := range targets {
+			if target.ID == pod.Status.PodIP && target.State != TargetStateUnused {
+				log.FromContext(ctx).V(1).Info("Pod is still a target of an AWS target group",
+					"pod", pod.Name, "targetGroup", arn, "port", target.Port, "state", target.State)
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// targetGroupARNs collects the annotated target groups of the pod and of the services
+// selecting it
+func (g *TargetGroupDrainGate) targetGroupARNs(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	arns := splitTargetGroupARNs(pod.Annotations[TargetGroupARNAnnotation])
+
+	var services corev1.ServiceList
+	if err := g.Reader.List(ctx, &services, client.InNamespace(pod.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, service := range services.Items {
+		if len(service.Spec.Selector) == 0 {
+			continue
+		}
+		if !labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		arns = append(arns, splitTargetGroupARNs(service.Annotations[TargetGroupARNAnnotation])...)
+	}
+	slices.Sort(arns)
+	return slices.Compact(arns), nil
+}
+
+func splitTargetGroupARNs(value string) []string {
+	var arns []string
+	for _, arn := range strings.Split(value, ",") {
+		if arn = strings.TrimSpace(arn); arn != "" {
+			arns = append(arns, arn)
+		}
+	}
+	return arns
+}
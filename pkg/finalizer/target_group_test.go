@@ -0,0 +1,160 @@
+package finalizer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type mockTargetHealth struct {
+	targets map[string][]TargetHealth
+	err     error
+	calls   []string
+}
+
+func (m *mockTargetHealth) DescribeTargetHealth(ctx context.Context, targetGroupARN string) ([]TargetHealth, error) {
+	m.calls = append(m.calls, targetGroupARN)
+	return m.targets[targetGroupARN], m.err
+}
+
+var _ = Describe("TargetGroupDrainGate", func() {
+	const arn = "arn:aws:elasticloadbalancing:ap-northeast-2:123456789012:targetgroup/web/abc"
+
+	var (
+		ctx     context.Context
+		pod     *corev1.Pod
+		targets *mockTargetHealth
+	)
+
+	newGate := func(objects ...client.Object) *TargetGroupDrainGate {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		return &TargetGroupDrainGate{
+			Reader:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			Targets: targets,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web-0",
+				Namespace:   "default",
+				Labels:      map[string]string{"app": "web"},
+				Annotations: map[string]string{TargetGroupARNAnnotation: arn},
+			},
+			Status: corev1.PodStatus{PodIP: "10.0.0.1"},
+		}
+		targets = &mockTargetHealth{targets: map[string][]TargetHealth{}}
+	})
+
+	It("should stay closed while the target is draining", func() {
+		targets.targets[arn] = []TargetHealth{
+			{ID: "10.0.0.1", Port: 8080, State: "draining"},
+			{ID: "10.0.0.2", Port: 8080, State: "healthy"},
+		}
+
+		drained, err := newGate().IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+	})
+
+	It("should open once the target is unused or gone", func() {
+		targets.targets[arn] = []TargetHealth{{ID: "10.0.0.1", Port: 8080, State: TargetStateUnused}}
+		gate := newGate()
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+
+		targets.targets[arn] = []TargetHealth{{ID: "10.0.0.2", Port: 8080, State: "healthy"}}
+		drained, err = gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+
+	It("should read target groups from the services selecting the pod", func() {
+		pod.Annotations = nil
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "default",
+				Annotations: map[string]string{TargetGroupARNAnnotation: arn},
+			},
+			Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+		}
+		targets.targets[arn] = []TargetHealth{{ID: "10.0.0.1", Port: 8080, State: "healthy"}}
+
+		drained, err := newGate(service).IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+		Expect(targets.calls).To(Equal([]string{arn}))
+	})
+
+	It("should not gate pods without a target group", func() {
+		pod.Annotations = nil
+
+		drained, err := newGate().IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+		Expect(targets.calls).To(BeEmpty())
+	})
+
+	It("should surface AWS API errors", func() {
+		targets.err = errors.New("throttled")
+
+		drained, err := newGate().IsDrained(ctx, pod)
+		Expect(err).To(HaveOccurred())
+		Expect(drained).To(BeFalse())
+	})
+
+	It("should hold HandleGracefulDrain until the target is deregistered", func() {
+		deletionTime := metav1.NewTime(time.Now().Add(-time.Minute))
+		pod.DeletionTimestamp = &deletionTime
+		pod.Status.Phase = corev1.PodRunning
+		targets.targets[arn] = []TargetHealth{{ID: "10.0.0.1", Port: 8080, State: "draining"}}
+
+		gate := newGate()
+		config := &mockConfig{
+			gracePeriod:        30 * time.Second,
+			drainTimeout:       300 * time.Second,
+			hardDeadlineBuffer: 60 * time.Second,
+			apiCallTimeout:     5 * time.Second,
+		}
+		drainHandler := NewDrainHandler(gate.Reader.(client.Client), config).WithDrainGate(gate)
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+
+		targets.targets[arn] = nil
+		result, err = drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+	})
+})
+
+var _ = Describe("AllDrainGates", func() {
+	It("should open only once every gate has", func() {
+		first, second := &mockDrainGate{drained: true}, &mockDrainGate{}
+		gates := AllDrainGates{first, second}
+
+		drained, err := gates.IsDrained(context.Background(), &corev1.Pod{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+
+		second.drained = true
+		drained, err = gates.IsDrained(context.Background(), &corev1.Pod{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+})
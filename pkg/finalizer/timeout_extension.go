@@ -0,0 +1,76 @@
+package finalizer
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// TimeoutExtensionsAnnotation counts the drain timeout extensions granted to a pod
+const TimeoutExtensionsAnnotation = "vpa-graceful-drain.cho.github.io/timeout-extensions"
+
+const (
+	// OnTimeoutForceComplete releases the pod at the drain timeout, connections or not
+	OnTimeoutForceComplete = "force-complete"
+	// OnTimeoutExtend grants another timeout extension while connections remain, up to the configured maximum
+	OnTimeoutExtend = "extend"
+)
+
+// timeoutExtensions returns the number of extensions already granted to the pod
+func timeoutExtensions(pod *corev1.Pod) int {
+	count, err := strconv.Atoi(pod.Annotations[TimeoutExtensionsAnnotation])
+	if err != nil || count < 0 {
+		return 0
+	}
+	return count
+}
+
+// extendForGrantedExtensions lengthens the drain timeout by the extensions already granted
+func (d *DrainHandler) extendForGrantedExtensions(pod *corev1.Pod, window DrainWindow) DrainWindow {
+	if d.config.GetOnTimeoutWithConnections() != OnTimeoutExtend {
+		return window
+	}
+
+	extensions := min(timeoutExtensions(pod), d.config.GetMaxTimeoutExtensions())
+	window.DrainTimeout += time.Duration(extensions) * d.config.GetTimeoutExtension()
+	return window
+}
+
+// extendTimeout grants one more timeout extension if the pod still has active connections
+// and extensions remain, recording it on the pod. Reports whether the timeout was extended.
+func (d *DrainHandler) extendTimeout(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	if d.config.GetOnTimeoutWithConnections() != OnTimeoutExtend {
+		return false, nil
+	}
+
+	extensions := timeoutExtensions(pod)
+	if extensions >= d.config.GetMaxTimeoutExtensions() {
+		return false, nil
+	}
+
+	hasActiveConnections, err := d.checkActiveConnections(ctx, pod)
+	if err != nil || !hasActiveConnections {
+		// Without evidence of connections the timeout applies as usual
+		return false, nil
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = map[string]string{}
+	}
+	podCopy.Annotations[TimeoutExtensionsAnnotation] = strconv.Itoa(extensions + 1)
+	if err := d.client.Patch(ctx, podCopy, client.MergeFrom(pod)); err != nil {
+		return false, err
+	}
+
+	log.FromContext(ctx).Info("Pod still has active connections at the drain timeout, extending it",
+		"pod", pod.Name,
+		"extension", extensions+1,
+		"maxExtensions", d.config.GetMaxTimeoutExtensions(),
+		"extendBy", d.config.GetTimeoutExtension().String())
+	return true, nil
+}
@@ -0,0 +1,39 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TrafficWeightAnnotation declares the share of its services' traffic a pod receives,
+// from 0 to 1, e.g. the canary weight of a service-mesh traffic split
+const TrafficWeightAnnotation = "vpa-graceful-drain.cho.github.io/traffic-weight"
+
+// TrafficWeightProvider reports the share of service traffic a pod receives, from 0 to 1.
+// Implementations can read it from a service mesh; the default reads TrafficWeightAnnotation.
+type TrafficWeightProvider interface {
+	TrafficWeight(ctx context.Context, pod *corev1.Pod) (float64, error)
+}
+
+// AnnotationTrafficWeight reads TrafficWeightAnnotation, treating unannotated pods as
+// receiving all of their traffic
+type AnnotationTrafficWeight struct{}
+
+func (AnnotationTrafficWeight) TrafficWeight(ctx context.Context, pod *corev1.Pod) (float64, error) {
+	value, ok := pod.Annotations[TrafficWeightAnnotation]
+	if !ok {
+		return 1.0, nil
+	}
+
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 1.0, fmt.Errorf("invalid %s annotation %q: %w", TrafficWeightAnnotation, value, err)
+	}
+	if weight < 0 || weight > 1 {
+		return 1.0, fmt.Errorf("%s annotation must be between 0 and 1, got: %s", TrafficWeightAnnotation, value)
+	}
+	return weight, nil
+}
@@ -0,0 +1,101 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// volumesInUse returns the pod's persistent volumes that its node still lists in
+// Node.Status.VolumesInUse, i.e. that the kubelet hasn't unmounted yet. A CSI volume is
+// matched by its unique name, any other by a name ending in the PersistentVolume's name.
+// Volumes that can't be resolved, e.g. an unbound claim or a deleted node, don't count.
+func (d *DrainHandler) volumesInUse(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	if pod.Spec.NodeName == "" {
+		return nil, nil
+	}
+
+	var claims []string
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	if len(claims) == 0 {
+		return nil, nil
+	}
+
+	var node corev1.Node
+	if err := d.client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get node %s: %w", pod.Spec.NodeName, err)
+	}
+	if len(node.Status.VolumesInUse) == 0 {
+		return nil, nil
+	}
+
+	var inUse []string
+	for _, claimName := range claims {
+		var claim corev1.PersistentVolumeClaim
+		if err := d.client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: claimName}, &claim); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get persistent volume claim %s: %w", claimName, err)
+		}
+		if claim.Spec.VolumeName == "" {
+			continue
+		}
+
+		var volume corev1.PersistentVolume
+		if err := d.client.Get(ctx, types.NamespacedName{Name: claim.Spec.VolumeName}, &volume); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get persistent volume %s: %w", claim.Spec.VolumeName, err)
+		}
+		if slices.ContainsFunc(node.Status.VolumesInUse, func(name corev1.UniqueVolumeName) bool {
+			return volumeNameMatches(string(name), &volume)
+		}) {
+			inUse = append(inUse, volume.Name)
+		}
+	}
+	return inUse, nil
+}
+
+// volumeNameMatches reports whether the unique volume name the kubelet reports refers to
+// the persistent volume
+func volumeNameMatches(name string, volume *corev1.PersistentVolume) bool {
+	if csi := volume.Spec.CSI; csi != nil {
+		return name == "kubernetes.io/csi/"+csi.Driver+"^"+csi.VolumeHandle
+	}
+	return strings.HasSuffix(name, "/"+volume.Name)
+}
+
+// volumesDetached reports whether a drain may complete as far as waitForVolumeDetach is
+// concerned: the option is off or none of the pod's volumes is in use on its node anymore
+func (d *DrainHandler) volumesDetached(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	if !d.config.GetWaitForVolumeDetach() {
+		return true, nil
+	}
+
+	volumes, err := d.volumesInUse(ctx, pod)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to check volume detach")
+		return false, err
+	}
+	if len(volumes) > 0 {
+		log.FromContext(ctx).V(1).Info("Pod's volumes are still in use on its node, continuing drain",
+			"pod", pod.Name, "node", pod.Spec.NodeName, "volumes", volumes)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,157 @@
+package finalizer
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("waitForVolumeDetach", func() {
+	const uniqueName = "kubernetes.io/csi/ebs.csi.aws.com^vol-0123"
+
+	var (
+		ctx    context.Context
+		config *mockConfig
+		pod    *corev1.Pod
+		now    time.Time
+	)
+
+	newNode := func(volumesInUse ...corev1.UniqueVolumeName) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status:     corev1.NodeStatus{VolumesInUse: volumesInUse},
+		}
+	}
+
+	newHandler := func(objects ...client.Object) *DrainHandler {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		objects = append(objects,
+			&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data-db-0", Namespace: "default"},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-data"},
+			},
+			&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-0123"},
+					},
+				},
+			},
+		)
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		return NewDrainHandler(fakeClient, config)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now()
+		config = &mockConfig{
+			gracePeriod:         30 * time.Second,
+			drainTimeout:        300 * time.Second,
+			hardDeadlineBuffer:  60 * time.Second,
+			tcpPortsOnly:        true,
+			apiCallTimeout:      5 * time.Second,
+			waitForVolumeDetach: true,
+		}
+
+		// Past the grace period and no longer ready, so only the volume holds the drain
+		deletionTime := metav1.NewTime(now.Add(-40 * time.Second))
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "db-0",
+				Namespace:         "default",
+				DeletionTimestamp: &deletionTime,
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Volumes: []corev1.Volume{{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+			},
+		}
+	})
+
+	It("should hold the drain while the node still lists the volume in use", func() {
+		drainHandler := newHandler(newNode(uniqueName))
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+	})
+
+	It("should complete once the volume left the node's volumes in use", func() {
+		drainHandler := newHandler(newNode("kubernetes.io/csi/ebs.csi.aws.com^vol-other"))
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+		Expect(result.Reason).To(Equal(CompletionReasonNotReady))
+	})
+
+	It("should hold a pod whose containers all terminated", func() {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "db",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+		}}
+		drainHandler := newHandler(newNode(uniqueName))
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+	})
+
+	It("should stop waiting at the drain timeout", func() {
+		deletionTime := metav1.NewTime(now.Add(-310 * time.Second))
+		pod.DeletionTimestamp = &deletionTime
+		drainHandler := newHandler(newNode(uniqueName))
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+		Expect(result.Reason).To(Equal(CompletionReasonTimeout))
+	})
+
+	It("should not wait when waitForVolumeDetach is disabled", func() {
+		config.waitForVolumeDetach = false
+		drainHandler := newHandler(newNode(uniqueName))
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+	})
+
+	It("should not wait for a pod without persistent volume claims", func() {
+		pod.Spec.Volumes = nil
+		drainHandler := newHandler(newNode(uniqueName))
+
+		result, err := drainHandler.HandleGracefulDrain(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+	})
+
+	It("should match a non-CSI volume by its persistent volume name", func() {
+		drainHandler := newHandler(newNode("kubernetes.io/local-volume/pv-data"))
+		Expect(drainHandler.client.Delete(ctx, &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-data"}})).To(Succeed())
+		Expect(drainHandler.client.Create(ctx, &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-data"}})).To(Succeed())
+
+		volumes, err := drainHandler.volumesInUse(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(volumes).To(Equal([]string{"pv-data"}))
+	})
+})
@@ -0,0 +1,77 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ZoneAvailabilityGate holds the drain of a pod that is the only Ready pod of its
+// ReplicaSet or StatefulSet in its zone, so a zone isn't left without a serving replica
+// while the replacement starts. The zone is the topology.kubernetes.io/zone label of the
+// pod's node; pods on nodes without one, or owned by other kinds, aren't gated.
+type ZoneAvailabilityGate struct {
+	Reader client.Reader
+}
+
+func (g *ZoneAvailabilityGate) IsDrained(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	owner := workloadOwner(pod)
+	if owner == nil || !podReady(pod) {
+		return true, nil
+	}
+
+	zones := map[string]string{}
+	zone, err := g.nodeZone(ctx, pod.Spec.NodeName, zones)
+	if err != nil {
+		return false, err
+	}
+	if zone == "" {
+		return true, nil
+	}
+
+	siblings, err := workloadSiblings(ctx, g.Reader, pod, owner)
+	if err != nil {
+		return false, err
+	}
+	for _, sibling := range siblings {
+		if !podReady(sibling) {
+			continue
+		}
+		siblingZone, err := g.nodeZone(ctx, sibling.Spec.NodeName, zones)
+		if err != nil {
+			return false, err
+		}
+		if siblingZone == zone {
+			return true, nil
+		}
+	}
+
+	log.FromContext(ctx).V(1).Info("Pod is the last ready replica of its workload in its zone, holding the drain",
+		"pod", pod.Name, "owner", owner.Kind+"/"+owner.Name, "zone", zone)
+	return false, nil
+}
+
+// nodeZone returns the zone label of the node, or "" for an unscheduled pod or a node that
+// is gone or unlabelled. Lookups are cached in zones for the duration of one check.
+func (g *ZoneAvailabilityGate) nodeZone(ctx context.Context, nodeName string, zones map[string]string) (string, error) {
+	if nodeName == "" {
+		return "", nil
+	}
+	if zone, ok := zones[nodeName]; ok {
+		return zone, nil
+	}
+
+	var node corev1.Node
+	if err := g.Reader.Get(ctx, types.NamespacedName{Name: nodeName}, &node); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to get node %s: %w", nodeName, err)
+		}
+	}
+	zones[nodeName] = node.Labels[corev1.LabelTopologyZone]
+	return zones[nodeName], nil
+}
@@ -0,0 +1,97 @@
+package finalizer
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ZoneAvailabilityGate", func() {
+	var ctx context.Context
+
+	newNode := func(name, zone string) *corev1.Node {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if zone != "" {
+			node.Labels = map[string]string{corev1.LabelTopologyZone: zone}
+		}
+		return node
+	}
+
+	newPod := func(name, nodeName string, ready bool) *corev1.Pod {
+		pod := newReplicaPod(name, "ReplicaSet", "rs", ready)
+		pod.Spec.NodeName = nodeName
+		return pod
+	}
+
+	newGate := func(objects ...client.Object) *ZoneAvailabilityGate {
+		objects = append(objects, newNode("node-a1", "zone-a"), newNode("node-a2", "zone-a"),
+			newNode("node-b1", "zone-b"), newNode("node-x", ""))
+		return &ZoneAvailabilityGate{Reader: newGateReader(objects...)}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should hold the last ready pod of its zone", func() {
+		pod := newPod("web-0", "node-a1", true)
+		gate := newGate(pod, newPod("web-1", "node-b1", true), newPod("web-2", "node-a2", false))
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+	})
+
+	It("should open once another pod in the same zone is ready", func() {
+		pod := newPod("web-0", "node-a1", true)
+		gate := newGate(pod, newPod("web-1", "node-a2", true))
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+
+	It("should not count a ready pod in the zone that is being deleted", func() {
+		pod := newPod("web-0", "node-a1", true)
+		deleting := newPod("web-1", "node-a2", true)
+		now := metav1.Now()
+		deleting.DeletionTimestamp = &now
+		deleting.Finalizers = []string{"example.com/other"}
+		gate := newGate(pod, deleting)
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeFalse())
+	})
+
+	It("should not gate a pod whose node has no zone", func() {
+		pod := newPod("web-0", "node-x", true)
+		gate := newGate(pod)
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+
+	It("should not gate a pod whose node is gone", func() {
+		pod := newPod("web-0", "node-gone", true)
+		gate := newGate(pod)
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+
+	It("should not hold a pod that is not ready itself", func() {
+		pod := newPod("web-0", "node-a1", false)
+		gate := newGate(pod)
+
+		drained, err := gate.IsDrained(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drained).To(BeTrue())
+	})
+})
@@ -0,0 +1,60 @@
+package grpchealth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const checkTimeout = 5 * time.Second
+
+// HealthChecker reports whether a pod still declares itself serving
+type HealthChecker interface {
+	Serving(ctx context.Context, pod *corev1.Pod) (bool, error)
+}
+
+// Client queries the standard gRPC health service (grpc.health.v1.Health) on the pod's IP.
+// Applications that drain gracefully switch their health to NOT_SERVING and then finish
+// their in-flight streams.
+type Client struct {
+	port    int32
+	service string
+}
+
+// NewClient builds a checker for the health service on the given port. An empty service
+// name checks the health of the server as a whole.
+func NewClient(port int32, service string) *Client {
+	return &Client{port: port, service: service}
+}
+
+// Serving reports whether the pod's health service reports anything but NOT_SERVING.
+// Errors, including an unreachable pod or a missing health service, are returned as is
+// for the caller to decide how conservative to be.
+func (c *Client) Serving(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	if pod.Status.PodIP == "" {
+		return false, fmt.Errorf("pod %s/%s has no IP address", pod.Namespace, pod.Name)
+	}
+
+	target := net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(c.port)))
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return false, fmt.Errorf("failed to create gRPC client for %s: %w", target, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: c.service})
+	if err != nil {
+		return false, fmt.Errorf("gRPC health check of %s failed: %w", target, err)
+	}
+	return resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING, nil
+}
@@ -0,0 +1,97 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGRPCHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "gRPC Health Suite")
+}
+
+var _ = Describe("Client", func() {
+	var (
+		ctx          context.Context
+		pod          *corev1.Pod
+		server       *grpc.Server
+		healthServer *health.Server
+		port         int32
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		port = int32(listener.Addr().(*net.TCPAddr).Port)
+
+		server = grpc.NewServer()
+		healthServer = health.NewServer()
+		healthpb.RegisterHealthServer(server, healthServer)
+		go server.Serve(listener)
+
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"},
+			Status:     corev1.PodStatus{PodIP: "127.0.0.1"},
+		}
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	It("should report serving while the server is SERVING", func() {
+		serving, err := NewClient(port, "").Serving(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serving).To(BeTrue())
+	})
+
+	It("should report not serving once the server is NOT_SERVING", func() {
+		healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+		serving, err := NewClient(port, "").Serving(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serving).To(BeFalse())
+	})
+
+	It("should check the named service", func() {
+		healthServer.SetServingStatus("orders.v1.Orders", healthpb.HealthCheckResponse_NOT_SERVING)
+
+		serving, err := NewClient(port, "orders.v1.Orders").Serving(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serving).To(BeFalse())
+
+		serving, err = NewClient(port, "").Serving(ctx, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serving).To(BeTrue())
+	})
+
+	It("should return an error for an unknown service", func() {
+		_, err := NewClient(port, "unknown.Service").Serving(ctx, pod)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error when the server is unreachable", func() {
+		server.Stop()
+
+		_, err := NewClient(port, "").Serving(ctx, pod)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error for a pod without an IP", func() {
+		pod.Status.PodIP = ""
+
+		_, err := NewClient(port, "").Serving(ctx, pod)
+		Expect(err).To(HaveOccurred())
+	})
+})
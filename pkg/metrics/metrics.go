@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// HardTimeoutTotal counts drains released by the hard deadline safety net
+	HardTimeoutTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_hard_timeout_total",
+		Help: "Number of drains force-completed after exceeding the drain timeout plus the hard deadline buffer",
+	})
+
+	// CompletionReasonTotal counts finalizer removals by why the drain completed
+	CompletionReasonTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_completion_reason_total",
+		Help: "Number of completed drains by completion reason, namespace and owner kind",
+	}, []string{"reason", "namespace", "owner_kind"})
+
+	// DrainDurationSeconds observes the time from deletion to finalizer removal
+	DrainDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vpa_graceful_drain_duration_seconds",
+		Help:    "Time from pod deletion until the finalizer was removed, by namespace and owner kind",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"namespace", "owner_kind"})
+
+	// ActiveDrains tracks the number of pods currently held by the finalizer
+	ActiveDrains = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "vpa_graceful_drain_active",
+		Help: "Number of pods currently being drained",
+	})
+
+	// EndpointCheckFailuresTotal counts endpoint checks that failed against the API server
+	EndpointCheckFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_endpoint_check_failures_total",
+		Help: "Number of endpoint checks that failed",
+	})
+
+	// EndpointCheckShortCircuitedTotal counts endpoint checks skipped while the circuit was open
+	EndpointCheckShortCircuitedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_endpoint_check_short_circuited_total",
+		Help: "Number of endpoint checks skipped because the circuit breaker was open",
+	})
+
+	// EndpointCircuitTransitionsTotal counts endpoint circuit breaker transitions by the state entered
+	EndpointCircuitTransitionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_endpoint_circuit_transitions_total",
+		Help: "Number of endpoint check circuit breaker state transitions by new state",
+	}, []string{"state"})
+
+	// FinalizerAddedTotal counts finalizers successfully added to pods
+	FinalizerAddedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_finalizer_added_total",
+		Help: "Number of finalizers added to pods",
+	})
+
+	// FinalizerRemovedTotal counts finalizers successfully removed from pods
+	FinalizerRemovedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_finalizer_removed_total",
+		Help: "Number of finalizers removed from pods",
+	})
+
+	// UpdateErrorsTotal counts failed pod writes by operation and reason (conflict or other)
+	UpdateErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_update_errors_total",
+		Help: "Number of failed pod updates by operation and reason",
+	}, []string{"operation", "reason"})
+
+	// DrainPhaseTransitionsTotal counts drain phase changes by the phase left and entered
+	DrainPhaseTransitionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_phase_transitions_total",
+		Help: "Number of drain phase transitions by previous and new phase",
+	}, []string{"from", "to"})
+
+	// DrainSlowTotal counts pods whose drain passed slowDrainThreshold of the drain timeout
+	DrainSlowTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vpa_graceful_drain_slow_total",
+		Help: "Number of pods whose drain ran past the slow drain threshold of the drain timeout",
+	})
+)
+
+// Operation and reason labels of UpdateErrorsTotal
+const (
+	OperationAddFinalizer    = "add-finalizer"
+	OperationRemoveFinalizer = "remove-finalizer"
+
+	UpdateErrorReasonConflict = "conflict"
+	UpdateErrorReasonOther    = "other"
+)
+
+// Owner kind label values; any other owner, or none, is reported as OwnerKindOther
+// to keep the label's cardinality fixed
+const (
+	OwnerKindDeployment  = "Deployment"
+	OwnerKindStatefulSet = "StatefulSet"
+	OwnerKindDaemonSet   = "DaemonSet"
+	OwnerKindJob         = "Job"
+	OwnerKindOther       = "Other"
+)
+
+// OwnerKindLabel maps a top-level owner kind onto the fixed set of owner kind label values
+func OwnerKindLabel(kind string) string {
+	switch kind {
+	case OwnerKindDeployment, OwnerKindStatefulSet, OwnerKindDaemonSet, OwnerKindJob:
+		return kind
+	default:
+		return OwnerKindOther
+	}
+}
+
+func init() {
+	// Register with controller-runtime's registry so the manager's metrics server exposes them
+	metrics.Registry.MustRegister(
+		HardTimeoutTotal,
+		CompletionReasonTotal,
+		DrainDurationSeconds,
+		ActiveDrains,
+		EndpointCheckFailuresTotal,
+		EndpointCheckShortCircuitedTotal,
+		EndpointCircuitTransitionsTotal,
+		FinalizerAddedTotal,
+		FinalizerRemovedTotal,
+		UpdateErrorsTotal,
+		DrainPhaseTransitionsTotal,
+		DrainSlowTotal,
+	)
+}